@@ -0,0 +1,331 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/identity"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/moby/buildkit/session/filesync"
+	"github.com/moby/buildkit/session/grpchijack"
+	"github.com/opencontainers/go-digest"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	fstypes "github.com/tonistiigi/fsutil/types"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+
+	"github.com/windmilleng/tilt/internal/docker"
+	"github.com/windmilleng/tilt/internal/dockerfile"
+	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// The address of a BuildKit daemon to build images with, instead of
+// the Docker daemon (e.g., unix:///run/buildkit/buildkitd.sock).
+//
+// Set with `tilt up --buildkit-addr`. Empty if not set.
+type BuildKitAddr string
+
+// Where the BuildKit builder sends the images it builds.
+type BuildKitExport string
+
+const (
+	// Push the image to its registry as part of the build.
+	BuildKitExportRegistry BuildKitExport = "registry"
+
+	// Write the image to a tarball (in `docker save` format) that
+	// we can load into a local cluster, like KIND or k3d.
+	BuildKitExportArchive BuildKitExport = "archive"
+)
+
+// Builds images by talking to a BuildKit daemon directly over gRPC,
+// so that we don't need a Docker daemon at all.
+type BuildKitImageBuilder struct {
+	addr       string
+	export     BuildKitExport
+	archiveDir string
+	clock      Clock
+}
+
+var _ ImageBuilder = &BuildKitImageBuilder{}
+
+func NewBuildKitImageBuilder(addr string, export BuildKitExport, clock Clock) *BuildKitImageBuilder {
+	return &BuildKitImageBuilder{
+		addr:       addr,
+		export:     export,
+		archiveDir: filepath.Join(os.TempDir(), "tilt-buildkit"),
+		clock:      clock,
+	}
+}
+
+func (b *BuildKitImageBuilder) Export() BuildKitExport {
+	return b.export
+}
+
+// The tarball that we export the given image to, when exporting to an archive.
+func (b *BuildKitImageBuilder) ArchivePath(ref reference.NamedTagged) string {
+	name := strings.NewReplacer("/", "_", ":", "_").Replace(ref.String())
+	return filepath.Join(b.archiveDir, name+".tar")
+}
+
+func (b *BuildKitImageBuilder) BuildImage(ctx context.Context, ps *PipelineState, ref reference.Named, df dockerfile.Dockerfile, db model.DockerBuild, filter model.PathMatcher) (reference.NamedTagged, error) {
+	logger.Get(ctx).Infof("Building Dockerfile:\n%s\n", indent(df.String(), "  "))
+	span, ctx := opentracing.StartSpanFromContext(ctx, "buildkit-BuildImage")
+	defer span.Finish()
+
+	// BuildKit can't tell us the digest until the image is exported,
+	// and we need to name the image before we export it.
+	tagged, err := reference.WithTag(ref, fmt.Sprintf("tilt-build-%d", b.clock.Now().Unix()))
+	if err != nil {
+		return nil, errors.Wrap(err, "BuildImage")
+	}
+
+	dfDir, err := ioutil.TempDir("", "tilt-dockerfile")
+	if err != nil {
+		return nil, errors.Wrap(err, "BuildImage")
+	}
+	defer func() { _ = os.RemoveAll(dfDir) }()
+
+	err = ioutil.WriteFile(filepath.Join(dfDir, "Dockerfile"), []byte(df), 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "BuildImage")
+	}
+
+	ps.StartBuildStep(ctx, "Connecting to BuildKit at %s", b.addr)
+	conn, err := dialBuildKit(ctx, b.addr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+	control := controlapi.NewControlClient(conn)
+
+	s, err := session.NewSession(ctx, "tilt", db.BuildPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating BuildKit session")
+	}
+	defer func() { _ = s.Close() }()
+
+	s.Allow(authprovider.NewDockerAuthProvider())
+	s.Allow(filesync.NewFSSyncProvider([]filesync.SyncedDir{
+		{
+			Name: "context",
+			Dir:  db.BuildPath,
+			Map: func(p string, st *fstypes.Stat) bool {
+				matches, err := filter.Matches(filepath.Join(db.BuildPath, p))
+				return err != nil || !matches
+			},
+		},
+		{Name: "dockerfile", Dir: dfDir},
+	}))
+
+	attachables, err := docker.BuildKitAttachables(db.SSHSpecs, db.SecretSpecs)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attachables {
+		s.Allow(a)
+	}
+
+	exporter, exporterAttrs, err := b.exporter(tagged, s)
+	if err != nil {
+		return nil, err
+	}
+
+	frontendAttrs := map[string]string{"filename": "Dockerfile"}
+	if db.TargetStage != "" {
+		frontendAttrs["target"] = db.TargetStage
+	}
+	for k, v := range db.BuildArgs {
+		frontendAttrs["build-arg:"+k] = v
+	}
+
+	// The Dockerfile frontend only reads the cache refs we import from
+	// when they're in its own attrs.
+	cache := controlapi.CacheOptions{}
+	if len(db.CacheFrom) > 0 {
+		cache.ImportRefs = db.CacheFrom
+		frontendAttrs["cache-from"] = strings.Join(db.CacheFrom, ",")
+	}
+	if db.CacheTo != "" {
+		// Export the cache for every stage, not just the ones in the final image.
+		cache.ExportRef = db.CacheTo
+		cache.ExportAttrs = map[string]string{"mode": "max"}
+	}
+
+	ps.StartBuildStep(ctx, "Building image")
+	buildRef := identity.NewID()
+	eg, egCtx := errgroup.WithContext(ctx)
+	solveDone := make(chan struct{})
+
+	eg.Go(func() error {
+		return s.Run(egCtx, grpchijack.Dialer(control))
+	})
+
+	eg.Go(func() error {
+		defer func() {
+			_ = s.Close()
+			close(solveDone)
+		}()
+		_, err := control.Solve(egCtx, &controlapi.SolveRequest{
+			Ref:           buildRef,
+			Exporter:      exporter,
+			ExporterAttrs: exporterAttrs,
+			Session:       s.ID(),
+			Frontend:      "dockerfile.v0",
+			FrontendAttrs: frontendAttrs,
+			Cache:         cache,
+		})
+		if err != nil {
+			return errors.Wrap(err, "ImageBuild")
+		}
+		return nil
+	})
+
+	printer := newBuildkitPrinter(logger.Get(ctx).Writer(logger.InfoLvl))
+	eg.Go(func() error {
+		// Keep reading the status after the solve finishes, so that
+		// we print the last few updates.
+		statusCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-egCtx.Done():
+				cancel()
+			case <-solveDone:
+			}
+		}()
+
+		return readBuildKitStatus(statusCtx, control, buildRef, printer)
+	})
+
+	err = eg.Wait()
+	ps.AddCacheStats(printer.cacheHits, printer.cacheMisses)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return tagged, nil
+}
+
+// Picks the BuildKit exporter for the given image.
+func (b *BuildKitImageBuilder) exporter(ref reference.NamedTagged, s *session.Session) (string, map[string]string, error) {
+	switch b.export {
+	case BuildKitExportRegistry:
+		return "image", map[string]string{"name": ref.String(), "push": "true"}, nil
+	case BuildKitExportArchive:
+		err := os.MkdirAll(b.archiveDir, 0755)
+		if err != nil {
+			return "", nil, errors.Wrap(err, "creating image archive dir")
+		}
+
+		path := b.ArchivePath(ref)
+		s.Allow(filesync.NewFSSyncTarget(func(map[string]string) (io.WriteCloser, error) {
+			return os.Create(path)
+		}))
+		return "docker", map[string]string{"name": ref.String()}, nil
+	}
+	return "", nil, fmt.Errorf("unknown BuildKit export: %s", b.export)
+}
+
+func readBuildKitStatus(ctx context.Context, control controlapi.ControlClient, buildRef string, printer *buildkitPrinter) error {
+	stream, err := control.Status(ctx, &controlapi.StatusRequest{Ref: buildRef})
+	if err != nil {
+		return errors.Wrap(err, "reading BuildKit status")
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrap(err, "reading BuildKit status")
+		}
+
+		err = printer.parseAndPrint(toVertexes(*resp))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Dials a BuildKit address, like unix:///run/buildkit/buildkitd.sock
+// or tcp://localhost:1234.
+func dialBuildKit(ctx context.Context, addr string) (*grpc.ClientConn, error) {
+	network, address, err := parseBuildKitAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := func(_ string, timeout time.Duration) (net.Conn, error) {
+		return net.DialTimeout(network, address, timeout)
+	}
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(), grpc.WithDialer(dialer))
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to BuildKit at %s", addr)
+	}
+	return conn, nil
+}
+
+func parseBuildKitAddr(addr string) (network string, address string, err error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "invalid BuildKit address %q", addr)
+	}
+
+	switch u.Scheme {
+	case "unix":
+		return "unix", u.Path, nil
+	case "tcp":
+		return "tcp", u.Host, nil
+	}
+	return "", "", fmt.Errorf("invalid BuildKit address %q: must start with unix:// or tcp://", addr)
+}
+
+func (b *BuildKitImageBuilder) DeprecatedFastBuildImage(ctx context.Context, ps *PipelineState, ref reference.Named, baseDockerfile dockerfile.Dockerfile, syncs []model.Sync, filter model.PathMatcher, runs []model.Run, entrypoint model.Cmd) (reference.NamedTagged, error) {
+	return nil, fmt.Errorf("fast_build is not supported when building with BuildKit")
+}
+
+func (b *BuildKitImageBuilder) PackBuildImage(ctx context.Context, ps *PipelineState, ref reference.Named, pb model.PackBuild, filter model.PathMatcher) (reference.NamedTagged, error) {
+	return nil, fmt.Errorf("pack_build is not supported when building with BuildKit")
+}
+
+// BuildKit pushes the image as part of the build, or writes it to an archive,
+// so there's nothing left to do here.
+func (b *BuildKitImageBuilder) PushImage(ctx context.Context, ref reference.NamedTagged, writer io.Writer) (reference.NamedTagged, error) {
+	return ref, nil
+}
+
+func (b *BuildKitImageBuilder) TagImage(ctx context.Context, ref reference.Named, dig digest.Digest) (reference.NamedTagged, error) {
+	return nil, fmt.Errorf("tagging images is not supported when building with BuildKit")
+}
+
+func (b *BuildKitImageBuilder) ImageExists(ctx context.Context, ref reference.NamedTagged) (bool, error) {
+	if b.export == BuildKitExportArchive {
+		_, err := os.Stat(b.ArchivePath(ref))
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "error checking if %s exists", ref.String())
+		}
+		return true, nil
+	}
+
+	// We pushed the image when we built it, so we assume the registry still has it.
+	return true, nil
+}
@@ -0,0 +1,53 @@
+package build
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestParseBuildKitAddr(t *testing.T) {
+	network, address, err := parseBuildKitAddr("unix:///run/buildkit/buildkitd.sock")
+	require.NoError(t, err)
+	assert.Equal(t, "unix", network)
+	assert.Equal(t, "/run/buildkit/buildkitd.sock", address)
+
+	network, address, err = parseBuildKitAddr("tcp://localhost:1234")
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", network)
+	assert.Equal(t, "localhost:1234", address)
+
+	_, _, err = parseBuildKitAddr("localhost:1234")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "must start with unix:// or tcp://")
+	}
+}
+
+func TestBuildKitImageExistsInArchive(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	b := NewBuildKitImageBuilder("unix:///run/buildkit/buildkitd.sock", BuildKitExportArchive, fakeClock{})
+	b.archiveDir = f.Path()
+
+	ref := container.MustParseNamedTagged("gcr.io/foo/bar:tilt-build-1551202573")
+	path := b.ArchivePath(ref)
+	assert.Equal(t, filepath.Join(f.Path(), "gcr.io_foo_bar_tilt-build-1551202573.tar"), path)
+
+	exists, err := b.ImageExists(context.Background(), ref)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("image"), 0644))
+
+	exists, err = b.ImageExists(context.Background(), ref)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
@@ -16,6 +16,10 @@ type buildkitPrinter struct {
 	writer io.Writer
 	vData  map[digest.Digest]*vertexAndLogs
 	vOrder []digest.Digest
+
+	// How many build steps we found in the cache, and how many we had to run.
+	cacheHits   int
+	cacheMisses int
 }
 
 type vertex struct {
@@ -27,6 +31,7 @@ type vertex struct {
 	startPrinted    bool
 	completePrinted bool
 	cached          bool
+	cacheCounted    bool
 	duration        time.Duration
 }
 
@@ -40,6 +45,12 @@ func (v *vertex) isInternal() bool {
 	return strings.HasPrefix(v.name, internalPrefix)
 }
 
+// Whether this vertex is a step in the Dockerfile (e.g., "[2/3] RUN make"),
+// rather than something BuildKit does around the build (e.g., "exporting to image").
+func (v *vertex) isBuildStep() bool {
+	return !v.isInternal() && stageNameRegexp.MatchString(v.name)
+}
+
 func (v *vertex) isError() bool {
 	return len(v.error) > 0
 }
@@ -132,6 +143,15 @@ func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog) e
 			}
 		}
 
+		if vl.vertex.completed && !vl.vertex.cacheCounted && vl.vertex.isBuildStep() && !vl.vertex.isError() {
+			if vl.vertex.cached {
+				b.cacheHits++
+			} else {
+				b.cacheMisses++
+			}
+			vl.vertex.cacheCounted = true
+		}
+
 		if vl.vertex.completed &&
 			!vl.vertex.completePrinted &&
 			!vl.vertex.isInternal() &&
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/stretchr/testify/assert"
 )
 
 // NOTE(dmiller): set at runtime with:
@@ -98,3 +99,29 @@ func TestBuildkitPrinter(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildkitPrinterCacheStats(t *testing.T) {
+	c := buildkitTestCase{"sleep-cache", "sleep-cache.response.txt"}
+	f, err := os.Open(fmt.Sprintf("testdata/TestBuildkitPrinter/%s", c.responsePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	responses, err := c.readResponse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := newBuildkitPrinter(ioutil.Discard)
+	for _, resp := range responses {
+		err := p.parseAndPrint(toVertexes(resp))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The FROM step isn't cached, but the RUN step is.
+	assert.Equal(t, 1, p.cacheHits)
+	assert.Equal(t, 1, p.cacheMisses)
+}
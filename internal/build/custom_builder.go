@@ -11,6 +11,7 @@ import (
 
 	"github.com/windmilleng/tilt/internal/docker"
 	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/procutil"
 )
 
 type CustomBuilder interface {
@@ -39,7 +40,7 @@ func (b *ExecCustomBuilder) Build(ctx context.Context, ref reference.Named, comm
 		return nil, err
 	}
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd := exec.Command("sh", "-c", command)
 
 	l := logger.Get(ctx)
 	l.Infof("Custom Build: Injecting Environment Variables")
@@ -57,7 +58,7 @@ func (b *ExecCustomBuilder) Build(ctx context.Context, ref reference.Named, comm
 	cmd.Stderr = w
 
 	l.Infof("Running custom build cmd %q", command)
-	err = cmd.Run()
+	err = procutil.Run(ctx, cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,86 @@
+package build
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/dockerfile"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// DigestBuildContext computes a digest of everything that goes into a docker build:
+// the files in the build context (after filtering), the Dockerfile, and the build args.
+//
+// Unlike the tarball that we send to Docker, the digest ignores file timestamps,
+// so touching a file (or renaming it and back) doesn't change it.
+func DigestBuildContext(ctx context.Context, df dockerfile.Dockerfile, buildPath string, filter model.PathMatcher, buildArgs model.DockerBuildArgs) (digest.Digest, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "daemon-DigestBuildContext")
+	defer span.Finish()
+
+	if filter == nil {
+		filter = model.EmptyMatcher
+	}
+	ab := &ArchiveBuilder{filter: filter}
+	entries, err := ab.entriesForPath(ctx, buildPath, "/")
+	if err != nil {
+		return "", errors.Wrap(err, "DigestBuildContext")
+	}
+
+	digester := digest.Canonical.Digester()
+	h := digester.Hash()
+	for _, entry := range entries {
+		err := digestEntry(h, entry)
+		if err != nil {
+			return "", errors.Wrap(err, "DigestBuildContext")
+		}
+	}
+
+	_, _ = fmt.Fprintf(h, "Dockerfile\x00%d\x00%s", len(df), df)
+
+	keys := make([]string, 0, len(buildArgs))
+	for k := range buildArgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(h, "\x00arg\x00%s=%s", k, buildArgs[k])
+	}
+
+	return digester.Digest(), nil
+}
+
+func digestEntry(w io.Writer, entry archiveEntry) error {
+	header := entry.header
+	_, _ = fmt.Fprintf(w, "%s\x00%c\x00%o\x00%s\x00%d\x00",
+		header.Name, header.Typeflag, header.Mode, header.Linkname, header.Size)
+
+	if header.Typeflag != tar.TypeReg {
+		return nil
+	}
+
+	file, err := os.Open(entry.path)
+	if err != nil {
+		// In case the file has been deleted since we last looked at it.
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "%s: open", entry.path)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	_, err = io.CopyN(w, file, header.Size)
+	if err != nil && err != io.EOF {
+		return errors.Wrapf(err, "%s: reading contents", entry.path)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package build
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/windmilleng/tilt/internal/dockerfile"
+	"github.com/windmilleng/tilt/internal/dockerignore"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestDigestBuildContextIgnoresTimestamps(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.WriteFile("a.txt", "a")
+	d1 := f.digest(model.EmptyMatcher, nil)
+
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(f.JoinPath("a.txt"), later, later))
+	assert.Equal(t, d1, f.digest(model.EmptyMatcher, nil))
+
+	f.Rm("a.txt")
+	f.WriteFile("b.txt", "a")
+	f.Rm("b.txt")
+	f.WriteFile("a.txt", "a")
+	assert.Equal(t, d1, f.digest(model.EmptyMatcher, nil))
+}
+
+func TestDigestBuildContextChanges(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.WriteFile("a.txt", "a")
+	d1 := f.digest(model.EmptyMatcher, nil)
+
+	f.WriteFile("a.txt", "b")
+	d2 := f.digest(model.EmptyMatcher, nil)
+	assert.NotEqual(t, d1, d2)
+
+	f.WriteFile("c.txt", "")
+	d3 := f.digest(model.EmptyMatcher, nil)
+	assert.NotEqual(t, d2, d3)
+
+	d4 := f.digest(model.EmptyMatcher, model.DockerBuildArgs{"FOO": "bar"})
+	assert.NotEqual(t, d3, d4)
+	assert.Equal(t, d4, f.digest(model.EmptyMatcher, model.DockerBuildArgs{"FOO": "bar"}))
+
+	d5, err := DigestBuildContext(f.ctx, dockerfile.Dockerfile("FROM busybox"), f.Path(), model.EmptyMatcher, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, d3, d5)
+}
+
+func TestDigestBuildContextFiltered(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	filter, err := dockerignore.NewDockerPatternMatcher(f.Path(), []string{"tmp"})
+	require.NoError(t, err)
+
+	f.WriteFile("a.txt", "a")
+	d1 := f.digest(filter, nil)
+
+	f.WriteFile("tmp/scratch.txt", "scratch")
+	assert.Equal(t, d1, f.digest(filter, nil))
+	assert.NotEqual(t, d1, f.digest(model.EmptyMatcher, nil))
+}
+
+func (f *fixture) digest(filter model.PathMatcher, buildArgs model.DockerBuildArgs) digest.Digest {
+	d, err := DigestBuildContext(f.ctx, dockerfile.Dockerfile("FROM alpine"), f.Path(), filter, buildArgs)
+	require.NoError(f.t, err)
+	return d
+}
@@ -39,8 +39,14 @@ type dockerImageBuilder struct {
 }
 
 type ImageBuilder interface {
-	BuildImage(ctx context.Context, ps *PipelineState, ref reference.Named, df dockerfile.Dockerfile, buildPath string, filter model.PathMatcher, buildArgs map[string]string) (reference.NamedTagged, error)
+	// Builds db.BuildPath with the given Dockerfile, which may differ from
+	// db.Dockerfile (e.g., if we added a cache stage).
+	BuildImage(ctx context.Context, ps *PipelineState, ref reference.Named, df dockerfile.Dockerfile, db model.DockerBuild, filter model.PathMatcher) (reference.NamedTagged, error)
 	DeprecatedFastBuildImage(ctx context.Context, ps *PipelineState, ref reference.Named, baseDockerfile dockerfile.Dockerfile, syncs []model.Sync, filter model.PathMatcher, runs []model.Run, entrypoint model.Cmd) (reference.NamedTagged, error)
+
+	// Builds pb.BuildPath with Cloud Native Buildpacks, by running the
+	// builder's lifecycle in a container.
+	PackBuildImage(ctx context.Context, ps *PipelineState, ref reference.Named, pb model.PackBuild, filter model.PathMatcher) (reference.NamedTagged, error)
 	PushImage(ctx context.Context, name reference.NamedTagged, writer io.Writer) (reference.NamedTagged, error)
 	TagImage(ctx context.Context, name reference.Named, dig digest.Digest) (reference.NamedTagged, error)
 	ImageExists(ctx context.Context, ref reference.NamedTagged) (bool, error)
@@ -59,17 +65,24 @@ func NewDockerImageBuilder(dCli docker.Client, extraLabels dockerfile.Labels) *d
 	}
 }
 
-func (d *dockerImageBuilder) BuildImage(ctx context.Context, ps *PipelineState, ref reference.Named, df dockerfile.Dockerfile, buildPath string, filter model.PathMatcher, buildArgs map[string]string) (reference.NamedTagged, error) {
+func (d *dockerImageBuilder) BuildImage(ctx context.Context, ps *PipelineState, ref reference.Named, df dockerfile.Dockerfile, db model.DockerBuild, filter model.PathMatcher) (reference.NamedTagged, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "dib-BuildImage")
 	defer span.Finish()
 
+	// The Docker daemon can only export the build cache inline, in the image
+	// itself, so we can't push it to a separate cache image.
+	if db.CacheTo != "" {
+		return nil, fmt.Errorf("cache_to requires building with BuildKit directly. "+
+			"Set buildkit_addr in update_settings() or run with --buildkit-addr, or remove cache_to (%s)", db.CacheTo)
+	}
+
 	paths := []PathMapping{
 		{
-			LocalPath:     buildPath,
+			LocalPath:     db.BuildPath,
 			ContainerPath: "/",
 		},
 	}
-	return d.buildFromDf(ctx, ps, df, paths, filter, ref, buildArgs)
+	return d.buildFromDf(ctx, ps, df, paths, filter, ref, db)
 }
 
 func (d *dockerImageBuilder) DeprecatedFastBuildImage(ctx context.Context, ps *PipelineState, ref reference.Named, baseDockerfile dockerfile.Dockerfile,
@@ -95,7 +108,7 @@ func (d *dockerImageBuilder) DeprecatedFastBuildImage(ctx context.Context, ps *P
 	}
 
 	df = d.applyLabels(df, BuildModeScratch)
-	return d.buildFromDf(ctx, ps, df, paths, filter, ref, model.DockerBuildArgs{})
+	return d.buildFromDf(ctx, ps, df, paths, filter, ref, model.DockerBuild{})
 }
 
 func (d *dockerImageBuilder) applyLabels(df dockerfile.Dockerfile, buildMode dockerfile.LabelValue) dockerfile.Dockerfile {
@@ -272,7 +285,7 @@ func (d *dockerImageBuilder) ImageExists(ctx context.Context, ref reference.Name
 	return len(images) > 0, nil
 }
 
-func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState, df dockerfile.Dockerfile, paths []PathMapping, filter model.PathMatcher, ref reference.Named, buildArgs model.DockerBuildArgs) (reference.NamedTagged, error) {
+func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState, df dockerfile.Dockerfile, paths []PathMapping, filter model.PathMatcher, ref reference.Named, db model.DockerBuild) (reference.NamedTagged, error) {
 	logger.Get(ctx).Infof("Building Dockerfile:\n%s\n", indent(df.String(), "  "))
 	span, ctx := opentracing.StartSpanFromContext(ctx, "daemon-buildFromDf")
 	defer span.Finish()
@@ -296,13 +309,15 @@ func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState,
 		}
 	}()
 
+	options := Options(pr, db.BuildArgs)
+	options.Target = db.TargetStage
+	options.SSHSpecs = db.SSHSpecs
+	options.SecretSpecs = db.SecretSpecs
+	options.CacheFrom = db.CacheFrom
+
 	ps.StartBuildStep(ctx, "Building image")
 	spanBuild, ctx := opentracing.StartSpanFromContext(ctx, "daemon-ImageBuild")
-	imageBuildResponse, err := d.dCli.ImageBuild(
-		ctx,
-		pr,
-		Options(pr, buildArgs),
-	)
+	imageBuildResponse, err := d.dCli.ImageBuild(ctx, pr, options)
 	spanBuild.Finish()
 	if err != nil {
 		return nil, err
@@ -315,9 +330,15 @@ func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState,
 		}
 	}()
 
-	digest, err := d.getDigestFromBuildOutput(ctx, imageBuildResponse.Body, ps.Writer(ctx))
+	output, err := readDockerOutput(ctx, imageBuildResponse.Body, ps.Writer(ctx))
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrap(err, "ImageBuild")
+	}
+	ps.AddCacheStats(output.cacheHits, output.cacheMisses)
+
+	digest, err := d.getDigestFromDockerOutput(ctx, output)
+	if err != nil {
+		return nil, errors.Wrap(err, "getDigestFromBuildOutput")
 	}
 
 	nt, err := d.TagImage(ctx, ref, digest)
@@ -415,6 +436,8 @@ func readDockerOutput(ctx context.Context, reader io.Reader, writer io.Writer) (
 	if ctx.Err() != nil {
 		return dockerOutput{}, ctx.Err()
 	}
+	result.cacheHits = b.cacheHits
+	result.cacheMisses = b.cacheMisses
 	return result, nil
 }
 
@@ -520,6 +543,8 @@ var oldDigestRegexp = regexp.MustCompile(`^Successfully built ([0-9a-f]+)\s*$`)
 type dockerOutput struct {
 	aux         *json.RawMessage
 	shortDigest string
+	cacheHits   int
+	cacheMisses int
 }
 
 func indent(text, indent string) string {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/docker"
@@ -93,6 +94,18 @@ func TestDigestFromOutputV1_23(t *testing.T) {
 	}
 }
 
+func TestCacheToNeedsBuildKit(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	defer f.teardown()
+
+	db := model.DockerBuild{BuildPath: f.Path(), CacheTo: "gcr.io/foo-cache:ci"}
+	_, err := f.b.BuildImage(f.ctx, f.ps, f.getNameFromTest(), simpleDockerfile, db, model.EmptyMatcher)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cache_to requires building with BuildKit directly")
+	}
+	assert.Equal(t, 0, f.fakeDocker.BuildCount)
+}
+
 func TestConditionalRunInFakeDocker(t *testing.T) {
 	f := newFakeDockerBuildFixture(t)
 	defer f.teardown()
@@ -0,0 +1,225 @@
+package build
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/opencontainers/go-digest"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/docker"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// The buildpacks lifecycle binary that runs every phase of the build
+// (detect, analyze, restore, build, export) in a single container.
+const packCreator = "/cnb/lifecycle/creator"
+
+// Where the lifecycle expects the app source and the platform env vars.
+const packAppDir = "/workspace"
+const packPlatformDir = "/platform"
+
+// The lifecycle exports the image straight to the Docker daemon
+// through its socket.
+const packDockerSocket = "/var/run/docker.sock"
+
+// We always export to the same tag, so that the lifecycle can find the
+// image it built last time and reuse its layers.
+const packBuildTag = "tilt-pack-build"
+
+func (d *dockerImageBuilder) PackBuildImage(ctx context.Context, ps *PipelineState, ref reference.Named, pb model.PackBuild, filter model.PathMatcher) (reference.NamedTagged, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "daemon-PackBuildImage")
+	defer span.Finish()
+
+	ps.StartBuildStep(ctx, "Inspecting builder %s", pb.Builder)
+	uid, gid, err := d.packBuilderUser(ctx, ps, pb.Builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "PackBuildImage")
+	}
+
+	buildRef, err := reference.WithTag(ref, packBuildTag)
+	if err != nil {
+		return nil, errors.Wrap(err, "PackBuildImage")
+	}
+
+	ps.StartBuildStep(ctx, "Running buildpacks")
+	pr, pw := io.Pipe()
+	go func() {
+		err := tarPackContext(ctx, pw, pb, filter, uid, gid)
+		if err != nil {
+			_ = pw.CloseWithError(err)
+		} else {
+			_ = pw.Close()
+		}
+	}()
+
+	err = d.dCli.RunContainer(ctx, docker.RunContainerOptions{
+		Image: pb.Builder,
+		Cmd: model.Cmd{Argv: []string{
+			packCreator,
+			"-daemon",
+			"-app", packAppDir,
+			"-platform", packPlatformDir,
+			buildRef.String(),
+		}},
+		// The lifecycle needs root to talk to the Docker socket,
+		// and drops down to the builder's user for the build itself.
+		User:    "root",
+		Binds:   []string{fmt.Sprintf("%s:%s", packDockerSocket, packDockerSocket)},
+		Content: pr,
+	}, ps.Writer(ctx))
+	_ = pr.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "PackBuildImage")
+	}
+
+	inspect, _, err := d.dCli.ImageInspectWithRaw(ctx, buildRef.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "PackBuildImage")
+	}
+
+	return d.TagImage(ctx, ref, digest.Digest(inspect.ID))
+}
+
+// Returns the user that the builder runs buildpacks as, pulling the builder
+// if we don't have it yet.
+func (d *dockerImageBuilder) packBuilderUser(ctx context.Context, ps *PipelineState, builder string) (uid int, gid int, err error) {
+	inspect, _, err := d.dCli.ImageInspectWithRaw(ctx, builder)
+	if client.IsErrNotFound(err) {
+		ps.Printf(ctx, "Pulling %s", builder)
+		resp, err := d.dCli.ImagePull(ctx, builder, types.ImagePullOptions{})
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "pulling builder %s", builder)
+		}
+		_, err = readDockerOutput(ctx, resp, ps.Writer(ctx))
+		_ = resp.Close()
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "pulling builder %s", builder)
+		}
+
+		inspect, _, err = d.dCli.ImageInspectWithRaw(ctx, builder)
+	}
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "inspecting builder %s", builder)
+	}
+
+	var env []string
+	if inspect.Config != nil {
+		env = inspect.Config.Env
+	}
+
+	uid, err = packBuilderEnvInt(env, "CNB_USER_ID")
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "%s is not a buildpacks builder", builder)
+	}
+	gid, err = packBuilderEnvInt(env, "CNB_GROUP_ID")
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "%s is not a buildpacks builder", builder)
+	}
+	return uid, gid, nil
+}
+
+func packBuilderEnvInt(env []string, key string) (int, error) {
+	for _, e := range env {
+		if !strings.HasPrefix(e, key+"=") {
+			continue
+		}
+
+		val, err := strconv.Atoi(strings.TrimPrefix(e, key+"="))
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %v", e, err)
+		}
+		return val, nil
+	}
+	return 0, fmt.Errorf("missing %s", key)
+}
+
+// Writes a tarball to copy into the builder container:
+// the app source at /workspace, and the env vars at /platform/env,
+// all owned by the builder's user so that the buildpacks can write to them.
+func tarPackContext(ctx context.Context, writer io.Writer, pb model.PackBuild, filter model.PathMatcher, uid, gid int) error {
+	tw := tar.NewWriter(writer)
+
+	pr, pw := io.Pipe()
+	defer func() { _ = pr.Close() }()
+	go tarArchiveForPaths(ctx, pw, []PathMapping{
+		{LocalPath: pb.BuildPath, ContainerPath: packAppDir},
+	}, filter)
+
+	tr := tar.NewReader(pr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "tarPackContext")
+		}
+
+		header.Uid = uid
+		header.Gid = gid
+		err = tw.WriteHeader(header)
+		if err != nil {
+			return errors.Wrap(err, "tarPackContext")
+		}
+		_, err = io.Copy(tw, tr)
+		if err != nil {
+			return errors.Wrap(err, "tarPackContext")
+		}
+	}
+
+	envDir := path.Join(strings.TrimPrefix(packPlatformDir, "/"), "env")
+	dirs := []string{path.Dir(envDir), envDir}
+	for _, dir := range dirs {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     dir,
+			Typeflag: tar.TypeDir,
+			Mode:     0755,
+			Uid:      uid,
+			Gid:      gid,
+			ModTime:  time.Now(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "tarPackContext")
+		}
+	}
+
+	keys := make([]string, 0, len(pb.Env))
+	for k := range pb.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := pb.Env[k]
+		err := tw.WriteHeader(&tar.Header{
+			Name:     path.Join(envDir, k),
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+			Size:     int64(len(v)),
+			Uid:      uid,
+			Gid:      gid,
+			ModTime:  time.Now(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "tarPackContext")
+		}
+		_, err = tw.Write([]byte(v))
+		if err != nil {
+			return errors.Wrap(err, "tarPackContext")
+		}
+	}
+
+	return tw.Close()
+}
@@ -0,0 +1,118 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	wmcontainer "github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/docker"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+const testPackBuilder = "gcr.io/buildpacks/builder:v1"
+
+func TestPackBuildImage(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	defer f.teardown()
+
+	f.fakeDocker.Images[testPackBuilder] = types.ImageInspect{
+		Config: &container.Config{Env: []string{"CNB_USER_ID=1000", "CNB_GROUP_ID=1001"}},
+	}
+	f.fakeDocker.Images["gcr.io/foo/bar:tilt-pack-build"] = types.ImageInspect{ID: docker.ExampleBuildSHA1}
+
+	f.WriteFile("main.go", "package main")
+	f.WriteFile(".git/HEAD", "ref: refs/heads/master")
+
+	ref := wmcontainer.MustParseNamed("gcr.io/foo/bar")
+	pb := model.PackBuild{
+		BuildPath: f.Path(),
+		Builder:   testPackBuilder,
+		Env:       map[string]string{"BP_GO_VERSION": "1.13"},
+	}
+	filter, err := model.NewSimpleFileMatcher(f.JoinPath(".git", "HEAD"))
+	require.NoError(t, err)
+	result, err := f.b.PackBuildImage(f.ctx, f.ps, ref, pb, filter)
+	require.NoError(t, err)
+
+	assert.Equal(t, "gcr.io/foo/bar:tilt-11cd0b38bc3ceb95", result.String())
+	assert.Empty(t, f.fakeDocker.PulledImages)
+
+	require.Len(t, f.fakeDocker.RunCalls, 1)
+	call := f.fakeDocker.RunCalls[0]
+	assert.Equal(t, testPackBuilder, call.Options.Image)
+	assert.Equal(t, []string{
+		"/cnb/lifecycle/creator", "-daemon", "-app", "/workspace", "-platform", "/platform",
+		"gcr.io/foo/bar:tilt-pack-build",
+	}, call.Options.Cmd.Argv)
+	assert.Equal(t, []string{"/var/run/docker.sock:/var/run/docker.sock"}, call.Options.Binds)
+
+	files := readPackContext(t, call.Content)
+	assert.Equal(t, "package main", files["workspace/main.go"])
+	assert.Equal(t, "1.13", files["platform/env/BP_GO_VERSION"])
+	_, hasIgnored := files["workspace/.git/HEAD"]
+	assert.False(t, hasIgnored)
+}
+
+func TestPackBuildImagePullsBuilder(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	defer f.teardown()
+
+	ref := wmcontainer.MustParseNamed("gcr.io/foo/bar")
+	pb := model.PackBuild{BuildPath: f.Path(), Builder: testPackBuilder}
+	_, err := f.b.PackBuildImage(f.ctx, f.ps, ref, pb, model.EmptyMatcher)
+
+	// The fake client doesn't have the builder even after pulling it.
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "inspecting builder gcr.io/buildpacks/builder:v1")
+	}
+	assert.Equal(t, []string{testPackBuilder}, f.fakeDocker.PulledImages)
+	assert.Empty(t, f.fakeDocker.RunCalls)
+}
+
+func TestPackBuildImageNotABuilder(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	defer f.teardown()
+
+	f.fakeDocker.Images["golang:1.13"] = types.ImageInspect{Config: &container.Config{}}
+
+	ref := wmcontainer.MustParseNamed("gcr.io/foo/bar")
+	pb := model.PackBuild{BuildPath: f.Path(), Builder: "golang:1.13"}
+	_, err := f.b.PackBuildImage(f.ctx, f.ps, ref, pb, model.EmptyMatcher)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "golang:1.13 is not a buildpacks builder: missing CNB_USER_ID")
+	}
+	assert.Empty(t, f.fakeDocker.RunCalls)
+}
+
+// Returns the contents of the regular files in the tarball, and checks
+// that everything is owned by the builder's user.
+func readPackContext(t *testing.T, content []byte) map[string]string {
+	result := make(map[string]string)
+	tr := tar.NewReader(bytes.NewReader(content))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		assert.Equal(t, 1000, header.Uid, header.Name)
+		assert.Equal(t, 1001, header.Gid, header.Name)
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		contents, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		result[header.Name] = string(contents)
+	}
+	return result
+}
@@ -18,6 +18,10 @@ type PipelineState struct {
 	curPipelineStart       time.Time
 	curPipelineStepStart   time.Time
 	c                      Clock
+
+	// Cache hits and misses across all the BuildKit builds in this pipeline.
+	cacheHits   int
+	cacheMisses int
 }
 
 type Clock interface {
@@ -66,11 +70,22 @@ func (ps *PipelineState) End(ctx context.Context, err error) {
 		l.Infof("%sStep %d - %.3fs", prefix, i+1, duration.Seconds())
 	}
 
+	if ps.cacheHits+ps.cacheMisses > 0 {
+		l.Infof("%sBuild cache: %d hits, %d misses", prefix, ps.cacheHits, ps.cacheMisses)
+	}
+
 	l.Infof("%sDone in: %.3fs \n", prefix, elapsed.Seconds())
 	ps.curPipelineStep = 0
 	ps.curBuildStep = 0
 }
 
+// Records how many build steps BuildKit found in its cache, so that we
+// can show them in the summary.
+func (ps *PipelineState) AddCacheStats(hits, misses int) {
+	ps.cacheHits += hits
+	ps.cacheMisses += misses
+}
+
 func (ps *PipelineState) StartPipelineStep(ctx context.Context, format string, a ...interface{}) {
 	l := logger.Get(ctx)
 	line := logger.Blue(l).Sprintf("STEP %d/%d — ", ps.curPipelineStep, ps.totalPipelineStepCount)
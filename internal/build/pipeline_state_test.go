@@ -53,6 +53,20 @@ func TestPipelineMultilinePrint(t *testing.T) {
 	assertSnapshot(t, out.String())
 }
 
+func TestPipelineCacheStats(t *testing.T) {
+	var err error
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, out))
+	ps := NewPipelineState(ctx, 1, fakeClock{})
+	ps.StartPipelineStep(ctx, "%s %s", "hello", "world")
+	ps.AddCacheStats(3, 1)
+	ps.AddCacheStats(1, 0)
+	ps.EndPipelineStep(ctx)
+	ps.End(ctx, err)
+
+	assertSnapshot(t, out.String())
+}
+
 func assertSnapshot(t *testing.T, output string) {
 	d1 := []byte(output)
 	gmPath := fmt.Sprintf("testdata/%s_master", t.Name())
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/spf13/cobra"
+
+	"github.com/windmilleng/tilt/internal/analytics"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/tiltfile"
+	"github.com/windmilleng/tilt/pkg/logger"
+)
+
+const DefaultCITimeout = 30 * time.Minute
+
+type ciCmd struct {
+	fileName           string
+	timeout            time.Duration
+	maxParallelUpdates int
+
+	// Set by cobra before run(). Args after the `--` are passed to the Tiltfile.
+	argsLenAtDash int
+}
+
+func (c *ciCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ci [<name>] [<name2>] [...] [-- <Tiltfile args>]",
+		Short: "stand up one or more manifests, then exit once they're healthy",
+		Long: `Starts Tilt without the HUD or file watching, and builds and deploys every resource once.
+
+Exits with status 0 as soon as every resource is built and healthy.
+Exits with a non-zero status as soon as any build fails, any resource
+crashes, or the timeout expires.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			c.argsLenAtDash = cmd.ArgsLenAtDash()
+		},
+	}
+
+	cmd.Flags().StringVar(&c.fileName, "file", tiltfile.FileName, "Path to Tiltfile")
+	cmd.Flags().DurationVar(&c.timeout, "timeout", DefaultCITimeout, "How long to wait for all resources to become healthy before failing. Set to 0 to wait forever.")
+	cmd.Flags().BoolVar(&logActionsFlag, "logactions", false, "log all actions and state changes")
+	cmd.Flags().Lookup("logactions").Hidden = true
+	cmd.Flags().IntVar(&c.maxParallelUpdates, "max-parallel-updates", 0, "Maximum number of resources to update at once. If set, overrides max_parallel_updates in the Tiltfile.")
+	addNamespaceFlag(cmd)
+
+	return cmd
+}
+
+func (c *ciCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.ci", map[string]string{})
+	defer a.Flush(time.Second)
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "CI")
+	defer span.Finish()
+
+	deferred := logger.NewDeferredLogger(ctx)
+	ctx = redirectLogs(ctx, deferred)
+
+	logOutput(fmt.Sprintf("Starting Tilt (%s)…", buildStamp()))
+
+	threads, err := wireThreads(ctx, a)
+	if err != nil {
+		deferred.SetOutput(deferred.Original())
+		return err
+	}
+
+	upper := threads.upper
+
+	l := store.NewLogActionLogger(ctx, upper.Dispatch)
+	deferred.SetOutput(l)
+	ctx = redirectLogs(ctx, l)
+
+	manifestNames, configArgs := splitArgsAtDash(args, c.argsLenAtDash)
+
+	// Unlike `tilt up`, a canceled context is a failure: we exited
+	// before we knew whether everything was healthy.
+	return upper.Start(ctx, manifestNames, configArgs, threads.tiltBuild, false, c.fileName, false, threads.sailMode, a.Opt(), c.maxParallelUpdates, false, store.EngineModeCI, c.timeout)
+}
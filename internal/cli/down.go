@@ -25,6 +25,7 @@ func (c *downCmd) register() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&c.fileName, "file", tiltfile.FileName, "Path to Tiltfile")
+	addNamespaceFlag(cmd)
 
 	return cmd
 }
@@ -42,7 +43,7 @@ func (c *downCmd) run(ctx context.Context, args []string) error {
 }
 
 func (c *downCmd) down(ctx context.Context, downDeps DownDeps) error {
-	tlr, err := downDeps.tfl.Load(ctx, c.fileName, nil)
+	tlr, err := downDeps.tfl.Load(ctx, c.fileName, nil, nil)
 	if err != nil {
 		return err
 	}
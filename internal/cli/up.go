@@ -38,18 +38,28 @@ var noBrowser bool = false
 var logActionsFlag bool = false
 var sailEnabled bool = false
 var sailModeFlag model.SailMode = model.SailModeProd
+var namespaceOverride string
+var buildkitAddr string
 
 type upCmd struct {
-	watch     bool
-	traceTags string
-	hud       bool
-	fileName  string
+	watch              bool
+	traceTags          string
+	hud                bool
+	fileName           string
+	maxParallelUpdates int
+	confirmChanges     bool
+
+	// Set by cobra before run(). Args after the `--` are passed to the Tiltfile.
+	argsLenAtDash int
 }
 
 func (c *upCmd) register() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "up [<name>] [<name2>] [...]",
+		Use:   "up [<name>] [<name2>] [...] [-- <Tiltfile args>]",
 		Short: "stand up one or more manifests",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			c.argsLenAtDash = cmd.ArgsLenAtDash()
+		},
 	}
 
 	cmd.Flags().BoolVar(&c.watch, "watch", true, "If true, services will be automatically rebuilt and redeployed when files change. Otherwise, each service will be started once.")
@@ -68,6 +78,10 @@ func (c *upCmd) register() *cobra.Command {
 	cmd.Flags().Lookup("logactions").Hidden = true
 	cmd.Flags().StringVar(&c.fileName, "file", tiltfile.FileName, "Path to Tiltfile")
 	cmd.Flags().BoolVar(&noBrowser, "no-browser", false, "If true, web UI will not open on startup.")
+	cmd.Flags().IntVar(&c.maxParallelUpdates, "max-parallel-updates", 0, "Maximum number of resources to update at once. If set, overrides max_parallel_updates in the Tiltfile.")
+	addNamespaceFlag(cmd)
+	cmd.Flags().StringVar(&buildkitAddr, "buildkit-addr", "", "If set, builds images with the BuildKit daemon at this address (e.g., unix:///run/buildkit/buildkitd.sock) instead of the Docker daemon. Overrides buildkit_addr in the Tiltfile.")
+	cmd.Flags().BoolVar(&c.confirmChanges, "confirm-changes", false, "If true, Tilt waits for you to review and approve (in the HUD) YAML changes to objects that are already running, and anything that would delete and re-create a running object.")
 
 	err := cmd.Flags().MarkHidden("image-tag-prefix")
 	if err != nil {
@@ -144,9 +158,11 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 		})
 	}
 
+	manifestNames, configArgs := splitArgsAtDash(args, c.argsLenAtDash)
+
 	g.Go(func() error {
 		defer cancel()
-		return upper.Start(ctx, args, threads.tiltBuild, c.watch, c.fileName, c.hud, threads.sailMode, a.Opt())
+		return upper.Start(ctx, manifestNames, configArgs, threads.tiltBuild, c.watch, c.fileName, c.hud, threads.sailMode, a.Opt(), c.maxParallelUpdates, c.confirmChanges, store.EngineModeUp, 0)
 	})
 
 	err = g.Wait()
@@ -157,6 +173,15 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 	}
 }
 
+// Splits the positional args into resource names and args for the Tiltfile's
+// config module, e.g., `tilt up frontend -- --env=staging`.
+func splitArgsAtDash(args []string, argsLenAtDash int) (manifestNames []string, configArgs []string) {
+	if argsLenAtDash < 0 {
+		return args, nil
+	}
+	return args[:argsLenAtDash], args[argsLenAtDash:]
+}
+
 func redirectLogs(ctx context.Context, l logger.Logger) context.Context {
 	ctx = logger.WithLogger(ctx, l)
 	log.SetOutput(l.Writer(logger.InfoLvl))
@@ -181,6 +206,19 @@ func provideKubectlLogLevel() k8s.KubectlLogLevel {
 	return k8s.KubectlLogLevel(klogLevel)
 }
 
+func addNamespaceFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&namespaceOverride, "namespace", "",
+		"If set, deploys every namespaced object to this namespace, instead of the namespace in its YAML. The namespace must already exist.")
+}
+
+func provideNamespaceOverride() k8s.NamespaceOverride {
+	return k8s.NamespaceOverride(namespaceOverride)
+}
+
+func provideBuildKitAddr() build.BuildKitAddr {
+	return build.BuildKitAddr(buildkitAddr)
+}
+
 func provideWebMode(b model.TiltBuild) (model.WebMode, error) {
 	switch webModeFlag {
 	case model.LocalWebMode, model.ProdWebMode, model.PrecompiledWebMode:
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/wire"
 	"github.com/jonboulle/clockwork"
+	"github.com/windmilleng/wmclient/pkg/dirs"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/tools/clientcmd/api"
 
@@ -33,6 +34,7 @@ import (
 var K8sWireSet = wire.NewSet(
 	k8s.ProvideEnv,
 	k8s.DetectNodeIP,
+	provideNamespaceOverride,
 	k8s.ProvideKubeContext,
 	k8s.ProvideKubeConfig,
 	k8s.ProvideClientConfig,
@@ -57,6 +59,7 @@ var BaseWireSet = wire.NewSet(
 	build.NewImageReaper,
 
 	tiltfile.ProvideTiltfileLoader,
+	dirs.UseWindmillDir,
 
 	clockwork.NewRealClock,
 	engine.DeployerWireSet,
@@ -65,7 +68,14 @@ var BaseWireSet = wire.NewSet(
 	engine.NewBuildController,
 	engine.NewPodWatcher,
 	engine.NewServiceWatcher,
+	engine.NewJobWatcher,
 	engine.NewEventWatchManager,
+	engine.NewLocalServeController,
+	engine.NewCIController,
+	engine.NewDisableController,
+	engine.NewHistoryController,
+	engine.NewRestartController,
+	engine.NewPruneController,
 	engine.NewImageController,
 	engine.NewConfigsController,
 	engine.NewDockerComposeEventWatcher,
@@ -108,7 +118,8 @@ var BaseWireSet = wire.NewSet(
 	client.SailWireSet,
 
 	provideThreads,
-	engine.NewKINDPusher,
+	engine.NewClusterImageLoader,
+	provideBuildKitAddr,
 
 	wire.Value(feature.MainDefaults),
 )
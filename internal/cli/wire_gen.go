@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/wire"
 	"github.com/jonboulle/clockwork"
+	"github.com/windmilleng/wmclient/pkg/dirs"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/tools/clientcmd/api"
 
@@ -52,7 +53,8 @@ func wireDemo(ctx context.Context, branch demo.RepoBranch, analytics2 *analytics
 	if err != nil {
 		return demo.Script{}, err
 	}
-	clientConfig := k8s.ProvideClientConfig()
+	namespaceOverride := provideNamespaceOverride()
+	clientConfig := k8s.ProvideClientConfig(namespaceOverride)
 	config, err := k8s.ProvideKubeConfig(clientConfig)
 	if err != nil {
 		return demo.Script{}, err
@@ -66,7 +68,7 @@ func wireDemo(ctx context.Context, branch demo.RepoBranch, analytics2 *analytics
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, namespaceOverride, kubectlRunner, clientConfig)
 	ownerFetcher := k8s.ProvideOwnerFetcher(k8sClient)
 	podWatcher := engine.NewPodWatcher(k8sClient, ownerFetcher)
 	nodeIP, err := k8s.DetectNodeIP(ctx, env)
@@ -74,6 +76,7 @@ func wireDemo(ctx context.Context, branch demo.RepoBranch, analytics2 *analytics
 		return demo.Script{}, err
 	}
 	serviceWatcher := engine.NewServiceWatcher(k8sClient, ownerFetcher, nodeIP)
+	jobWatcher := engine.NewJobWatcher(k8sClient)
 	podLogManager := engine.NewPodLogManager(k8sClient)
 	portForwardController := engine.NewPortForwardController(k8sClient)
 	fsWatcherMaker := engine.ProvideFsWatcherMaker()
@@ -114,18 +117,24 @@ func wireDemo(ctx context.Context, branch demo.RepoBranch, analytics2 *analytics
 	cacheBuilder := build.NewCacheBuilder(switchCli)
 	clock := build.ProvideClock()
 	execCustomBuilder := build.NewExecCustomBuilder(switchCli, clock)
-	kindPusher := engine.NewKINDPusher()
-	imageBuildAndDeployer := engine.NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, k8sClient, env, analytics2, updateMode, clock, runtime, kindPusher)
+	clusterImageLoader := engine.NewClusterImageLoader(localClient, k8sClient)
+	buildKitAddr := provideBuildKitAddr()
+	imageBuildAndDeployer := engine.NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, k8sClient, env, analytics2, updateMode, clock, runtime, clusterImageLoader, buildKitAddr)
 	dockerComposeClient := dockercompose.NewDockerComposeClient(localEnv)
 	imageAndCacheBuilder := engine.NewImageAndCacheBuilder(imageBuilder, cacheBuilder, execCustomBuilder, updateMode)
 	dockerComposeBuildAndDeployer := engine.NewDockerComposeBuildAndDeployer(dockerComposeClient, switchCli, imageAndCacheBuilder, clock)
-	buildOrder := engine.DefaultBuildOrder(liveUpdateBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, updateMode, env, runtime)
+	localTargetBuildAndDeployer := engine.NewLocalTargetBuildAndDeployer(clock)
+	buildOrder := engine.DefaultBuildOrder(liveUpdateBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, localTargetBuildAndDeployer, updateMode, env, runtime)
 	compositeBuildAndDeployer := engine.NewCompositeBuildAndDeployer(buildOrder)
 	buildController := engine.NewBuildController(compositeBuildAndDeployer)
 	imageReaper := build.NewImageReaper(switchCli)
 	imageController := engine.NewImageController(imageReaper)
 	defaults := _wireDefaultsValue
-	tiltfileLoader := tiltfile.ProvideTiltfileLoader(analytics2, k8sClient, dockerComposeClient, kubeContext, env, defaults)
+	windmillDir, err := dirs.UseWindmillDir()
+	if err != nil {
+		return demo.Script{}, err
+	}
+	tiltfileLoader := tiltfile.ProvideTiltfileLoader(analytics2, k8sClient, dockerComposeClient, kubeContext, env, defaults, windmillDir)
 	configsController := engine.NewConfigsController(tiltfileLoader, switchCli)
 	dockerComposeEventWatcher := engine.NewDockerComposeEventWatcher(dockerComposeClient)
 	dockerComposeLogManager := engine.NewDockerComposeLogManager(dockerComposeClient)
@@ -162,8 +171,14 @@ func wireDemo(ctx context.Context, branch demo.RepoBranch, analytics2 *analytics
 	tiltAnalyticsSubscriber := engine.NewTiltAnalyticsSubscriber(analytics2)
 	clockworkClock := clockwork.NewRealClock()
 	eventWatchManager := engine.NewEventWatchManager(k8sClient, clockworkClock)
-	v2 := engine.ProvideSubscribers(headsUpDisplay, podWatcher, serviceWatcher, podLogManager, portForwardController, watchManager, buildController, imageController, configsController, dockerComposeEventWatcher, dockerComposeLogManager, profilerManager, syncletManager, analyticsReporter, headsUpServerController, sailClient, tiltVersionChecker, tiltAnalyticsSubscriber, eventWatchManager)
-	upper := engine.NewUpper(ctx, storeStore, v2)
+	localServeController := engine.NewLocalServeController()
+	ciController := engine.NewCIController()
+	disableController := engine.NewDisableController(k8sClient, dockerComposeClient)
+	historyController := engine.NewHistoryController(windmillDir)
+	restartController := engine.NewRestartController()
+	pruneController := engine.NewPruneController(k8sClient, namespace, namespaceOverride)
+	v2 := engine.ProvideSubscribers(headsUpDisplay, podWatcher, serviceWatcher, jobWatcher, podLogManager, portForwardController, watchManager, buildController, imageController, configsController, dockerComposeEventWatcher, dockerComposeLogManager, profilerManager, syncletManager, analyticsReporter, headsUpServerController, sailClient, tiltVersionChecker, tiltAnalyticsSubscriber, eventWatchManager, localServeController, ciController, disableController, historyController, restartController, pruneController)
+	upper := engine.NewUpper(ctx, storeStore, v2, historyController)
 	script := demo.NewScript(upper, headsUpDisplay, k8sClient, env, storeStore, branch, runtime, tiltfileLoader)
 	return script, nil
 }
@@ -189,7 +204,8 @@ func wireThreads(ctx context.Context, analytics2 *analytics.TiltAnalytics) (Thre
 	reducer := _wireReducerValue
 	storeLogActionsFlag := provideLogActions()
 	storeStore := store.NewStore(reducer, storeLogActionsFlag)
-	clientConfig := k8s.ProvideClientConfig()
+	namespaceOverride := provideNamespaceOverride()
+	clientConfig := k8s.ProvideClientConfig(namespaceOverride)
 	config, err := k8s.ProvideKubeConfig(clientConfig)
 	if err != nil {
 		return Threads{}, err
@@ -203,7 +219,7 @@ func wireThreads(ctx context.Context, analytics2 *analytics.TiltAnalytics) (Thre
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, namespaceOverride, kubectlRunner, clientConfig)
 	ownerFetcher := k8s.ProvideOwnerFetcher(k8sClient)
 	podWatcher := engine.NewPodWatcher(k8sClient, ownerFetcher)
 	nodeIP, err := k8s.DetectNodeIP(ctx, env)
@@ -211,6 +227,7 @@ func wireThreads(ctx context.Context, analytics2 *analytics.TiltAnalytics) (Thre
 		return Threads{}, err
 	}
 	serviceWatcher := engine.NewServiceWatcher(k8sClient, ownerFetcher, nodeIP)
+	jobWatcher := engine.NewJobWatcher(k8sClient)
 	podLogManager := engine.NewPodLogManager(k8sClient)
 	portForwardController := engine.NewPortForwardController(k8sClient)
 	fsWatcherMaker := engine.ProvideFsWatcherMaker()
@@ -251,18 +268,24 @@ func wireThreads(ctx context.Context, analytics2 *analytics.TiltAnalytics) (Thre
 	cacheBuilder := build.NewCacheBuilder(switchCli)
 	clock := build.ProvideClock()
 	execCustomBuilder := build.NewExecCustomBuilder(switchCli, clock)
-	kindPusher := engine.NewKINDPusher()
-	imageBuildAndDeployer := engine.NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, k8sClient, env, analytics2, updateMode, clock, runtime, kindPusher)
+	clusterImageLoader := engine.NewClusterImageLoader(localClient, k8sClient)
+	buildKitAddr := provideBuildKitAddr()
+	imageBuildAndDeployer := engine.NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, k8sClient, env, analytics2, updateMode, clock, runtime, clusterImageLoader, buildKitAddr)
 	dockerComposeClient := dockercompose.NewDockerComposeClient(localEnv)
 	imageAndCacheBuilder := engine.NewImageAndCacheBuilder(imageBuilder, cacheBuilder, execCustomBuilder, updateMode)
 	dockerComposeBuildAndDeployer := engine.NewDockerComposeBuildAndDeployer(dockerComposeClient, switchCli, imageAndCacheBuilder, clock)
-	buildOrder := engine.DefaultBuildOrder(liveUpdateBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, updateMode, env, runtime)
+	localTargetBuildAndDeployer := engine.NewLocalTargetBuildAndDeployer(clock)
+	buildOrder := engine.DefaultBuildOrder(liveUpdateBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, localTargetBuildAndDeployer, updateMode, env, runtime)
 	compositeBuildAndDeployer := engine.NewCompositeBuildAndDeployer(buildOrder)
 	buildController := engine.NewBuildController(compositeBuildAndDeployer)
 	imageReaper := build.NewImageReaper(switchCli)
 	imageController := engine.NewImageController(imageReaper)
 	defaults := _wireDefaultsValue
-	tiltfileLoader := tiltfile.ProvideTiltfileLoader(analytics2, k8sClient, dockerComposeClient, kubeContext, env, defaults)
+	windmillDir, err := dirs.UseWindmillDir()
+	if err != nil {
+		return Threads{}, err
+	}
+	tiltfileLoader := tiltfile.ProvideTiltfileLoader(analytics2, k8sClient, dockerComposeClient, kubeContext, env, defaults, windmillDir)
 	configsController := engine.NewConfigsController(tiltfileLoader, switchCli)
 	dockerComposeEventWatcher := engine.NewDockerComposeEventWatcher(dockerComposeClient)
 	dockerComposeLogManager := engine.NewDockerComposeLogManager(dockerComposeClient)
@@ -299,14 +322,21 @@ func wireThreads(ctx context.Context, analytics2 *analytics.TiltAnalytics) (Thre
 	tiltAnalyticsSubscriber := engine.NewTiltAnalyticsSubscriber(analytics2)
 	clockworkClock := clockwork.NewRealClock()
 	eventWatchManager := engine.NewEventWatchManager(k8sClient, clockworkClock)
-	v2 := engine.ProvideSubscribers(headsUpDisplay, podWatcher, serviceWatcher, podLogManager, portForwardController, watchManager, buildController, imageController, configsController, dockerComposeEventWatcher, dockerComposeLogManager, profilerManager, syncletManager, analyticsReporter, headsUpServerController, sailClient, tiltVersionChecker, tiltAnalyticsSubscriber, eventWatchManager)
-	upper := engine.NewUpper(ctx, storeStore, v2)
+	localServeController := engine.NewLocalServeController()
+	ciController := engine.NewCIController()
+	disableController := engine.NewDisableController(k8sClient, dockerComposeClient)
+	historyController := engine.NewHistoryController(windmillDir)
+	restartController := engine.NewRestartController()
+	pruneController := engine.NewPruneController(k8sClient, namespace, namespaceOverride)
+	v2 := engine.ProvideSubscribers(headsUpDisplay, podWatcher, serviceWatcher, jobWatcher, podLogManager, portForwardController, watchManager, buildController, imageController, configsController, dockerComposeEventWatcher, dockerComposeLogManager, profilerManager, syncletManager, analyticsReporter, headsUpServerController, sailClient, tiltVersionChecker, tiltAnalyticsSubscriber, eventWatchManager, localServeController, ciController, disableController, historyController, restartController, pruneController)
+	upper := engine.NewUpper(ctx, storeStore, v2, historyController)
 	threads := provideThreads(headsUpDisplay, upper, tiltBuild, sailMode)
 	return threads, nil
 }
 
 func wireKubeContext(ctx context.Context) (k8s.KubeContext, error) {
-	clientConfig := k8s.ProvideClientConfig()
+	namespaceOverride := provideNamespaceOverride()
+	clientConfig := k8s.ProvideClientConfig(namespaceOverride)
 	config, err := k8s.ProvideKubeConfig(clientConfig)
 	if err != nil {
 		return "", err
@@ -319,7 +349,8 @@ func wireKubeContext(ctx context.Context) (k8s.KubeContext, error) {
 }
 
 func wireKubeConfig(ctx context.Context) (*api.Config, error) {
-	clientConfig := k8s.ProvideClientConfig()
+	namespaceOverride := provideNamespaceOverride()
+	clientConfig := k8s.ProvideClientConfig(namespaceOverride)
 	config, err := k8s.ProvideKubeConfig(clientConfig)
 	if err != nil {
 		return nil, err
@@ -328,7 +359,8 @@ func wireKubeConfig(ctx context.Context) (*api.Config, error) {
 }
 
 func wireEnv(ctx context.Context) (k8s.Env, error) {
-	clientConfig := k8s.ProvideClientConfig()
+	namespaceOverride := provideNamespaceOverride()
+	clientConfig := k8s.ProvideClientConfig(namespaceOverride)
 	config, err := k8s.ProvideKubeConfig(clientConfig)
 	if err != nil {
 		return "", err
@@ -338,13 +370,15 @@ func wireEnv(ctx context.Context) (k8s.Env, error) {
 }
 
 func wireNamespace(ctx context.Context) (k8s.Namespace, error) {
-	clientConfig := k8s.ProvideClientConfig()
+	namespaceOverride := provideNamespaceOverride()
+	clientConfig := k8s.ProvideClientConfig(namespaceOverride)
 	namespace := k8s.ProvideConfigNamespace(clientConfig)
 	return namespace, nil
 }
 
 func wireRuntime(ctx context.Context) (container.Runtime, error) {
-	clientConfig := k8s.ProvideClientConfig()
+	namespaceOverride := provideNamespaceOverride()
+	clientConfig := k8s.ProvideClientConfig(namespaceOverride)
 	config, err := k8s.ProvideKubeConfig(clientConfig)
 	if err != nil {
 		return "", err
@@ -358,13 +392,14 @@ func wireRuntime(ctx context.Context) (container.Runtime, error) {
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, namespaceOverride, kubectlRunner, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, k8sClient)
 	return runtime, nil
 }
 
 func wireK8sVersion(ctx context.Context) (*version.Info, error) {
-	clientConfig := k8s.ProvideClientConfig()
+	namespaceOverride := provideNamespaceOverride()
+	clientConfig := k8s.ProvideClientConfig(namespaceOverride)
 	config, err := k8s.ProvideRESTConfig(clientConfig)
 	if err != nil {
 		return nil, err
@@ -381,7 +416,8 @@ func wireK8sVersion(ctx context.Context) (*version.Info, error) {
 }
 
 func wireDockerClusterClient(ctx context.Context) (docker.ClusterClient, error) {
-	clientConfig := k8s.ProvideClientConfig()
+	namespaceOverride := provideNamespaceOverride()
+	clientConfig := k8s.ProvideClientConfig(namespaceOverride)
 	config, err := k8s.ProvideKubeConfig(clientConfig)
 	if err != nil {
 		return nil, err
@@ -395,7 +431,7 @@ func wireDockerClusterClient(ctx context.Context) (docker.ClusterClient, error)
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, namespaceOverride, kubectlRunner, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, k8sClient)
 	minikubeClient := minikube.ProvideMinikubeClient()
 	clusterEnv, err := docker.ProvideClusterEnv(ctx, env, runtime, minikubeClient)
@@ -418,7 +454,8 @@ func wireDockerClusterClient(ctx context.Context) (docker.ClusterClient, error)
 }
 
 func wireDockerLocalClient(ctx context.Context) (docker.LocalClient, error) {
-	clientConfig := k8s.ProvideClientConfig()
+	namespaceOverride := provideNamespaceOverride()
+	clientConfig := k8s.ProvideClientConfig(namespaceOverride)
 	config, err := k8s.ProvideKubeConfig(clientConfig)
 	if err != nil {
 		return nil, err
@@ -432,7 +469,7 @@ func wireDockerLocalClient(ctx context.Context) (docker.LocalClient, error) {
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, namespaceOverride, kubectlRunner, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, k8sClient)
 	minikubeClient := minikube.ProvideMinikubeClient()
 	clusterEnv, err := docker.ProvideClusterEnv(ctx, env, runtime, minikubeClient)
@@ -451,7 +488,8 @@ func wireDockerLocalClient(ctx context.Context) (docker.LocalClient, error) {
 }
 
 func wireDownDeps(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics) (DownDeps, error) {
-	clientConfig := k8s.ProvideClientConfig()
+	namespaceOverride := provideNamespaceOverride()
+	clientConfig := k8s.ProvideClientConfig(namespaceOverride)
 	config, err := k8s.ProvideKubeConfig(clientConfig)
 	if err != nil {
 		return DownDeps{}, err
@@ -465,7 +503,7 @@ func wireDownDeps(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics) (
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, namespaceOverride, kubectlRunner, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, k8sClient)
 	minikubeClient := minikube.ProvideMinikubeClient()
 	clusterEnv, err := docker.ProvideClusterEnv(ctx, env, runtime, minikubeClient)
@@ -478,24 +516,28 @@ func wireDownDeps(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics) (
 	}
 	dockerComposeClient := dockercompose.NewDockerComposeClient(localEnv)
 	defaults := _wireDefaultsValue
-	tiltfileLoader := tiltfile.ProvideTiltfileLoader(tiltAnalytics, k8sClient, dockerComposeClient, kubeContext, env, defaults)
+	windmillDir, err := dirs.UseWindmillDir()
+	if err != nil {
+		return DownDeps{}, err
+	}
+	tiltfileLoader := tiltfile.ProvideTiltfileLoader(tiltAnalytics, k8sClient, dockerComposeClient, kubeContext, env, defaults, windmillDir)
 	downDeps := ProvideDownDeps(tiltfileLoader, dockerComposeClient, k8sClient)
 	return downDeps, nil
 }
 
 // wire.go:
 
-var K8sWireSet = wire.NewSet(k8s.ProvideEnv, k8s.DetectNodeIP, k8s.ProvideKubeContext, k8s.ProvideKubeConfig, k8s.ProvideClientConfig, k8s.ProvideClientSet, k8s.ProvideRESTConfig, k8s.ProvidePortForwarder, k8s.ProvideConfigNamespace, k8s.ProvideKubectlRunner, k8s.ProvideContainerRuntime, k8s.ProvideServerVersion, k8s.ProvideK8sClient, k8s.ProvideOwnerFetcher)
+var K8sWireSet = wire.NewSet(k8s.ProvideEnv, k8s.DetectNodeIP, provideNamespaceOverride, k8s.ProvideKubeContext, k8s.ProvideKubeConfig, k8s.ProvideClientConfig, k8s.ProvideClientSet, k8s.ProvideRESTConfig, k8s.ProvidePortForwarder, k8s.ProvideConfigNamespace, k8s.ProvideKubectlRunner, k8s.ProvideContainerRuntime, k8s.ProvideServerVersion, k8s.ProvideK8sClient, k8s.ProvideOwnerFetcher)
 
 var BaseWireSet = wire.NewSet(
 	K8sWireSet,
-	provideKubectlLogLevel, docker.SwitchWireSet, dockercompose.NewDockerComposeClient, build.NewImageReaper, tiltfile.ProvideTiltfileLoader, clockwork.NewRealClock, engine.DeployerWireSet, engine.NewPodLogManager, engine.NewPortForwardController, engine.NewBuildController, engine.NewPodWatcher, engine.NewServiceWatcher, engine.NewEventWatchManager, engine.NewImageController, engine.NewConfigsController, engine.NewDockerComposeEventWatcher, engine.NewDockerComposeLogManager, engine.NewProfilerManager, engine.NewGithubClientFactory, engine.NewTiltVersionChecker, provideClock, hud.NewRenderer, hud.NewDefaultHeadsUpDisplay, provideLogActions, store.NewStore, wire.Bind(new(store.RStore), new(store.Store)), provideTiltInfo, engine.ProvideSubscribers, engine.NewUpper, engine.NewTiltAnalyticsSubscriber, engine.ProvideAnalyticsReporter, provideUpdateModeFlag, engine.NewWatchManager, engine.ProvideFsWatcherMaker, engine.ProvideTimerMaker, provideWebVersion,
+	provideKubectlLogLevel, docker.SwitchWireSet, dockercompose.NewDockerComposeClient, build.NewImageReaper, tiltfile.ProvideTiltfileLoader, dirs.UseWindmillDir, clockwork.NewRealClock, engine.DeployerWireSet, engine.NewPodLogManager, engine.NewPortForwardController, engine.NewBuildController, engine.NewPodWatcher, engine.NewServiceWatcher, engine.NewJobWatcher, engine.NewEventWatchManager, engine.NewLocalServeController, engine.NewCIController, engine.NewDisableController, engine.NewHistoryController, engine.NewRestartController, engine.NewPruneController, engine.NewImageController, engine.NewConfigsController, engine.NewDockerComposeEventWatcher, engine.NewDockerComposeLogManager, engine.NewProfilerManager, engine.NewGithubClientFactory, engine.NewTiltVersionChecker, provideClock, hud.NewRenderer, hud.NewDefaultHeadsUpDisplay, provideLogActions, store.NewStore, wire.Bind(new(store.RStore), new(store.Store)), provideTiltInfo, engine.ProvideSubscribers, engine.NewUpper, engine.NewTiltAnalyticsSubscriber, engine.ProvideAnalyticsReporter, provideUpdateModeFlag, engine.NewWatchManager, engine.ProvideFsWatcherMaker, engine.ProvideTimerMaker, provideWebVersion,
 	provideWebMode,
 	provideWebURL,
 	provideWebPort,
 	provideWebDevPort,
 	provideNoBrowserFlag, server.ProvideHeadsUpServer, assets.ProvideAssetServer, server.ProvideHeadsUpServerController, server.ProvideHttpClient, provideSailMode,
-	provideSailURL, client.SailWireSet, provideThreads, engine.NewKINDPusher, wire.Value(feature.MainDefaults),
+	provideSailURL, client.SailWireSet, provideThreads, engine.NewClusterImageLoader, provideBuildKitAddr, wire.Value(feature.MainDefaults),
 )
 
 type Threads struct {
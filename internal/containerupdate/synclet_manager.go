@@ -194,11 +194,12 @@ func newSyncletClient(ctx context.Context, kCli k8s.Client, podID k8s.PodID, ns
 	}
 
 	// TODO(nick): We need a better way to kill the client when the pod dies.
-	tunneledPort, tunnelCloser, err := kCli.ForwardPort(ctx, ns, podID, 0, synclet.Port)
+	tunnel, err := kCli.ForwardPort(ctx, ns, podID, 0, synclet.Port)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed opening tunnel to synclet pod '%s'", podID)
 	}
 
+	tunneledPort := tunnel.LocalPort
 	logger.Get(ctx).Verbosef("tunneling to synclet client at %s (local port %d)", podID.String(), tunneledPort)
 
 	t := opentracing.GlobalTracer()
@@ -212,5 +213,5 @@ func newSyncletClient(ctx context.Context, kCli k8s.Client, podID k8s.PodID, ns
 		return nil, errors.Wrap(err, "connecting to synclet")
 	}
 
-	return tunneledSyncletClient{synclet.NewGRPCClient(conn), tunnelCloser}, nil
+	return tunneledSyncletClient{synclet.NewGRPCClient(conn), tunnel.Close}, nil
 }
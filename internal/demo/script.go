@@ -83,7 +83,7 @@ func (m *podMonitor) OnChange(ctx context.Context, st store.RStore) {
 		m.healthy = false
 	}
 
-	if state.CurrentlyBuilding != "" {
+	if len(state.CurrentlyBuilding) > 0 {
 		m.healthy = false
 	}
 
@@ -185,7 +185,7 @@ func (s Script) Run(ctx context.Context) error {
 		}
 
 		tfPath := filepath.Join(dir, tiltfile.FileName)
-		tlr, err := s.tfl.Load(ctx, tfPath, nil)
+		tlr, err := s.tfl.Load(ctx, tfPath, nil, nil)
 		if err != nil {
 			return err
 		}
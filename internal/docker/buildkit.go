@@ -0,0 +1,150 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/moby/buildkit/session/secrets/secretsprovider"
+	"github.com/moby/buildkit/session/sshforward/sshprovider"
+	"github.com/pkg/errors"
+)
+
+// Parses an SSH spec in the same format as `docker build --ssh`:
+//
+// default|<id>[=<socket>|<key>[,<key>]]
+func ParseSSHSpec(spec string) (sshprovider.AgentConfig, error) {
+	parts := strings.SplitN(spec, "=", 2)
+	cfg := sshprovider.AgentConfig{ID: parts[0]}
+	if cfg.ID == "" {
+		return sshprovider.AgentConfig{}, fmt.Errorf("invalid ssh spec %q: missing id", spec)
+	}
+	if len(parts) > 1 {
+		cfg.Paths = strings.Split(parts[1], ",")
+	}
+	return cfg, nil
+}
+
+// Parses a secret spec in the same format as `docker build --secret`:
+//
+// id=<id>[,src=<path>]
+//
+// If there's no src, the id is used as the path. If there's no id,
+// the base name of the src is used as the id.
+func ParseSecretSpec(spec string) (secretsprovider.FileSource, error) {
+	fs := secretsprovider.FileSource{}
+	for _, field := range strings.Split(spec, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return secretsprovider.FileSource{}, fmt.Errorf("invalid secret spec %q: expected key=value, got %q", spec, field)
+		}
+
+		key, value := strings.ToLower(parts[0]), parts[1]
+		switch key {
+		case "type":
+			if value != "file" {
+				return secretsprovider.FileSource{}, fmt.Errorf("invalid secret spec %q: unsupported type %q", spec, value)
+			}
+		case "id":
+			fs.ID = value
+		case "source", "src":
+			fs.FilePath = value
+		default:
+			return secretsprovider.FileSource{}, fmt.Errorf("invalid secret spec %q: unexpected key %q", spec, key)
+		}
+	}
+
+	if fs.ID == "" && fs.FilePath != "" {
+		fs.ID = filepath.Base(fs.FilePath)
+	}
+	if fs.ID == "" {
+		return secretsprovider.FileSource{}, fmt.Errorf("invalid secret spec %q: missing id", spec)
+	}
+	return fs, nil
+}
+
+// Creates the session attachables that forward the given ssh agents and secrets
+// (in `docker build --ssh` and `docker build --secret` format) to a BuildKit build.
+func BuildKitAttachables(sshSpecs, secretSpecs []string) ([]session.Attachable, error) {
+	var result []session.Attachable
+	if len(sshSpecs) > 0 {
+		var configs []sshprovider.AgentConfig
+		for _, spec := range sshSpecs {
+			cfg, err := ParseSSHSpec(spec)
+			if err != nil {
+				return nil, err
+			}
+			configs = append(configs, cfg)
+		}
+
+		sp, err := sshprovider.NewSSHAgentProvider(configs)
+		if err != nil {
+			return nil, errors.Wrap(err, "setting up ssh forwarding")
+		}
+		result = append(result, sp)
+	}
+
+	if len(secretSpecs) > 0 {
+		var sources []secretsprovider.FileSource
+		for _, spec := range secretSpecs {
+			fs, err := ParseSecretSpec(spec)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, fs)
+		}
+
+		store, err := secretsprovider.NewFileStore(sources)
+		if err != nil {
+			return nil, errors.Wrap(err, "setting up secrets")
+		}
+		result = append(result, secretsprovider.NewSecretProvider(store))
+	}
+	return result, nil
+}
+
+func needsBuildKitSession(options BuildOptions) bool {
+	return len(options.SSHSpecs) > 0 || len(options.SecretSpecs) > 0
+}
+
+// SSH agents and secrets should only be visible to the build that asked for
+// them, so builds that use them get their own session instead of sharing
+// the session from initCreds.
+//
+// The session stays open until the returned closer is called.
+func (c *Cli) startBuildKitSession(ctx context.Context, options BuildOptions) (id string, closer func(), err error) {
+	s, err := session.NewSession(ctx, "tilt", sessionSharedKey)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "creating BuildKit session")
+	}
+	s.Allow(authprovider.NewDockerAuthProvider())
+
+	attachables, err := BuildKitAttachables(options.SSHSpecs, options.SecretSpecs)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, a := range attachables {
+		s.Allow(a)
+	}
+
+	go func() {
+		_ = s.Run(ctx, c.Client.DialSession)
+	}()
+	return s.ID(), func() { _ = s.Close() }, nil
+}
+
+// Closes the BuildKit session once we're done reading the build output.
+type sessionReadCloser struct {
+	io.ReadCloser
+	closeSession func()
+}
+
+func (r sessionReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.closeSession()
+	return err
+}
@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/session/secrets/secretsprovider"
+	"github.com/moby/buildkit/session/sshforward/sshprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSSHSpec(t *testing.T) {
+	cases := []struct {
+		spec     string
+		expected sshprovider.AgentConfig
+	}{
+		{"default", sshprovider.AgentConfig{ID: "default"}},
+		{"github=/home/nick/.ssh/id_rsa", sshprovider.AgentConfig{ID: "github", Paths: []string{"/home/nick/.ssh/id_rsa"}}},
+		{"default=a.pem,b.pem", sshprovider.AgentConfig{ID: "default", Paths: []string{"a.pem", "b.pem"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.spec, func(t *testing.T) {
+			actual, err := ParseSSHSpec(c.spec)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+
+	_, err := ParseSSHSpec("=/tmp/agent.sock")
+	assert.EqualError(t, err, `invalid ssh spec "=/tmp/agent.sock": missing id`)
+}
+
+func TestParseSecretSpec(t *testing.T) {
+	cases := []struct {
+		spec     string
+		expected secretsprovider.FileSource
+	}{
+		{"id=npmrc", secretsprovider.FileSource{ID: "npmrc"}},
+		{"id=npmrc,src=/home/nick/.npmrc", secretsprovider.FileSource{ID: "npmrc", FilePath: "/home/nick/.npmrc"}},
+		{"type=file,source=creds/gcp.json", secretsprovider.FileSource{ID: "gcp.json", FilePath: "creds/gcp.json"}},
+	}
+	for _, c := range cases {
+		t.Run(c.spec, func(t *testing.T) {
+			actual, err := ParseSecretSpec(c.spec)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+
+	_, err := ParseSecretSpec("npmrc")
+	assert.EqualError(t, err, `invalid secret spec "npmrc": expected key=value, got "npmrc"`)
+	_, err = ParseSecretSpec("type=env,id=token")
+	assert.EqualError(t, err, `invalid secret spec "type=env,id=token": unsupported type "env"`)
+}
+
+func TestImageBuildSSHRequiresBuildKit(t *testing.T) {
+	c := &Cli{builderVersion: "1", initDone: make(chan bool)}
+	close(c.initDone)
+
+	_, err := c.ImageBuild(context.Background(), nil, BuildOptions{SSHSpecs: []string{"default"}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "require BuildKit")
+	}
+}
@@ -15,7 +15,9 @@ import (
 	"github.com/blang/semver"
 	"github.com/docker/cli/cli/config"
 	"github.com/docker/docker/api/types"
+	typescontainer "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/tlsconfig"
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
@@ -76,12 +78,40 @@ type Client interface {
 	// Returns an ExitError if the command exits with a non-zero exit code.
 	ExecInContainer(ctx context.Context, cID container.ID, cmd model.Cmd, out io.Writer) error
 
+	// Execute a command in a container, streaming `in` to the command's stdin
+	// and the command output to `out`. Unlike ExecInContainer, doesn't allocate
+	// a TTY, so `in` can be binary (like an image tarball).
+	ExecInContainerWithInput(ctx context.Context, cID container.ID, cmd model.Cmd, in io.Reader, out io.Writer) error
+
 	ImagePush(ctx context.Context, image string, options types.ImagePushOptions) (io.ReadCloser, error)
+	ImagePull(ctx context.Context, image string, options types.ImagePullOptions) (io.ReadCloser, error)
 	ImageBuild(ctx context.Context, buildContext io.Reader, options BuildOptions) (types.ImageBuildResponse, error)
 	ImageTag(ctx context.Context, source, target string) error
 	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
 	ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
 	ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
+
+	// Streams the given images as a tarball, in the same format as `docker save`.
+	ImageSave(ctx context.Context, images []string) (io.ReadCloser, error)
+
+	// Runs a one-off container to completion, streaming its output to `out`,
+	// then removes it.
+	// Returns an ExitError if the container exits with a non-zero exit code.
+	RunContainer(ctx context.Context, opts RunContainerOptions, out io.Writer) error
+}
+
+type RunContainerOptions struct {
+	Image string
+	Cmd   model.Cmd
+	User  string
+	Env   []string
+
+	// Host paths or volumes to mount, in the same format as `docker run -v`.
+	Binds []string
+
+	// Optional: a tarball to extract at the root of the container's
+	// filesystem before it starts.
+	Content io.Reader
 }
 
 type ExitError struct {
@@ -364,8 +394,32 @@ func (c *Cli) ImageBuild(ctx context.Context, buildContext io.Reader, options Bu
 	opts.BuildArgs = options.BuildArgs
 	opts.Dockerfile = options.Dockerfile
 	opts.Tags = options.Tags
+	opts.Target = options.Target
+	opts.CacheFrom = options.CacheFrom
+
+	if !needsBuildKitSession(options) {
+		return c.Client.ImageBuild(ctx, buildContext, opts)
+	}
+
+	if c.builderVersion != types.BuilderBuildKit {
+		return types.ImageBuildResponse{}, fmt.Errorf("docker_build ssh and secret options require BuildKit, " +
+			"but this Docker daemon doesn't have BuildKit enabled. " +
+			"Try upgrading Docker, or setting DOCKER_BUILDKIT=1")
+	}
 
-	return c.Client.ImageBuild(ctx, buildContext, opts)
+	sessionID, closeSession, err := c.startBuildKitSession(ctx, options)
+	if err != nil {
+		return types.ImageBuildResponse{}, err
+	}
+	opts.SessionID = sessionID
+
+	res, err := c.Client.ImageBuild(ctx, buildContext, opts)
+	if err != nil {
+		closeSession()
+		return types.ImageBuildResponse{}, err
+	}
+	res.Body = sessionReadCloser{ReadCloser: res.Body, closeSession: closeSession}
+	return res, nil
 }
 
 func (c *Cli) CopyToContainerRoot(ctx context.Context, container string, content io.Reader) error {
@@ -433,8 +487,130 @@ func (c *Cli) ExecInContainer(ctx context.Context, cID container.ID, cmd model.C
 		return errors.Wrap(err, "ExecInContainer#copy")
 	}
 
+	return c.waitForExec(ctx, execId.ID)
+}
+
+func (c *Cli) ExecInContainerWithInput(ctx context.Context, cID container.ID, cmd model.Cmd, in io.Reader, out io.Writer) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "dockerCli-ExecInContainerWithInput")
+	span.SetTag("cmd", strings.Join(cmd.Argv, " "))
+	defer span.Finish()
+
+	cfg := types.ExecConfig{
+		Cmd:          cmd.Argv,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+
+	if _, err := c.ContainerInspect(ctx, cID.String()); err != nil {
+		return errors.Wrap(err, "ExecInContainerWithInput")
+	}
+
+	execId, err := c.ContainerExecCreate(ctx, cID.String(), cfg)
+	if err != nil {
+		return errors.Wrap(err, "ExecInContainerWithInput#create")
+	}
+
+	// Attaching starts the command.
+	connection, err := c.ContainerExecAttach(ctx, execId.ID, types.ExecStartCheck{})
+	if err != nil {
+		return errors.Wrap(err, "ExecInContainerWithInput#attach")
+	}
+	defer connection.Close()
+
+	inputErr := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(connection.Conn, in)
+		if err == nil {
+			err = connection.CloseWrite()
+		}
+		inputErr <- err
+	}()
+
+	// Without a TTY, stdout and stderr are multiplexed on the same stream.
+	_, err = stdcopy.StdCopy(out, out, connection.Reader)
+	if err != nil {
+		return errors.Wrap(err, "ExecInContainerWithInput#copy")
+	}
+
+	err = <-inputErr
+	if err != nil {
+		return errors.Wrap(err, "ExecInContainerWithInput#input")
+	}
+
+	return c.waitForExec(ctx, execId.ID)
+}
+
+func (c *Cli) RunContainer(ctx context.Context, opts RunContainerOptions, out io.Writer) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "dockerCli-RunContainer")
+	span.SetTag("image", opts.Image)
+	defer span.Finish()
+
+	created, err := c.ContainerCreate(ctx,
+		&typescontainer.Config{
+			Image: opts.Image,
+			Cmd:   opts.Cmd.Argv,
+			User:  opts.User,
+			Env:   opts.Env,
+		},
+		&typescontainer.HostConfig{Binds: opts.Binds},
+		nil, "")
+	if err != nil {
+		return errors.Wrap(err, "RunContainer#create")
+	}
+
+	id := created.ID
+	defer func() {
+		// Use a fresh context, so that we clean up even if the run was canceled.
+		err := c.ContainerRemove(context.Background(), id, types.ContainerRemoveOptions{Force: true})
+		if err != nil {
+			logger.Get(ctx).Debugf("Error removing container %s: %v", id, err)
+		}
+	}()
+
+	if opts.Content != nil {
+		err = c.CopyToContainer(ctx, id, "/", opts.Content, types.CopyToContainerOptions{})
+		if err != nil {
+			return errors.Wrap(err, "RunContainer#copy")
+		}
+	}
+
+	connection, err := c.ContainerAttach(ctx, id, types.ContainerAttachOptions{
+		Stream: true,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return errors.Wrap(err, "RunContainer#attach")
+	}
+	defer connection.Close()
+
+	err = c.ContainerStart(ctx, id, types.ContainerStartOptions{})
+	if err != nil {
+		return errors.Wrap(err, "RunContainer#start")
+	}
+
+	_, err = stdcopy.StdCopy(out, out, connection.Reader)
+	if err != nil {
+		return errors.Wrap(err, "RunContainer#copyOutput")
+	}
+
+	statusCh, errCh := c.ContainerWait(ctx, id, typescontainer.WaitConditionNotRunning)
+	select {
+	case status := <-statusCh:
+		if status.StatusCode != 0 {
+			return ExitError{ExitCode: int(status.StatusCode)}
+		}
+		return nil
+	case err := <-errCh:
+		return errors.Wrap(err, "RunContainer#wait")
+	}
+}
+
+// Waits for an exec to finish, and returns an ExitError if it failed.
+func (c *Cli) waitForExec(ctx context.Context, execID string) error {
 	for {
-		inspected, err := c.ContainerExecInspect(ctx, execId.ID)
+		inspected, err := c.ContainerExecInspect(ctx, execID)
 		if err != nil {
 			return errors.Wrap(err, "ExecInContainer#inspect")
 		}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -64,6 +66,14 @@ var DefaultContainerListOutput = map[string][]types.Container{
 type ExecCall struct {
 	Container string
 	Cmd       model.Cmd
+	Input     string
+}
+
+type RunCall struct {
+	Options RunContainerOptions
+
+	// The tarball that we copied into the container before running it.
+	Content []byte
 }
 
 type FakeClient struct {
@@ -92,8 +102,14 @@ type FakeClient struct {
 	ExecCalls         []ExecCall
 	ExecErrorsToThrow []error // next call to exec will throw ExecError[0] (which we then pop)
 
+	RunCalls        []RunCall
+	RunOutput       string
+	RunErrorToThrow error // next call to RunContainer will throw this err (after which we clear the error)
+
 	RestartsByContainer map[string]int
 	RemovedImageIDs     []string
+	PulledImages        []string
+	SavedImages         []string
 
 	Images map[string]types.ImageInspect
 }
@@ -171,6 +187,17 @@ func (c *FakeClient) ExecInContainer(ctx context.Context, cID container.ID, cmd
 	return err
 }
 
+func (c *FakeClient) ExecInContainerWithInput(ctx context.Context, cID container.ID, cmd model.Cmd, in io.Reader, out io.Writer) error {
+	input, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	err = c.ExecInContainer(ctx, cID, cmd, out)
+	c.ExecCalls[len(c.ExecCalls)-1].Input = string(input)
+	return err
+}
+
 func (c *FakeClient) CopyToContainerRoot(ctx context.Context, container string, content io.Reader) error {
 	c.CopyCount++
 	c.CopyContainer = container
@@ -185,6 +212,11 @@ func (c *FakeClient) ImagePush(ctx context.Context, image string, options types.
 	return NewFakeDockerResponse(c.PushOutput), nil
 }
 
+func (c *FakeClient) ImagePull(ctx context.Context, image string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	c.PulledImages = append(c.PulledImages, image)
+	return NewFakeDockerResponse(""), nil
+}
+
 func (c *FakeClient) ImageBuild(ctx context.Context, buildContext io.Reader, options BuildOptions) (types.ImageBuildResponse, error) {
 	c.BuildCount++
 	c.BuildOptions = options
@@ -231,6 +263,28 @@ func (c *FakeClient) ImageRemove(ctx context.Context, imageID string, options ty
 	return nil, nil
 }
 
+func (c *FakeClient) ImageSave(ctx context.Context, images []string) (io.ReadCloser, error) {
+	c.SavedImages = append(c.SavedImages, images...)
+	return NewFakeDockerResponse(fmt.Sprintf("saved %s", strings.Join(images, ","))), nil
+}
+func (c *FakeClient) RunContainer(ctx context.Context, opts RunContainerOptions, out io.Writer) error {
+	call := RunCall{Options: opts}
+	if opts.Content != nil {
+		content, err := ioutil.ReadAll(opts.Content)
+		if err != nil {
+			return err
+		}
+		call.Content = content
+	}
+	c.RunCalls = append(c.RunCalls, call)
+
+	_, _ = out.Write([]byte(c.RunOutput))
+
+	err := c.RunErrorToThrow
+	c.RunErrorToThrow = nil
+	return err
+}
+
 var _ Client = &FakeClient{}
 
 type fakeDockerResponse struct {
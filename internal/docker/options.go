@@ -8,4 +8,16 @@ type BuildOptions struct {
 	Remove     bool
 	BuildArgs  map[string]*string
 	Tags       []string
+
+	// The stage of a multi-stage Dockerfile to stop at.
+	Target string
+
+	// SSH agents and secrets to expose to the build, in the same format
+	// as `docker build --ssh` and `docker build --secret`. Only BuildKit
+	// supports these.
+	SSHSpecs    []string
+	SecretSpecs []string
+
+	// Images to use as cache sources.
+	CacheFrom []string
 }
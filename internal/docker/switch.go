@@ -64,9 +64,15 @@ func (c *switchCli) CopyToContainerRoot(ctx context.Context, container string, c
 func (c *switchCli) ExecInContainer(ctx context.Context, cID container.ID, cmd model.Cmd, out io.Writer) error {
 	return c.client().ExecInContainer(ctx, cID, cmd, out)
 }
+func (c *switchCli) ExecInContainerWithInput(ctx context.Context, cID container.ID, cmd model.Cmd, in io.Reader, out io.Writer) error {
+	return c.client().ExecInContainerWithInput(ctx, cID, cmd, in, out)
+}
 func (c *switchCli) ImagePush(ctx context.Context, image string, options types.ImagePushOptions) (io.ReadCloser, error) {
 	return c.client().ImagePush(ctx, image, options)
 }
+func (c *switchCli) ImagePull(ctx context.Context, image string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	return c.client().ImagePull(ctx, image, options)
+}
 func (c *switchCli) ImageBuild(ctx context.Context, buildContext io.Reader, options BuildOptions) (types.ImageBuildResponse, error) {
 	return c.client().ImageBuild(ctx, buildContext, options)
 }
@@ -82,5 +88,11 @@ func (c *switchCli) ImageList(ctx context.Context, options types.ImageListOption
 func (c *switchCli) ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
 	return c.client().ImageRemove(ctx, imageID, options)
 }
+func (c *switchCli) ImageSave(ctx context.Context, images []string) (io.ReadCloser, error) {
+	return c.client().ImageSave(ctx, images)
+}
+func (c *switchCli) RunContainer(ctx context.Context, opts RunContainerOptions, out io.Writer) error {
+	return c.client().RunContainer(ctx, opts, out)
+}
 
 var _ Client = &switchCli{}
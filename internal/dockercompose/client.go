@@ -21,6 +21,7 @@ import (
 type DockerComposeClient interface {
 	Up(ctx context.Context, configPaths []string, serviceName model.TargetName, shouldBuild bool, stdout, stderr io.Writer) error
 	Down(ctx context.Context, configPaths []string, stdout, stderr io.Writer) error
+	Rm(ctx context.Context, configPaths []string, serviceName model.TargetName, stdout, stderr io.Writer) error
 	StreamLogs(ctx context.Context, configPaths []string, serviceName model.TargetName) (io.ReadCloser, error)
 	StreamEvents(ctx context.Context, configPaths []string) (<-chan string, error)
 	Config(ctx context.Context, configPaths []string) (string, error)
@@ -92,6 +93,29 @@ func (c *cmdDCClient) Down(ctx context.Context, configPaths []string, stdout, st
 	return nil
 }
 
+// Stops and removes the container of a single service, leaving the rest of the project alone.
+func (c *cmdDCClient) Rm(ctx context.Context, configPaths []string, serviceName model.TargetName, stdout, stderr io.Writer) error {
+	var args []string
+	if logger.Get(ctx).Level() >= logger.VerboseLvl {
+		args = []string{"--verbose"}
+	}
+	for _, config := range configPaths {
+		args = append(args, "-f", config)
+	}
+
+	args = append(args, "rm", "--stop", "--force", serviceName.String())
+	cmd := c.dcCommand(ctx, args)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return FormatError(cmd, nil, err)
+	}
+
+	return nil
+}
+
 func (c *cmdDCClient) StreamLogs(ctx context.Context, configPaths []string, serviceName model.TargetName) (io.ReadCloser, error) {
 	// TODO(maia): --since time
 	// (may need to implement with `docker log <cID>` instead since `d-c log` doesn't support `--since`
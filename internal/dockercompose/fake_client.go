@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sync"
 	"testing"
 
 	"github.com/windmilleng/tilt/internal/container"
@@ -23,6 +24,9 @@ type FakeDCClient struct {
 
 	UpCalls   []UpCall
 	DownError error
+
+	rmCallsMu sync.Mutex
+	rmCalls   []RmCall
 }
 
 // Represents a single call to Up
@@ -32,6 +36,12 @@ type UpCall struct {
 	ShouldBuild  bool
 }
 
+// Represents a single call to Rm
+type RmCall struct {
+	PathToConfig []string
+	ServiceName  model.TargetName
+}
+
 func NewFakeDockerComposeClient(t *testing.T, ctx context.Context) *FakeDCClient {
 	return &FakeDCClient{
 		t:            t,
@@ -56,6 +66,22 @@ func (c *FakeDCClient) Down(ctx context.Context, configPaths []string, stdout, s
 	return nil
 }
 
+func (c *FakeDCClient) Rm(ctx context.Context, configPaths []string, serviceName model.TargetName, stdout, stderr io.Writer) error {
+	c.rmCallsMu.Lock()
+	c.rmCalls = append(c.rmCalls, RmCall{configPaths, serviceName})
+	c.rmCallsMu.Unlock()
+
+	_, _ = fmt.Fprintf(stdout, "Removing %s ... done\n", serviceName)
+	return nil
+}
+
+// The calls to Rm so far. Rm may be called from another goroutine.
+func (c *FakeDCClient) RmCalls() []RmCall {
+	c.rmCallsMu.Lock()
+	defer c.rmCallsMu.Unlock()
+	return append([]RmCall{}, c.rmCalls...)
+}
+
 func (c *FakeDCClient) StreamLogs(ctx context.Context, configPaths []string, serviceName model.TargetName) (io.ReadCloser, error) {
 	output := c.RunLogOutput[serviceName]
 	reader, writer := io.Pipe()
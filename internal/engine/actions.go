@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/windmilleng/wmclient/pkg/analytics"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
@@ -40,6 +41,16 @@ func NewServiceChangeAction(service *v1.Service, url *url.URL) ServiceChangeActi
 	return ServiceChangeAction{Service: service, URL: url}
 }
 
+type JobChangeAction struct {
+	Job *batchv1.Job
+}
+
+func (JobChangeAction) Action() {}
+
+func NewJobChangeAction(job *batchv1.Job) JobChangeAction {
+	return JobChangeAction{Job: job}
+}
+
 type BuildLogAction struct {
 	store.LogEvent
 }
@@ -79,16 +90,28 @@ func NewDeployIDActionsForTargets(ids []model.TargetID, dID model.DeployID) []De
 }
 
 type BuildCompleteAction struct {
-	Result store.BuildResultSet
-	Error  error
+	ManifestName model.ManifestName
+	Result       store.BuildResultSet
+	Error        error
+
+	// True if the build was canceled because newer changes came in.
+	Superseded bool
 }
 
 func (BuildCompleteAction) Action() {}
 
-func NewBuildCompleteAction(result store.BuildResultSet, err error) BuildCompleteAction {
+func NewBuildCompleteAction(mn model.ManifestName, result store.BuildResultSet, err error) BuildCompleteAction {
+	return BuildCompleteAction{
+		ManifestName: mn,
+		Result:       result,
+		Error:        err,
+	}
+}
+
+func NewBuildSupersededAction(mn model.ManifestName) BuildCompleteAction {
 	return BuildCompleteAction{
-		Result: result,
-		Error:  err,
+		ManifestName: mn,
+		Superseded:   true,
 	}
 }
 
@@ -97,6 +120,7 @@ type InitAction struct {
 	TiltfilePath  string
 	ConfigFiles   []string
 	InitManifests []model.ManifestName
+	ConfigArgs    []string
 
 	TiltBuild model.TiltBuild
 	StartTime time.Time
@@ -104,6 +128,18 @@ type InitAction struct {
 	EnableSail bool
 
 	AnalyticsOpt analytics.Opt
+
+	// If non-zero, overrides the Tiltfile's max_parallel_updates.
+	MaxParallelUpdates int
+
+	// If true, certain k8s changes wait for the user's approval.
+	ConfirmChanges bool
+
+	EngineMode store.EngineMode
+	CITimeout  time.Duration
+
+	// Restored from the previous Tilt session.
+	History store.History
 }
 
 func (InitAction) Action() {}
@@ -152,6 +188,8 @@ type ConfigsReloadedAction struct {
 	Warnings   []string
 	Features   map[string]bool
 	TeamName   string
+
+	UpdateSettings model.UpdateSettings
 }
 
 func (ConfigsReloadedAction) Action() {}
@@ -168,6 +206,22 @@ type DockerComposeLogAction struct {
 
 func (DockerComposeLogAction) Action() {}
 
+type LocalServeStatusAction struct {
+	ManifestName model.ManifestName
+	Status       store.LocalServeStatus
+	PID          int
+	StartTime    time.Time
+}
+
+func (LocalServeStatusAction) Action() {}
+
+type LocalServeLogAction struct {
+	store.LogEvent
+	PID int
+}
+
+func (LocalServeLogAction) Action() {}
+
 type TiltfileLogAction struct {
 	store.LogEvent
 }
@@ -188,3 +242,46 @@ type UIDUpdateAction struct {
 }
 
 func (UIDUpdateAction) Action() {}
+
+// Dispatched when a resource with a restart policy crashed,
+// and it's time to redeploy it (or give up).
+type CrashRestartAction struct {
+	ManifestName model.ManifestName
+
+	// The build that deployed the crashing resource. If there's
+	// been a build since, the crash is out of date.
+	LastBuildStartTime time.Time
+}
+
+func (CrashRestartAction) Action() {}
+
+// Dispatched when a build holds back k8s changes that need the user's approval.
+type K8sChangesPendingAction struct {
+	ManifestName model.ManifestName
+	Changes      []k8s.EntityDiff
+}
+
+func (K8sChangesPendingAction) Action() {}
+
+func NewK8sChangesPendingAction(mn model.ManifestName, changes []k8s.EntityDiff) K8sChangesPendingAction {
+	return K8sChangesPendingAction{ManifestName: mn, Changes: changes}
+}
+
+// Dispatched when a port-forward connects, breaks, or gives up reconnecting.
+type PortForwardStatusAction struct {
+	ManifestName model.ManifestName
+	PodID        k8s.PodID
+	Forward      model.PortForward
+	Status       store.PortForwardStatus
+	Error        error
+}
+
+func (PortForwardStatusAction) Action() {}
+
+// Dispatched when we close the port-forwards to a pod (e.g., because it stopped running).
+type PortForwardsStoppedAction struct {
+	ManifestName model.ManifestName
+	PodID        k8s.PodID
+}
+
+func (PortForwardsStoppedAction) Action() {}
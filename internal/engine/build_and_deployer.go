@@ -68,6 +68,12 @@ func (composite *CompositeBuildAndDeployer) BuildAndDeploy(ctx context.Context,
 			return br, err
 		}
 
+		// If the build was canceled (e.g., because it was superseded by newer changes),
+		// the error is just fallout from the cancellation. Don't try the other builders.
+		if ctx.Err() != nil {
+			return br, err
+		}
+
 		if redirectErr, ok := err.(RedirectToNextBuilder); ok {
 			s := fmt.Sprintf("falling back to next update method because: %v\n", err)
 			logger.Get(ctx).Write(redirectErr.level, s)
@@ -88,16 +94,18 @@ func (composite *CompositeBuildAndDeployer) BuildAndDeploy(ctx context.Context,
 }
 
 func DefaultBuildOrder(lubad *LiveUpdateBuildAndDeployer, ibad *ImageBuildAndDeployer, dcbad *DockerComposeBuildAndDeployer,
-	updMode UpdateMode, env k8s.Env, runtime container.Runtime) BuildOrder {
+	ltbad *LocalTargetBuildAndDeployer, updMode UpdateMode, env k8s.Env, runtime container.Runtime) BuildOrder {
 	if updMode == UpdateModeImage || updMode == UpdateModeNaive {
-		return BuildOrder{dcbad, ibad}
+		return BuildOrder{ltbad, dcbad, ibad}
 	}
 
 	if updMode == UpdateModeSynclet || shouldUseSynclet(updMode, env, runtime) {
 		ibad.SetInjectSynclet(true)
 	}
 
-	return BuildOrder{lubad, dcbad, ibad}
+	// LocalTargets can only be handled by the LocalTargetBuildAndDeployer,
+	// so try it first; it passes on everything else silently.
+	return BuildOrder{ltbad, lubad, dcbad, ibad}
 }
 
 func shouldUseSynclet(updMode UpdateMode, env k8s.Env, runtime container.Runtime) bool {
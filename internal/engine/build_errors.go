@@ -53,6 +53,24 @@ func IsDontFallBackError(err error) bool {
 
 var _ error = DontFallBackError{}
 
+// The build is holding back k8s changes until the user approves them
+// (with --confirm-changes). Nothing is wrong: the resource waits until the
+// user approves the changes, which kicks off another build.
+type AwaitingApprovalError struct {
+	error
+}
+
+func AwaitingApprovalErrorf(msg string, a ...interface{}) AwaitingApprovalError {
+	return AwaitingApprovalError{fmt.Errorf(msg, a...)}
+}
+
+func IsAwaitingApprovalError(err error) bool {
+	_, ok := errors.Cause(err).(AwaitingApprovalError)
+	return ok
+}
+
+var _ error = AwaitingApprovalError{}
+
 // A permanent error indicates that the whole build pipeline needs to stop.
 // It will never recover, even on subsequent rebuilds.
 func isPermanentError(err error) bool {
@@ -66,7 +84,7 @@ func shouldFallBackForErr(err error) bool {
 	}
 
 	cause := errors.Cause(err)
-	if IsDontFallBackError(cause) {
+	if IsDontFallBackError(cause) || IsAwaitingApprovalError(cause) {
 		return false
 	}
 	return true
@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/windmilleng/tilt/internal/store"
@@ -11,9 +12,25 @@ import (
 )
 
 type BuildController struct {
-	b                  BuildAndDeployer
-	lastActionCount    int
+	b BuildAndDeployer
+
+	// The number of builds we've started. We don't start another build
+	// until the engine state has caught up, so that we don't accidentally
+	// start the same build twice.
+	startedBuildCount  int
 	disabledForTesting bool
+
+	// Builds that are currently running, so that we can cancel them
+	// when newer changes come in.
+	mu           sync.Mutex
+	activeBuilds map[model.ManifestName]*activeBuild
+}
+
+type activeBuild struct {
+	startTime  time.Time
+	cancel     func()
+	superseded bool
+	disabled   bool
 }
 
 type buildEntry struct {
@@ -27,8 +44,8 @@ type buildEntry struct {
 
 func NewBuildController(b BuildAndDeployer) *BuildController {
 	return &BuildController{
-		b:               b,
-		lastActionCount: -1,
+		b:            b,
+		activeBuilds: make(map[model.ManifestName]*activeBuild),
 	}
 }
 
@@ -40,18 +57,35 @@ func nextTargetToBuild(state store.EngineState) *store.ManifestTarget {
 		return nil
 	}
 
+	// Don't start any more builds if we're already building as many as we're allowed.
+	if len(state.CurrentlyBuilding) >= state.MaxParallelUpdates() {
+		return nil
+	}
+
 	// unresourced YAML goes first
 	targets := append([]*store.ManifestTarget{}, state.Targets()...)
 	findUnresourcedYAMLAndPutItFirst(targets)
 
+	// The unresourced YAML might include namespaces or CRDs that everything else needs,
+	// so don't build anything alongside its first build.
+	if len(targets) > 0 && targets[0].Manifest.Name == model.UnresourcedYAMLManifestName {
+		yaml := targets[0]
+		if state.CurrentlyBuilding[yaml.Manifest.Name] && len(yaml.State.BuildHistory) == 0 {
+			return nil
+		}
+	}
+
+	targets = removeUnbuildableTargets(state, targets)
+
 	// put no-build manifests next since they're more likely to be
 	// 1. fast and 2. dependencies of other services (e.g., redis)
 	sort.Sort(newNoBuildsManifestsFirst(targets))
 
 	// First, go through all the manifests in order.
-	// If any of them haven't started yet, build them now.
+	// If any of them haven't started yet, build them now,
+	// unless they're still waiting on their resource_deps.
 	for _, mt := range targets {
-		if !mt.State.StartedFirstBuild() {
+		if !mt.State.StartedFirstBuild() && !isWaitingOnDependencies(state, mt) {
 			return mt
 		}
 	}
@@ -71,15 +105,19 @@ func nextTargetToBuild(state store.EngineState) *store.ManifestTarget {
 		}
 	}
 
-	if len(state.TriggerQueue) > 0 {
-		mn := state.TriggerQueue[0]
-		mt, ok := state.ManifestTargets[mn]
-		if ok {
-			return mt
+	for _, mn := range state.TriggerQueue {
+		for _, mt := range targets {
+			if mt.Manifest.Name == mn && !isWaitingOnDependencies(state, mt) {
+				return mt
+			}
 		}
 	}
 
 	for _, mt := range targets {
+		if isWaitingOnDependencies(state, mt) {
+			continue
+		}
+
 		ok, newTime := mt.State.HasPendingChangesBefore(earliest)
 		if ok {
 			if mt.Manifest.TriggerMode == model.TriggerModeManual {
@@ -95,6 +133,52 @@ func nextTargetToBuild(state store.EngineState) *store.ManifestTarget {
 	return choice
 }
 
+// Resource dependencies only hold back the first build of a manifest.
+// After that, we rebuild on changes as usual, even if a dependency is unhealthy.
+func isWaitingOnDependencies(state store.EngineState, mt *store.ManifestTarget) bool {
+	if mt.State.StartedFirstBuild() {
+		return false
+	}
+	return len(state.UnreadyDependencies(mt.Manifest)) > 0
+}
+
+// Filter out the targets that we can't build right now: the disabled ones, the ones
+// that are already building, and the ones that share an image with a manifest that's
+// building (so that we never build the same image twice at once).
+func removeUnbuildableTargets(state store.EngineState, targets []*store.ManifestTarget) []*store.ManifestTarget {
+	buildingImages := make(map[model.TargetID]bool)
+	for mn := range state.CurrentlyBuilding {
+		mt, ok := state.ManifestTargets[mn]
+		if !ok {
+			continue
+		}
+		for _, iTarget := range mt.Manifest.ImageTargets {
+			buildingImages[iTarget.ID()] = true
+		}
+	}
+
+	result := make([]*store.ManifestTarget, 0, len(targets))
+	for _, mt := range targets {
+		if mt.State.Disabled || state.CurrentlyBuilding[mt.Manifest.Name] {
+			continue
+		}
+
+		sharesImage := false
+		for _, iTarget := range mt.Manifest.ImageTargets {
+			if buildingImages[iTarget.ID()] {
+				sharesImage = true
+				break
+			}
+		}
+		if sharesImage {
+			continue
+		}
+
+		result = append(result, mt)
+	}
+	return result
+}
+
 func findUnresourcedYAMLAndPutItFirst(input []*store.ManifestTarget) {
 	for i := range input {
 		if input[i].Manifest.ManifestName() == model.UnresourcedYAMLManifestName {
@@ -162,9 +246,9 @@ func (c *BuildController) needsBuild(ctx context.Context, st store.RStore) (buil
 	state := st.RLockState()
 	defer st.RUnlockState()
 
-	// Don't start the next build until the previous action has been recorded,
+	// Don't start the next build until the previous one has been recorded,
 	// so that we don't accidentally repeat the same build.
-	if c.lastActionCount == state.BuildControllerActionCount {
+	if c.startedBuildCount != state.StartedBuildCount {
 		return buildEntry{}, false
 	}
 
@@ -173,7 +257,7 @@ func (c *BuildController) needsBuild(ctx context.Context, st store.RStore) (buil
 		return buildEntry{}, false
 	}
 
-	c.lastActionCount = state.BuildControllerActionCount
+	c.startedBuildCount++
 	ms := mt.State
 	manifest := mt.Manifest
 	firstBuild := !ms.StartedFirstBuild()
@@ -196,33 +280,120 @@ func (c *BuildController) DisableForTesting() {
 	c.disabledForTesting = true
 }
 
+// Cancel any running builds whose inputs have changed since they started.
+// The newer changes are still pending, so we'll rebuild as soon as the
+// superseded build finishes unwinding.
+//
+// Also cancel the builds of resources that were disabled while building.
+func (c *BuildController) cancelSupersededBuilds(st store.RStore) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for mn, b := range c.activeBuilds {
+		if b.superseded {
+			continue
+		}
+
+		mt, ok := state.ManifestTargets[mn]
+		if !ok {
+			continue
+		}
+
+		if mt.State.Disabled {
+			b.disabled = true
+		} else if !hasFileChangesSince(mt, b.startTime) {
+			continue
+		}
+
+		b.superseded = true
+		b.cancel()
+	}
+}
+
+// Whether any of the manifest's files changed after the given time.
+//
+// Changes to manual manifests don't trigger a build on their own,
+// so they never supersede the running one.
+func hasFileChangesSince(mt *store.ManifestTarget, t time.Time) bool {
+	if mt.Manifest.TriggerMode != model.TriggerModeAuto {
+		return false
+	}
+
+	for _, status := range mt.State.BuildStatuses {
+		for _, modTime := range status.PendingFileChanges {
+			if modTime.After(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (c *BuildController) OnChange(ctx context.Context, st store.RStore) {
 	if c.disabledForTesting {
 		return
 	}
+
+	c.cancelSupersededBuilds(st)
+
 	entry, ok := c.needsBuild(ctx, st)
 	if !ok {
 		return
 	}
 
+	// Dispatch this before we kick off the build, so that it's
+	// ordered before any of the logs from the build.
+	startTime := time.Now()
+	st.Dispatch(BuildStartedAction{
+		ManifestName: entry.name,
+		StartTime:    startTime,
+		FilesChanged: entry.filesChanged,
+		Reason:       entry.buildReason,
+	})
+
+	ctx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.activeBuilds[entry.name] = &activeBuild{
+		startTime: startTime,
+		cancel:    cancel,
+	}
+	c.mu.Unlock()
+
 	go func() {
+		defer cancel()
+
 		// Send the logs to both the EngineState and the normal log stream.
+		// Each build gets its own writer, so that the output of parallel
+		// builds ends up in the right manifest's log.
 		actionWriter := BuildLogActionWriter{
 			store:        st,
 			manifestName: entry.name,
 		}
 		ctx := logger.WithLogger(ctx, logger.NewLogger(logger.Get(ctx).Level(), actionWriter))
 
-		st.Dispatch(BuildStartedAction{
-			ManifestName: entry.name,
-			StartTime:    time.Now(),
-			FilesChanged: entry.filesChanged,
-			Reason:       entry.buildReason,
-		})
 		c.logBuildEntry(ctx, entry)
 
 		result, err := c.buildAndDeploy(ctx, st, entry)
-		st.Dispatch(NewBuildCompleteAction(result, err))
+
+		c.mu.Lock()
+		ab := c.activeBuilds[entry.name]
+		delete(c.activeBuilds, entry.name)
+		c.mu.Unlock()
+
+		if ab.disabled {
+			logger.Get(ctx).Infof("Build canceled: resource disabled")
+			st.Dispatch(NewBuildSupersededAction(entry.name))
+			return
+		} else if ab.superseded {
+			logger.Get(ctx).Infof("Build canceled: newer changes detected")
+			st.Dispatch(NewBuildSupersededAction(entry.name))
+			return
+		}
+
+		st.Dispatch(NewBuildCompleteAction(entry.name, result, err))
 	}()
 }
 
@@ -283,6 +454,8 @@ func buildTargets(manifest model.Manifest) []model.TargetSpec {
 		result = append(result, manifest.DockerComposeTarget())
 	} else if manifest.IsK8s() {
 		result = append(result, manifest.K8sTarget())
+	} else if manifest.IsLocal() {
+		result = append(result, manifest.LocalTarget())
 	}
 
 	return result
@@ -294,7 +467,7 @@ func buildStateSet(ctx context.Context, manifest model.Manifest, specs []model.T
 
 	for _, spec := range specs {
 		id := spec.ID()
-		if id.Type != model.TargetTypeImage && id.Type != model.TargetTypeDockerCompose {
+		if id.Type != model.TargetTypeImage && id.Type != model.TargetTypeDockerCompose && id.Type != model.TargetTypeLocal {
 			continue
 		}
 
@@ -305,7 +478,11 @@ func buildStateSet(ctx context.Context, manifest model.Manifest, specs []model.T
 		}
 		sort.Strings(filesChanged)
 
-		buildState := store.NewBuildState(status.LastSuccessfulResult, filesChanged)
+		lastResult := status.LastSuccessfulResult
+		if status.RestoredFromHistory {
+			lastResult = restoredResultIfUnchanged(ctx, spec, status)
+		}
+		buildState := store.NewBuildState(lastResult, filesChanged)
 
 		// Pass along the container when we can update containers in-place.
 		//
@@ -11,9 +11,11 @@ import (
 	v1 "k8s.io/api/core/v1"
 
 	"github.com/windmilleng/tilt/internal/hud/server"
+	"github.com/windmilleng/tilt/internal/hud/view"
 	"github.com/windmilleng/tilt/internal/k8s/testyaml"
 	"github.com/windmilleng/tilt/internal/testutils/manifestbuilder"
 	"github.com/windmilleng/tilt/internal/testutils/podbuilder"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
 
 	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/store"
@@ -652,3 +654,304 @@ func TestBuildControllerUnresourcedYAMLFirst(t *testing.T) {
 	}
 	assert.Equal(t, expectedBuildOrder, observedBuildOrder)
 }
+
+func TestBuildControllerResourceDeps(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	db := f.newManifest("db")
+	api := f.newManifest("api").WithResourceDependencies([]model.ManifestName{"db"})
+	f.Start([]model.Manifest{api, db}, true)
+
+	call := f.nextCall()
+	assert.Equal(t, "db", call.k8s().Name.String())
+
+	call = f.nextCall()
+	assert.Equal(t, "api", call.k8s().Name.String())
+}
+
+func TestBuildControllerResourceDepsWaitsForReadyPod(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	db := f.newManifest("db")
+	kTarget := db.K8sTarget()
+	kTarget.HasPodTemplates = true
+	db = db.WithDeployTarget(kTarget)
+
+	api := f.newManifest("api").WithResourceDependencies([]model.ManifestName{"db"})
+	f.Start([]model.Manifest{api, db}, true)
+
+	call := f.nextCallComplete()
+	assert.Equal(t, "db", call.k8s().Name.String())
+	f.assertNoCall("api should wait until db has a ready pod")
+
+	f.withState(func(state store.EngineState) {
+		view := store.StateToView(state)
+		r, ok := view.Resource("api")
+		require.True(t, ok)
+		assert.Equal(t, []model.ManifestName{"db"}, r.WaitingOn)
+	})
+
+	f.podEvent(podbuilder.New(t, db).Build())
+
+	call = f.nextCall()
+	assert.Equal(t, "api", call.k8s().Name.String())
+}
+
+func TestBuildControllerResourceDepsWaitsForReadinessProbe(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	db := f.newManifest("db")
+	kTarget := db.K8sTarget()
+	kTarget.HasPodTemplates = true
+	db = db.WithDeployTarget(kTarget)
+
+	api := f.newManifest("api").WithResourceDependencies([]model.ManifestName{"db"})
+	f.Start([]model.Manifest{api, db}, true)
+
+	call := f.nextCallComplete()
+	assert.Equal(t, "db", call.k8s().Name.String())
+
+	f.podEvent(podbuilder.New(t, db).WithNotReady().Build())
+	f.WaitUntilHUDResource("db pending readiness", "db", func(res view.Resource) bool {
+		return res.K8sInfo().PodStatus == store.PodStatusPendingReadiness
+	})
+	f.assertNoCall("api should wait until db passes its readiness probe")
+
+	f.podEvent(podbuilder.New(t, db).Build())
+	f.WaitUntilHUDResource("db ready", "db", func(res view.Resource) bool {
+		return res.K8sInfo().PodStatus == "Running"
+	})
+
+	call = f.nextCall()
+	assert.Equal(t, "api", call.k8s().Name.String())
+}
+
+func TestBuildControllerResourceDepsNotLoaded(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	// If the dependency isn't running in this session at all, don't wait on it.
+	api := f.newManifest("api").WithResourceDependencies([]model.ManifestName{"db"})
+	f.Start([]model.Manifest{api}, true)
+
+	call := f.nextCall()
+	assert.Equal(t, "api", call.k8s().Name.String())
+}
+
+func TestBuildControllerParallelBuilds(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	manifests := []model.Manifest{
+		f.newManifestWithRef("frontend", container.MustParseNamed("gcr.io/frontend")),
+		f.newManifestWithRef("backend", container.MustParseNamed("gcr.io/backend")),
+		f.newManifestWithRef("database", container.MustParseNamed("gcr.io/database")),
+	}
+	f.Start(manifests, true, withMaxParallelUpdates(3))
+
+	for range manifests {
+		f.nextCall()
+	}
+	f.waitForCompletedBuildCount(len(manifests))
+
+	// Each build's output should end up in its own manifest's log.
+	f.withState(func(state store.EngineState) {
+		assert.Equal(t, 0, len(state.CurrentlyBuilding))
+		for _, m := range manifests {
+			log := state.ManifestTargets[m.Name].State.LastBuild().Log.String()
+			for _, other := range manifests {
+				if other.Name == m.Name {
+					assert.Contains(t, log, other.Name.String())
+				} else {
+					assert.NotContains(t, log, other.Name.String())
+				}
+			}
+		}
+	})
+}
+
+func TestNextTargetToBuildMaxParallelUpdates(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	state := store.NewState()
+	for _, name := range []string{"a", "b", "c"} {
+		upsertManifestWithImage(f, state, name, "gcr.io/"+name)
+	}
+
+	markBuilding(state, "a")
+	assert.Equal(t, model.ManifestName(""), nextManifestNameToBuild(*state),
+		"should only build one manifest at a time by default")
+
+	state.UpdateSettings.MaxParallelUpdates = 2
+	assert.Equal(t, model.ManifestName("b"), nextManifestNameToBuild(*state))
+
+	markBuilding(state, "b")
+	assert.Equal(t, model.ManifestName(""), nextManifestNameToBuild(*state))
+
+	state.MaxParallelUpdatesOverride = 3
+	assert.Equal(t, model.ManifestName("c"), nextManifestNameToBuild(*state))
+}
+
+func TestNextTargetToBuildSkipsSharedImages(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	state := store.NewState()
+	state.UpdateSettings.MaxParallelUpdates = 3
+	upsertManifestWithImage(f, state, "a", "gcr.io/shared")
+	upsertManifestWithImage(f, state, "b", "gcr.io/shared")
+	upsertManifestWithImage(f, state, "c", "gcr.io/c")
+
+	markBuilding(state, "a")
+	assert.Equal(t, model.ManifestName("c"), nextManifestNameToBuild(*state))
+}
+
+func TestNextTargetToBuildWaitsForUnresourcedYAML(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	state := store.NewState()
+	state.UpdateSettings.MaxParallelUpdates = 3
+	upsertManifestWithImage(f, state, "a", "gcr.io/a")
+	yaml := manifestbuilder.New(f, model.UnresourcedYAMLManifestName).WithK8sYAML("fake-yaml").Build()
+	state.UpsertManifestTarget(store.NewManifestTarget(yaml))
+
+	assert.Equal(t, model.UnresourcedYAMLManifestName, nextManifestNameToBuild(*state))
+
+	markBuilding(state, model.UnresourcedYAMLManifestName)
+	assert.Equal(t, model.ManifestName(""), nextManifestNameToBuild(*state))
+}
+
+func withMaxParallelUpdates(n int) initOption {
+	return func(ia InitAction) InitAction {
+		ia.MaxParallelUpdates = n
+		return ia
+	}
+}
+
+func upsertManifestWithImage(f Fixture, state *store.EngineState, name string, ref string) {
+	iTarget := model.NewImageTarget(container.MustParseSelector(ref)).
+		WithBuildDetails(model.DockerBuild{BuildPath: f.Path()})
+	m := manifestbuilder.New(f, model.ManifestName(name)).
+		WithK8sYAML(SanchoYAML).
+		WithImageTarget(iTarget).
+		Build()
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+}
+
+func markBuilding(state *store.EngineState, name model.ManifestName) {
+	state.ManifestTargets[name].State.CurrentBuild = model.BuildRecord{StartTime: time.Now()}
+	state.CurrentlyBuilding[name] = true
+}
+
+func TestBuildControllerCancelsSupersededBuild(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	manifest := f.newManifest("foobar")
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+
+	f.b.nextBuildWaitsForCancel = true
+	f.fsWatcher.events <- watch.NewFileEvent(f.JoinPath("main.go"))
+	f.WaitUntilManifestState("build started", "foobar", func(ms store.ManifestState) bool {
+		return !ms.CurrentBuild.Empty()
+	})
+
+	f.fsWatcher.events <- watch.NewFileEvent(f.JoinPath("other.go"))
+
+	// The slow build gets canceled...
+	call := f.nextCall()
+	assert.Equal(t, []string{f.JoinPath("main.go")}, call.oneState().FilesChanged())
+
+	// ...and the next build picks up both changes.
+	call = f.nextCall()
+	assert.Equal(t, []string{f.JoinPath("main.go"), f.JoinPath("other.go")}, call.oneState().FilesChanged())
+	f.waitForCompletedBuildCount(3)
+
+	f.withManifestState("foobar", func(ms store.ManifestState) {
+		require.Equal(t, 2, len(ms.BuildHistory))
+		assert.False(t, ms.BuildHistory[0].Superseded)
+		assert.NoError(t, ms.BuildHistory[0].Error)
+
+		superseded := ms.BuildHistory[1]
+		assert.True(t, superseded.Superseded)
+		assert.NoError(t, superseded.Error)
+		assert.Contains(t, superseded.Log.String(), "Build canceled: newer changes detected")
+	})
+}
+
+func TestBuildControllerDoesNotCancelManualBuild(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	mName := model.ManifestName("foobar")
+	manifest := f.newManifest(mName.String()).WithTriggerMode(model.TriggerModeManual)
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+
+	f.fsWatcher.events <- watch.NewFileEvent(f.JoinPath("main.go"))
+	f.WaitUntil("pending change appears", func(st store.EngineState) bool {
+		return len(st.BuildStatus(manifest.ImageTargetAt(0).ID()).PendingFileChanges) > 0
+	})
+
+	f.b.nextBuildWaitsForCancel = true
+	f.store.Dispatch(server.AppendToTriggerQueueAction{Name: mName})
+	f.WaitUntilManifestState("build started", mName, func(ms store.ManifestState) bool {
+		return !ms.CurrentBuild.Empty()
+	})
+
+	f.fsWatcher.events <- watch.NewFileEvent(f.JoinPath("other.go"))
+	f.assertNoCall("changes to a manual manifest shouldn't cancel its build")
+}
+
+func TestBuildControllerDisableCancelsBuild(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	manifest := NewSanchoFastBuildDCManifest(f)
+	mName := manifest.Name
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+
+	f.b.nextBuildWaitsForCancel = true
+	f.fsWatcher.events <- watch.NewFileEvent(f.JoinPath("main.go"))
+	f.WaitUntilManifestState("build started", mName, func(ms store.ManifestState) bool {
+		return !ms.CurrentBuild.Empty()
+	})
+
+	f.store.Dispatch(store.SetResourceEnabledAction{ManifestName: mName, Enabled: false})
+
+	// The in-progress build gets canceled, and its result thrown away.
+	f.nextCall()
+	f.WaitUntilManifestState("build canceled", mName, func(ms store.ManifestState) bool {
+		return ms.Disabled && ms.CurrentBuild.Empty()
+	})
+	f.WaitUntil("container removed", func(st store.EngineState) bool {
+		return len(f.dcc.RmCalls()) == 1
+	})
+	f.withManifestState(mName, func(ms store.ManifestState) {
+		assert.Equal(t, 0, len(ms.BuildHistory))
+	})
+
+	f.fsWatcher.events <- watch.NewFileEvent(f.JoinPath("other.go"))
+	f.assertNoCall("disabled manifests shouldn't build")
+
+	// Re-enabling builds from scratch.
+	f.store.Dispatch(store.SetResourceEnabledAction{ManifestName: mName, Enabled: true})
+	call := f.nextCall()
+	assert.True(t, call.state[manifest.ImageTargetAt(0).ID()].IsEmpty())
+	f.WaitUntilManifestState("build completed", mName, func(ms store.ManifestState) bool {
+		return !ms.Disabled && len(ms.BuildHistory) == 1
+	})
+}
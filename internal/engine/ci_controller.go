@@ -0,0 +1,213 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/hud"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// Pod statuses that we don't expect to recover from on their own.
+var ciFatalPodStatuses = map[string]bool{
+	"CrashLoopBackOff": true,
+	"ErrImagePull":     true,
+	"ImagePullBackOff": true,
+}
+
+// Decides when `tilt ci` is done.
+//
+// Exits successfully once every resource has been built and deployed
+// and is healthy, and exits with an error as soon as anything fails
+// or the timeout expires. Either way, prints a summary of each resource.
+type CIController struct {
+	out io.Writer
+
+	mu     sync.Mutex
+	timer  *time.Timer
+	exited bool
+}
+
+func NewCIController() *CIController {
+	return &CIController{out: os.Stdout}
+}
+
+func (c *CIController) OnChange(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	if state.EngineMode != store.EngineModeCI {
+		st.RUnlockState()
+		return
+	}
+
+	c.maybeStartTimer(st, state.CITimeout)
+
+	done, err := ciStatus(state)
+	var summary string
+	if done {
+		summary = ciSummary(state)
+	}
+	st.RUnlockState()
+
+	if done {
+		c.exit(st, summary, err)
+	}
+}
+
+func (c *CIController) maybeStartTimer(st store.RStore, timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil || timeout <= 0 {
+		return
+	}
+
+	c.timer = time.AfterFunc(timeout, func() {
+		state := st.RLockState()
+		summary := ciSummary(state)
+		st.RUnlockState()
+
+		c.exit(st, summary, fmt.Errorf("Timeout after %s", timeout))
+	})
+}
+
+func (c *CIController) exit(st store.RStore, summary string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.exited {
+		return
+	}
+	c.exited = true
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+
+	_, _ = fmt.Fprint(c.out, summary)
+	st.Dispatch(hud.NewExitAction(err))
+}
+
+func (c *CIController) TearDown(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+}
+
+// Returns true if we're done, with an error if anything failed.
+func ciStatus(state store.EngineState) (bool, error) {
+	tfBuild := state.TiltfileState.LastBuild()
+	if tfBuild.Error != nil {
+		return true, fmt.Errorf("Tiltfile load failed: %v", tfBuild.Error)
+	}
+	if tfBuild.Empty() {
+		return false, nil
+	}
+
+	allReady := true
+	for _, mt := range state.Targets() {
+		// Disabled resources are never deployed, so don't wait on them.
+		if mt.State.Disabled {
+			continue
+		}
+
+		err := ciTargetError(mt)
+		if err != nil {
+			return true, fmt.Errorf("%s: %v", mt.Manifest.Name, err)
+		}
+
+		if !ciTargetReady(mt) {
+			allReady = false
+		}
+	}
+	return allReady, nil
+}
+
+func ciTargetError(mt *store.ManifestTarget) error {
+	ms := mt.State
+	if ms.CurrentBuild.Empty() {
+		if err := ms.LastBuild().Error; err != nil {
+			return fmt.Errorf("build failed: %v", err)
+		}
+	}
+
+	m := mt.Manifest
+	switch {
+	case m.IsK8s() && m.K8sTarget().HasJobs:
+		job := ms.K8sRuntimeState().CurrentJob()
+		if job.Status == store.JobStatusFailed {
+			return fmt.Errorf("job %s failed: %s", job.Name, job.Message)
+		}
+	case m.IsK8s():
+		for _, pod := range ms.K8sRuntimeState().Pods {
+			if ciFatalPodStatuses[pod.Status] {
+				return fmt.Errorf("pod %s is in %s", pod.PodID, pod.Status)
+			}
+		}
+	case m.IsDC():
+		if ms.DCRuntimeState().Status == dockercompose.StatusCrash {
+			return fmt.Errorf("container crashed")
+		}
+	case m.IsLocal():
+		switch ms.LocalRuntimeState().Status {
+		case store.LocalServeStatusCrashed:
+			return fmt.Errorf("serve_cmd crashed")
+		case store.LocalServeStatusExited:
+			return fmt.Errorf("serve_cmd exited")
+		}
+	}
+	return nil
+}
+
+func ciTargetReady(mt *store.ManifestTarget) bool {
+	ms := mt.State
+	if !ms.CurrentBuild.Empty() || ms.LastBuild().Empty() {
+		return false
+	}
+	return mt.IsReadyForDependents()
+}
+
+func ciSummary(state store.EngineState) string {
+	sb := &strings.Builder{}
+	sb.WriteString("\nResource summary:\n")
+
+	w := tabwriter.NewWriter(sb, 0, 8, 2, ' ', 0)
+	for _, mt := range state.Targets() {
+		status := "Pending"
+		detail := ""
+		if mt.State.Disabled {
+			status = "Disabled"
+		} else if err := ciTargetError(mt); err != nil {
+			status = "Error"
+			detail = err.Error()
+		} else if ciTargetReady(mt) {
+			status = "OK"
+		} else if !mt.State.CurrentBuild.Empty() {
+			detail = "building"
+		} else if !mt.State.LastBuild().Empty() {
+			detail = "waiting to become ready"
+		}
+		_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\n", mt.Manifest.Name, status, firstLine(detail))
+	}
+	_ = w.Flush()
+	return sb.String()
+}
+
+func firstLine(s string) string {
+	i := strings.Index(s, "\n")
+	if i == -1 {
+		return s
+	}
+	return s[:i]
+}
+
+var _ store.Subscriber = &CIController{}
+var _ store.TearDowner = &CIController{}
@@ -0,0 +1,247 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/windmilleng/tilt/internal/hud"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestCIExitsWhenAllResourcesReady(t *testing.T) {
+	f := newCICFixture(t, 0)
+	defer f.TearDown()
+
+	f.upsertLocal("migrate")
+	f.upsertLocal("seed")
+	f.loadTiltfile()
+
+	f.onChange()
+	f.assertNoExit()
+
+	f.completeBuild("migrate", nil)
+	f.onChange()
+	f.assertNoExit()
+
+	f.completeBuild("seed", nil)
+	f.onChange()
+	assert.NoError(t, f.waitForExit())
+	assert.Contains(t, f.out.String(), "migrate")
+	assert.Contains(t, f.out.String(), "OK")
+}
+
+func TestCIExitsOnBuildFailure(t *testing.T) {
+	f := newCICFixture(t, 0)
+	defer f.TearDown()
+
+	f.upsertLocal("migrate")
+	f.upsertLocal("seed")
+	f.loadTiltfile()
+
+	f.completeBuild("migrate", fmt.Errorf("exit status 1"))
+	f.onChange()
+
+	err := f.waitForExit()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "migrate: build failed: exit status 1")
+	}
+	assert.Contains(t, f.out.String(), "Error")
+	assert.Contains(t, f.out.String(), "Pending")
+}
+
+func TestCIExitsOnCrashLoopBackOff(t *testing.T) {
+	f := newCICFixture(t, 0)
+	defer f.TearDown()
+
+	f.upsertK8s("fe")
+	f.loadTiltfile()
+	f.completeBuild("fe", nil)
+
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["fe"].State.RuntimeState = store.NewK8sRuntimeState(model.DeployID(0), store.Pod{
+		PodID:  "fe-pod",
+		Phase:  v1.PodRunning,
+		Status: "CrashLoopBackOff",
+	})
+	f.st.UnlockMutableState()
+	f.onChange()
+
+	err := f.waitForExit()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "pod fe-pod is in CrashLoopBackOff")
+	}
+}
+
+func TestCIExitsOnTiltfileError(t *testing.T) {
+	f := newCICFixture(t, 0)
+	defer f.TearDown()
+
+	state := f.st.LockMutableStateForTesting()
+	state.TiltfileState.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Now(),
+		FinishTime: time.Now(),
+		Error:      fmt.Errorf("syntax error"),
+	})
+	f.st.UnlockMutableState()
+	f.onChange()
+
+	err := f.waitForExit()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Tiltfile load failed: syntax error")
+	}
+}
+
+func TestCITimeout(t *testing.T) {
+	f := newCICFixture(t, 50*time.Millisecond)
+	defer f.TearDown()
+
+	f.upsertLocal("migrate")
+	f.loadTiltfile()
+	f.onChange()
+
+	err := f.waitForExit()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Timeout after 50ms")
+	}
+	assert.Contains(t, f.out.String(), "Pending")
+}
+
+func TestCIIgnoredInUpMode(t *testing.T) {
+	f := newCICFixture(t, 0)
+	defer f.TearDown()
+
+	state := f.st.LockMutableStateForTesting()
+	state.EngineMode = store.EngineModeUp
+	f.st.UnlockMutableState()
+
+	f.upsertLocal("migrate")
+	f.loadTiltfile()
+	f.completeBuild("migrate", nil)
+	f.onChange()
+	f.assertNoExit()
+}
+
+type cicFixture struct {
+	t          *testing.T
+	ctx        context.Context
+	cancel     func()
+	st         *store.Store
+	getActions func() []store.Action
+	cic        *CIController
+	out        *bytes.Buffer
+}
+
+func newCICFixture(t *testing.T, timeout time.Duration) *cicFixture {
+	st, getActions := store.NewStoreForTesting()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	state := st.LockMutableStateForTesting()
+	state.EngineMode = store.EngineModeCI
+	state.CITimeout = timeout
+	st.UnlockMutableState()
+
+	go func() {
+		_ = st.Loop(ctx)
+	}()
+
+	out := &bytes.Buffer{}
+	cic := NewCIController()
+	cic.out = out
+
+	return &cicFixture{
+		t:          t,
+		ctx:        ctx,
+		cancel:     cancel,
+		st:         st,
+		getActions: getActions,
+		cic:        cic,
+		out:        out,
+	}
+}
+
+func (f *cicFixture) onChange() {
+	f.cic.OnChange(f.ctx, f.st)
+}
+
+func (f *cicFixture) upsertLocal(name string) {
+	lt := model.NewLocalTarget(model.TargetName(name), model.ToShellCmd("echo hi"), ".", nil)
+	m := model.Manifest{Name: model.ManifestName(name)}.WithDeployTarget(lt)
+	f.upsert(m)
+}
+
+func (f *cicFixture) upsertK8s(name string) {
+	kt := model.K8sTarget{Name: model.TargetName(name), HasPodTemplates: true}
+	m := model.Manifest{Name: model.ManifestName(name)}.WithDeployTarget(kt)
+	f.upsert(m)
+}
+
+func (f *cicFixture) upsert(m model.Manifest) {
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	f.st.UnlockMutableState()
+}
+
+func (f *cicFixture) loadTiltfile() {
+	state := f.st.LockMutableStateForTesting()
+	state.TiltfileState.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Now(),
+		FinishTime: time.Now(),
+	})
+	f.st.UnlockMutableState()
+}
+
+func (f *cicFixture) completeBuild(name string, err error) {
+	state := f.st.LockMutableStateForTesting()
+	ms := state.ManifestTargets[model.ManifestName(name)].State
+	ms.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Now(),
+		FinishTime: time.Now(),
+		Error:      err,
+	})
+	if err == nil {
+		ms.LastSuccessfulDeployTime = time.Now()
+	}
+	f.st.UnlockMutableState()
+}
+
+func (f *cicFixture) exitActions() []hud.ExitAction {
+	var result []hud.ExitAction
+	for _, a := range f.getActions() {
+		if a, ok := a.(hud.ExitAction); ok {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func (f *cicFixture) waitForExit() error {
+	start := time.Now()
+	for time.Since(start) < time.Second {
+		actions := f.exitActions()
+		if len(actions) > 0 {
+			require.Equal(f.t, 1, len(actions))
+			return actions[0].Err
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	f.t.Fatal("timed out waiting for ExitAction")
+	return nil
+}
+
+func (f *cicFixture) assertNoExit() {
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(f.t, 0, len(f.exitActions()))
+}
+
+func (f *cicFixture) TearDown() {
+	f.cic.TearDown(f.ctx)
+	f.cancel()
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/docker"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// Imports an image tarball into the containerd on a cluster node.
+var nodeImageImportCmd = model.Cmd{Argv: []string{"ctr", "--namespace=k8s.io", "images", "import", "-"}}
+
+// Loads images straight into the nodes of a local cluster whose nodes
+// are Docker containers (KIND or k3d), so that we don't need a registry.
+//
+// This does the same thing as `kind load docker-image` and `k3d image import`,
+// without needing those CLIs installed.
+type ClusterImageLoader interface {
+	// Loads an image from the local Docker daemon into every node.
+	LoadImage(ctx context.Context, ref reference.NamedTagged, w io.Writer) error
+
+	// Loads an image tarball (e.g., one exported by BuildKit) into every node.
+	LoadImageArchive(ctx context.Context, path string, w io.Writer) error
+}
+
+type nodeImageLoader struct {
+	dCli docker.Client
+	kCli k8s.Client
+}
+
+func NewClusterImageLoader(dCli docker.LocalClient, kCli k8s.Client) ClusterImageLoader {
+	return &nodeImageLoader{
+		dCli: dCli,
+		kCli: kCli,
+	}
+}
+
+func (l *nodeImageLoader) LoadImage(ctx context.Context, ref reference.NamedTagged, w io.Writer) error {
+	return l.loadIntoNodes(ctx, ref.String(), w, func() (io.ReadCloser, error) {
+		return l.dCli.ImageSave(ctx, []string{ref.String()})
+	})
+}
+
+func (l *nodeImageLoader) LoadImageArchive(ctx context.Context, path string, w io.Writer) error {
+	return l.loadIntoNodes(ctx, path, w, func() (io.ReadCloser, error) {
+		return os.Open(path)
+	})
+}
+
+// Streams a fresh copy of the image tarball into each node.
+func (l *nodeImageLoader) loadIntoNodes(ctx context.Context, name string, w io.Writer,
+	open func() (io.ReadCloser, error)) error {
+	nodes, err := l.kCli.NodeNames(ctx)
+	if err != nil {
+		return errors.Wrap(err, "loading image into cluster")
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("loading image into cluster: no nodes found")
+	}
+
+	for _, node := range nodes {
+		_, _ = fmt.Fprintf(w, "Loading %s into node %s\n", name, node)
+		err := l.loadIntoNode(ctx, node, w, open)
+		if err != nil {
+			return errors.Wrapf(err, "loading image into node %s", node)
+		}
+	}
+	return nil
+}
+
+func (l *nodeImageLoader) loadIntoNode(ctx context.Context, node string, w io.Writer,
+	open func() (io.ReadCloser, error)) error {
+	image, err := open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = image.Close() }()
+
+	return l.dCli.ExecInContainerWithInput(ctx, container.ID(node), nodeImageImportCmd, image, w)
+}
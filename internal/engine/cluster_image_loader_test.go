@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/docker"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestLoadImageIntoEveryNode(t *testing.T) {
+	dCli := docker.NewFakeClient()
+	kCli := k8s.NewFakeK8sClient()
+	kCli.Nodes = []string{"kind-control-plane", "kind-worker"}
+	loader := NewClusterImageLoader(docker.LocalClient(dCli), kCli)
+
+	ref := container.MustParseNamedTagged("gcr.io/foo/bar:tilt-11cd0b38bc3ceb95")
+	out := &bytes.Buffer{}
+	err := loader.LoadImage(context.Background(), ref, out)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{ref.String(), ref.String()}, dCli.SavedImages)
+	if assert.Len(t, dCli.ExecCalls, 2) {
+		for i, node := range kCli.Nodes {
+			call := dCli.ExecCalls[i]
+			assert.Equal(t, node, call.Container)
+			assert.Equal(t, nodeImageImportCmd, call.Cmd)
+			assert.Equal(t, "saved "+ref.String(), call.Input)
+		}
+	}
+	assert.Contains(t, out.String(), "Loading gcr.io/foo/bar:tilt-11cd0b38bc3ceb95 into node kind-worker")
+}
+
+func TestLoadImageArchive(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	path := filepath.Join(f.Path(), "image.tar")
+	require.NoError(t, ioutil.WriteFile(path, []byte("image"), 0644))
+
+	dCli := docker.NewFakeClient()
+	kCli := k8s.NewFakeK8sClient()
+	kCli.Nodes = []string{"k3d-k3s-default-server"}
+	loader := NewClusterImageLoader(docker.LocalClient(dCli), kCli)
+
+	err := loader.LoadImageArchive(context.Background(), path, &bytes.Buffer{})
+	require.NoError(t, err)
+
+	assert.Empty(t, dCli.SavedImages)
+	if assert.Len(t, dCli.ExecCalls, 1) {
+		assert.Equal(t, "k3d-k3s-default-server", dCli.ExecCalls[0].Container)
+		assert.Equal(t, "image", dCli.ExecCalls[0].Input)
+	}
+}
+
+func TestLoadImageNoNodes(t *testing.T) {
+	loader := NewClusterImageLoader(docker.LocalClient(docker.NewFakeClient()), k8s.NewFakeK8sClient())
+
+	ref := container.MustParseNamedTagged("gcr.io/foo/bar:tilt-11cd0b38bc3ceb95")
+	err := loader.LoadImage(context.Background(), ref, &bytes.Buffer{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no nodes found")
+	}
+}
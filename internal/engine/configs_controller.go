@@ -66,7 +66,7 @@ func logTiltfileChanges(ctx context.Context, filesChanged map[string]bool) {
 }
 
 func (cc *ConfigsController) loadTiltfile(ctx context.Context, st store.RStore,
-	initManifests []model.ManifestName, filesChanged map[string]bool, tiltfilePath string) {
+	initManifests []model.ManifestName, configArgs []string, filesChanged map[string]bool, tiltfilePath string) {
 
 	startTime := cc.clock()
 	st.Dispatch(ConfigsReloadStartedAction{FilesChanged: filesChanged, StartTime: startTime})
@@ -86,7 +86,7 @@ func (cc *ConfigsController) loadTiltfile(ctx context.Context, st store.RStore,
 	}
 	st.RUnlockState()
 
-	tlr, err := cc.tfl.Load(ctx, tiltfilePath, matching)
+	tlr, err := cc.tfl.Load(ctx, tiltfilePath, matching, configArgs)
 	if err == nil && len(tlr.Manifests) == 0 {
 		err = fmt.Errorf("No resources found. Check out https://docs.tilt.dev/tutorial.html to get started!")
 	}
@@ -107,6 +107,7 @@ func (cc *ConfigsController) loadTiltfile(ctx context.Context, st store.RStore,
 		Warnings:           tlr.Warnings,
 		Features:           tlr.FeatureFlags,
 		TeamName:           tlr.TeamName,
+		UpdateSettings:     tlr.UpdateSettings,
 	})
 }
 
@@ -119,6 +120,7 @@ func (cc *ConfigsController) OnChange(ctx context.Context, st store.RStore) {
 	defer st.RUnlockState()
 
 	initManifests := state.InitManifests
+	configArgs := state.ConfigArgs
 	if !cc.shouldBuild(state) {
 		return
 	}
@@ -135,5 +137,5 @@ func (cc *ConfigsController) OnChange(ctx context.Context, st store.RStore) {
 	}
 
 	// Release the state lock and load the tiltfile in a separate goroutine
-	go cc.loadTiltfile(ctx, st, initManifests, filesChanged, tiltfilePath)
+	go cc.loadTiltfile(ctx, st, initManifests, configArgs, filesChanged, tiltfilePath)
 }
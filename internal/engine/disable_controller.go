@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// Deletes everything we deployed for a resource when it's disabled:
+// its k8s objects, or its docker-compose container.
+//
+// (Local serve_cmds are stopped by the LocalServeController.)
+type DisableController struct {
+	kCli  k8s.Client
+	dcCli dockercompose.DockerComposeClient
+
+	// Disabled resources that we've already torn down.
+	tornDown map[model.ManifestName]bool
+}
+
+func NewDisableController(kCli k8s.Client, dcCli dockercompose.DockerComposeClient) *DisableController {
+	return &DisableController{
+		kCli:     kCli,
+		dcCli:    dcCli,
+		tornDown: make(map[model.ManifestName]bool),
+	}
+}
+
+// Returns the disabled manifests that we haven't torn down yet.
+func (c *DisableController) diff(st store.RStore) []model.Manifest {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	var result []model.Manifest
+	seen := make(map[model.ManifestName]bool)
+	for _, mt := range state.Targets() {
+		mn := mt.Manifest.Name
+		if !mt.State.Disabled {
+			continue
+		}
+		seen[mn] = true
+
+		// Wait for any in-progress build to unwind, so that we
+		// also delete whatever it deployed.
+		if c.tornDown[mn] || state.CurrentlyBuilding[mn] {
+			continue
+		}
+
+		c.tornDown[mn] = true
+		result = append(result, mt.Manifest)
+	}
+
+	// Forget resources that have been re-enabled (or removed),
+	// so that we tear them down again the next time they're disabled.
+	for mn := range c.tornDown {
+		if !seen[mn] {
+			delete(c.tornDown, mn)
+		}
+	}
+
+	return result
+}
+
+func (c *DisableController) OnChange(ctx context.Context, st store.RStore) {
+	for _, m := range c.diff(st) {
+		c.tearDown(ctx, st, m)
+	}
+}
+
+func (c *DisableController) tearDown(ctx context.Context, st store.RStore, m model.Manifest) {
+	l := logger.Get(ctx)
+
+	var err error
+	switch {
+	case m.IsK8s():
+		var entities []k8s.K8sEntity
+		entities, err = ParseYAMLFromManifests(m)
+		if err == nil && len(entities) > 0 {
+			l.Infof("Deleting k8s objects of disabled resource %s", m.Name)
+			err = c.kCli.Delete(ctx, entities)
+		}
+	case m.IsDC():
+		dct := m.DockerComposeTarget()
+		l.Infof("Removing container of disabled resource %s", m.Name)
+		w := disableLogActionWriter{store: st, manifestName: m.Name}
+		err = c.dcCli.Rm(ctx, dct.ConfigPaths, dct.Name, w, w)
+	}
+
+	if err != nil {
+		l.Infof("Error tearing down disabled resource %s: %v", m.Name, err)
+	}
+}
+
+// Sends the output of tearing down a resource to that resource's log.
+type disableLogActionWriter struct {
+	store        store.RStore
+	manifestName model.ManifestName
+}
+
+func (w disableLogActionWriter) Write(p []byte) (n int, err error) {
+	w.store.Dispatch(store.NewLogEvent(w.manifestName, p))
+	return len(p), nil
+}
+
+var _ store.Subscriber = &DisableController{}
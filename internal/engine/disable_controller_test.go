@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestDisableDeletesK8sObjects(t *testing.T) {
+	f := newDisableFixture(t)
+	defer f.TearDown()
+
+	m := model.Manifest{Name: "fe"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.MyNamespaceYAML})
+	f.upsert(m)
+
+	f.dc.OnChange(f.ctx, f.st)
+	assert.Equal(t, "", f.kCli.DeletedYaml)
+
+	f.setDisabled("fe", true)
+	f.dc.OnChange(f.ctx, f.st)
+	assert.Contains(t, f.kCli.DeletedYaml, "name: mynamespace")
+
+	// Only tear down once.
+	f.kCli.DeletedYaml = ""
+	f.dc.OnChange(f.ctx, f.st)
+	assert.Equal(t, "", f.kCli.DeletedYaml)
+
+	// Re-enabling and disabling again tears it down again.
+	f.setDisabled("fe", false)
+	f.dc.OnChange(f.ctx, f.st)
+	f.setDisabled("fe", true)
+	f.dc.OnChange(f.ctx, f.st)
+	assert.Contains(t, f.kCli.DeletedYaml, "name: mynamespace")
+}
+
+func TestDisableRemovesDockerComposeContainer(t *testing.T) {
+	f := newDisableFixture(t)
+	defer f.TearDown()
+
+	m := model.Manifest{Name: "db"}.WithDeployTarget(model.DockerComposeTarget{
+		Name:        "db",
+		ConfigPaths: []string{f.JoinPath("docker-compose.yml")},
+	})
+	f.upsert(m)
+	f.setDisabled("db", true)
+
+	f.dc.OnChange(f.ctx, f.st)
+	rmCalls := f.dcCli.RmCalls()
+	if assert.Equal(t, 1, len(rmCalls)) {
+		assert.Equal(t, model.TargetName("db"), rmCalls[0].ServiceName)
+		assert.Equal(t, []string{f.JoinPath("docker-compose.yml")}, rmCalls[0].PathToConfig)
+	}
+}
+
+func TestDisableWaitsForCurrentBuild(t *testing.T) {
+	f := newDisableFixture(t)
+	defer f.TearDown()
+
+	m := model.Manifest{Name: "fe"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.MyNamespaceYAML})
+	f.upsert(m)
+	f.setDisabled("fe", true)
+
+	state := f.st.LockMutableStateForTesting()
+	state.CurrentlyBuilding["fe"] = true
+	f.st.UnlockMutableState()
+
+	f.dc.OnChange(f.ctx, f.st)
+	assert.Equal(t, "", f.kCli.DeletedYaml)
+
+	state = f.st.LockMutableStateForTesting()
+	delete(state.CurrentlyBuilding, "fe")
+	f.st.UnlockMutableState()
+
+	f.dc.OnChange(f.ctx, f.st)
+	assert.Contains(t, f.kCli.DeletedYaml, "name: mynamespace")
+}
+
+type disableFixture struct {
+	*tempdir.TempDirFixture
+	ctx   context.Context
+	st    *store.Store
+	kCli  *k8s.FakeK8sClient
+	dcCli *dockercompose.FakeDCClient
+	dc    *DisableController
+}
+
+func newDisableFixture(t *testing.T) *disableFixture {
+	f := tempdir.NewTempDirFixture(t)
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.DebugLvl, ioutil.Discard))
+	st, _ := store.NewStoreForTesting()
+	kCli := k8s.NewFakeK8sClient()
+	dcCli := dockercompose.NewFakeDockerComposeClient(t, ctx)
+	return &disableFixture{
+		TempDirFixture: f,
+		ctx:            ctx,
+		st:             st,
+		kCli:           kCli,
+		dcCli:          dcCli,
+		dc:             NewDisableController(kCli, dcCli),
+	}
+}
+
+func (f *disableFixture) upsert(m model.Manifest) {
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	f.st.UnlockMutableState()
+}
+
+func (f *disableFixture) setDisabled(name model.ManifestName, disabled bool) {
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets[name].State.Disabled = disabled
+	f.st.UnlockMutableState()
+}
+
+func (f *disableFixture) TearDown() {
+	f.kCli.TearDown()
+	f.TempDirFixture.TearDown()
+}
@@ -24,7 +24,9 @@ func NewDockerComposeEventWatcher(dcc dockercompose.DockerComposeClient) *Docker
 func (w *DockerComposeEventWatcher) needsWatch(st store.RStore) bool {
 	state := st.RLockState()
 	defer st.RUnlockState()
-	return state.WatchFiles && !w.watching
+	// In CI mode, we need container events to tell when a service crashes.
+	watch := state.WatchFiles || state.EngineMode == store.EngineModeCI
+	return watch && !w.watching
 }
 
 func (w *DockerComposeEventWatcher) OnChange(ctx context.Context, st store.RStore) {
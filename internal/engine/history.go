@@ -0,0 +1,212 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/windmilleng/wmclient/pkg/dirs"
+
+	"github.com/windmilleng/tilt/internal/ignore"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// How often we write the history to disk, at most.
+const historyWriteInterval = 2 * time.Second
+
+// Persists build history, deployed images, pods, and log tails to disk,
+// so that a restarted Tilt can pick up where the last one left off.
+type HistoryController struct {
+	// The directory where we persist the engine state between Tilt sessions.
+	dir string
+
+	st          store.RStore
+	lastWrite   time.Time
+	lastHistory store.History
+	loggedError bool
+}
+
+func NewHistoryController(dir *dirs.WindmillDir) *HistoryController {
+	return &HistoryController{dir: filepath.Join(dir.Root(), "history")}
+}
+
+// Each Tiltfile gets its own history file.
+func (c *HistoryController) path(tiltfilePath string) string {
+	hash := sha256.Sum256([]byte(tiltfilePath))
+	name := filepath.Base(filepath.Dir(tiltfilePath))
+	return filepath.Join(c.dir, fmt.Sprintf("%s-%x.json", name, hash[:6]))
+}
+
+// Load the history of the previous session with this Tiltfile, if any.
+func (c *HistoryController) Load(ctx context.Context, tiltfilePath string) store.History {
+	bs, err := ioutil.ReadFile(c.path(tiltfilePath))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Get(ctx).Infof("Error loading history of previous session: %v", err)
+		}
+		return store.History{}
+	}
+
+	var h store.History
+	err = json.Unmarshal(bs, &h)
+	if err != nil {
+		logger.Get(ctx).Infof("Error loading history of previous session: %v", err)
+		return store.History{}
+	}
+	return h
+}
+
+func (c *HistoryController) OnChange(ctx context.Context, st store.RStore) {
+	c.st = st
+	if time.Since(c.lastWrite) < historyWriteInterval {
+		return
+	}
+
+	err := c.write(st)
+	if err != nil && !c.loggedError {
+		c.loggedError = true
+		logger.Get(ctx).Infof("Error saving history: %v", err)
+	}
+}
+
+// Make sure that the last changes make it to disk.
+func (c *HistoryController) TearDown(ctx context.Context) {
+	if c.st != nil {
+		_ = c.write(c.st)
+	}
+}
+
+func (c *HistoryController) write(st store.RStore) error {
+	state := st.RLockState()
+	if state.TiltfilePath == "" || state.EngineMode == store.EngineModeCI {
+		st.RUnlockState()
+		return nil
+	}
+	h := store.NewHistory(state)
+	path := c.path(state.TiltfilePath)
+	st.RUnlockState()
+
+	c.lastWrite = time.Now()
+	if reflect.DeepEqual(h, c.lastHistory) {
+		return nil
+	}
+
+	bs, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(c.dir, os.FileMode(0755))
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file first, so that we never leave a half-written file behind.
+	tmp, err := ioutil.TempFile(c.dir, ".history-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(bs)
+	closeErr := tmp.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return err
+	}
+
+	c.lastHistory = h
+	return nil
+}
+
+// Results restored from a previous session are only valid if none of their
+// files changed while Tilt wasn't watching.
+func restoredResultIfUnchanged(ctx context.Context, spec model.TargetSpec, status store.BuildStatus) store.BuildResult {
+	iTarget, ok := spec.(model.ImageTarget)
+	if !ok {
+		return store.BuildResult{}
+	}
+
+	changed, err := filesChangedSince(iTarget, status.LastSuccessfulBuildTime)
+	if err != nil {
+		logger.Get(ctx).Debugf("Error checking files of image %s: %v", iTarget.ID(), err)
+		return store.BuildResult{}
+	}
+	if changed {
+		return store.BuildResult{}
+	}
+
+	logger.Get(ctx).Infof("Reusing image %s from the previous session",
+		status.LastSuccessfulResult.Image.String())
+	return status.LastSuccessfulResult
+}
+
+var errFileChanged = errors.New("file changed")
+
+// Whether any of the files that the image depends on were modified after t.
+func filesChangedSince(iTarget model.ImageTarget, t time.Time) (bool, error) {
+	if t.IsZero() {
+		return true, nil
+	}
+
+	matcher, err := ignore.CreateFileChangeFilter(iTarget)
+	if err != nil {
+		return false, err
+	}
+
+	for _, dep := range iTarget.Dependencies() {
+		err := filepath.Walk(dep, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				ignored, err := matcher.MatchesEntireDir(path)
+				if err != nil {
+					return err
+				}
+				if ignored {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			ignored, err := matcher.Matches(path)
+			if err != nil {
+				return err
+			}
+			if !ignored && info.ModTime().After(t) {
+				return errFileChanged
+			}
+			return nil
+		})
+		if err == errFileChanged {
+			return true, nil
+		} else if os.IsNotExist(err) {
+			// A deleted dependency is a change, too.
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+var _ store.Subscriber = &HistoryController{}
+var _ store.TearDowner = &HistoryController{}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/windmilleng/wmclient/pkg/dirs"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestHistoryWriteAndLoad(t *testing.T) {
+	f := newHistoryFixture(t)
+	defer f.TearDown()
+
+	m := model.Manifest{Name: "fe"}.WithDeployTarget(model.LocalTarget{Cmd: model.ToShellCmd("echo hi")})
+	state := f.st.LockMutableStateForTesting()
+	state.TiltfilePath = f.JoinPath("Tiltfile")
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	state.ManifestTargets["fe"].State.BuildHistory = []model.BuildRecord{
+		{StartTime: time.Now(), FinishTime: time.Now()},
+	}
+	f.st.UnlockMutableState()
+
+	f.hc.OnChange(f.ctx, f.st)
+
+	h := f.hc.Load(f.ctx, f.JoinPath("Tiltfile"))
+	assert.Equal(t, 1, len(h.Resources["fe"].BuildHistory))
+
+	// A different Tiltfile has a different history.
+	h = f.hc.Load(f.ctx, f.JoinPath("other", "Tiltfile"))
+	assert.Equal(t, 0, len(h.Resources))
+}
+
+func TestHistoryNotWrittenInCI(t *testing.T) {
+	f := newHistoryFixture(t)
+	defer f.TearDown()
+
+	state := f.st.LockMutableStateForTesting()
+	state.TiltfilePath = f.JoinPath("Tiltfile")
+	state.EngineMode = store.EngineModeCI
+	f.st.UnlockMutableState()
+
+	f.hc.OnChange(f.ctx, f.st)
+
+	_, err := os.Stat(f.hc.dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRestoredResultIfUnchanged(t *testing.T) {
+	f := newHistoryFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("src/main.go", "package main")
+	iTarget := model.NewImageTarget(container.MustParseSelector("gcr.io/windmill/fe")).
+		WithBuildDetails(model.DockerBuild{BuildPath: f.JoinPath("src")})
+	ref := container.MustParseNamedTagged("gcr.io/windmill/fe:tilt-12345")
+	status := store.BuildStatus{
+		LastSuccessfulResult:    store.NewImageBuildResult(iTarget.ID(), ref),
+		LastSuccessfulBuildTime: time.Now().Add(time.Minute),
+		RestoredFromHistory:     true,
+	}
+
+	result := restoredResultIfUnchanged(f.ctx, iTarget, status)
+	assert.Equal(t, ref.String(), result.Image.String())
+
+	status.LastSuccessfulBuildTime = time.Now().Add(-time.Minute)
+	result = restoredResultIfUnchanged(f.ctx, iTarget, status)
+	assert.True(t, result.IsEmpty())
+}
+
+func TestFilesChangedSinceIgnoresDockerignore(t *testing.T) {
+	f := newHistoryFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("src/.dockerignore", "tmp")
+	f.WriteFile("src/main.go", "package main")
+	iTarget := model.NewImageTarget(container.MustParseSelector("gcr.io/windmill/fe")).
+		WithBuildDetails(model.DockerBuild{BuildPath: f.JoinPath("src")})
+	iTarget = iTarget.WithDockerignores([]model.Dockerignore{
+		{LocalPath: f.JoinPath("src"), Contents: "tmp"},
+	})
+
+	buildTime := time.Now()
+	past := buildTime.Add(-time.Minute)
+	for _, p := range []string{"src", "src/.dockerignore", "src/main.go"} {
+		require.NoError(t, os.Chtimes(f.JoinPath(p), past, past))
+	}
+
+	changed, err := filesChangedSince(iTarget, buildTime)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	f.WriteFile("src/tmp/scratch.txt", "scratch")
+	changed, err = filesChangedSince(iTarget, buildTime)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	f.WriteFile("src/main.go", "package main // changed")
+	changed, err = filesChangedSince(iTarget, buildTime)
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
+
+type historyFixture struct {
+	*tempdir.TempDirFixture
+	ctx context.Context
+	st  *store.Store
+	hc  *HistoryController
+}
+
+func newHistoryFixture(t *testing.T) *historyFixture {
+	f := tempdir.NewTempDirFixture(t)
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.DebugLvl, ioutil.Discard))
+	st, _ := store.NewStoreForTesting()
+	return &historyFixture{
+		TempDirFixture: f,
+		ctx:            ctx,
+		st:             st,
+		hc:             NewHistoryController(dirs.NewWindmillDirAt(f.JoinPath(".windmill"))),
+	}
+}
@@ -30,6 +30,13 @@ func NewImageAndCacheBuilder(ib build.ImageBuilder, cb build.CacheBuilder, custb
 	}
 }
 
+// Returns a copy of this builder that builds images with the given ImageBuilder.
+func (icb *imageAndCacheBuilder) withImageBuilder(ib build.ImageBuilder) *imageAndCacheBuilder {
+	result := *icb
+	result.ib = ib
+	return &result
+}
+
 func (icb *imageAndCacheBuilder) Build(ctx context.Context, iTarget model.ImageTarget, state store.BuildState, ps *build.PipelineState) (reference.NamedTagged, error) {
 	var n reference.NamedTagged
 
@@ -47,7 +54,7 @@ func (icb *imageAndCacheBuilder) Build(ctx context.Context, iTarget model.ImageT
 		defer ps.EndPipelineStep(ctx)
 
 		df := icb.dockerfile(iTarget, cacheRef)
-		ref, err := icb.ib.BuildImage(ctx, ps, refToBuild, df, bd.BuildPath, ignore.CreateBuildContextFilter(iTarget), bd.BuildArgs)
+		ref, err := icb.ib.BuildImage(ctx, ps, refToBuild, df, bd, ignore.CreateBuildContextFilter(iTarget))
 
 		if err != nil {
 			return nil, err
@@ -76,6 +83,14 @@ func (icb *imageAndCacheBuilder) Build(ctx context.Context, iTarget model.ImageT
 			return nil, err
 		}
 		n = ref
+	case model.PackBuild:
+		ps.StartPipelineStep(ctx, "Building with buildpacks: [%s]", userFacingRefName)
+		defer ps.EndPipelineStep(ctx)
+		ref, err := icb.ib.PackBuildImage(ctx, ps, refToBuild, bd, ignore.CreateBuildContextFilter(iTarget))
+		if err != nil {
+			return nil, err
+		}
+		n = ref
 	default:
 		// Theoretically this should never trip b/c we `validate` the manifest beforehand...?
 		// If we get here, something is very wrong.
@@ -2,12 +2,13 @@ package engine
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"io"
-	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
@@ -17,6 +18,7 @@ import (
 	"github.com/windmilleng/tilt/internal/build"
 	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/dockerfile"
+	"github.com/windmilleng/tilt/internal/ignore"
 	"github.com/windmilleng/tilt/internal/k8s"
 	"github.com/windmilleng/tilt/internal/store"
 	"github.com/windmilleng/tilt/internal/synclet/sidecar"
@@ -26,24 +28,6 @@ import (
 
 var _ BuildAndDeployer = &ImageBuildAndDeployer{}
 
-type KINDPusher interface {
-	PushToKIND(ctx context.Context, ref reference.NamedTagged, w io.Writer) error
-}
-
-type cmdKINDPusher struct{}
-
-func (*cmdKINDPusher) PushToKIND(ctx context.Context, ref reference.NamedTagged, w io.Writer) error {
-	cmd := exec.CommandContext(ctx, "kind", "load", "docker-image", ref.String())
-	cmd.Stdout = w
-	cmd.Stderr = w
-
-	return cmd.Run()
-}
-
-func NewKINDPusher() KINDPusher {
-	return &cmdKINDPusher{}
-}
-
 type ImageBuildAndDeployer struct {
 	ib            build.ImageBuilder
 	icb           *imageAndCacheBuilder
@@ -53,7 +37,8 @@ type ImageBuildAndDeployer struct {
 	analytics     *analytics.TiltAnalytics
 	injectSynclet bool
 	clock         build.Clock
-	kp            KINDPusher
+	loader        ClusterImageLoader
+	bkAddr        build.BuildKitAddr
 }
 
 func NewImageBuildAndDeployer(
@@ -66,7 +51,8 @@ func NewImageBuildAndDeployer(
 	updMode UpdateMode,
 	c build.Clock,
 	runtime container.Runtime,
-	kp KINDPusher,
+	loader ClusterImageLoader,
+	bkAddr build.BuildKitAddr,
 ) *ImageBuildAndDeployer {
 	return &ImageBuildAndDeployer{
 		ib:        b,
@@ -76,7 +62,8 @@ func NewImageBuildAndDeployer(
 		analytics: analytics,
 		clock:     c,
 		runtime:   runtime,
-		kp:        kp,
+		loader:    loader,
+		bkAddr:    bkAddr,
 	}
 }
 
@@ -108,7 +95,13 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 		ibd.analytics.Timer("build.image", time.Since(startTime), tags)
 	}()
 
-	q, err := NewImageTargetQueue(ctx, iTargets, stateSet, ibd.ib.ImageExists)
+	state := st.RLockState()
+	settings := state.UpdateSettings
+	ownerLabel := k8s.TiltOwnerLabel(state.TiltfilePath)
+	st.RUnlockState()
+
+	ib, icb := ibd.imageBuilder(settings)
+	q, err := NewImageTargetQueue(ctx, iTargets, stateSet, ib.ImageExists)
 	if err != nil {
 		return store.BuildResultSet{}, err
 	}
@@ -117,7 +110,14 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 	numStages := q.CountDirty()*2 + 1
 
 	ps := build.NewPipelineState(ctx, numStages, ibd.clock)
-	defer func() { ps.End(ctx, err) }()
+	defer func() {
+		endErr := err
+		if IsAwaitingApprovalError(err) {
+			// Waiting for approval isn't a failure.
+			endErr = nil
+		}
+		ps.End(ctx, endErr)
+	}()
 
 	var anyInPlaceBuild bool
 
@@ -133,19 +133,31 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 			return store.BuildResult{}, err
 		}
 
-		ref, err := ibd.icb.Build(ctx, iTarget, state, ps)
+		anyInPlaceBuild = anyInPlaceBuild ||
+			!iTarget.AnyFastBuildInfo().Empty() || !iTarget.AnyLiveUpdateInfo().Empty()
+
+		// Compare against the last image we deployed, even if a dependency was rebuilt.
+		// The digest covers the dependency refs injected into the Dockerfile.
+		contextDigest := ibd.contextDigest(ctx, iTarget)
+		result, ok, err := ibd.reuseUnchangedImage(ctx, ib, ps, iTarget, kTarget, settings, stateSet[iTarget.ID()], contextDigest)
+		if err != nil {
+			return store.BuildResult{}, err
+		}
+		if ok {
+			return result, nil
+		}
+
+		ref, err := icb.Build(ctx, iTarget, state, ps)
 		if err != nil {
 			return store.BuildResult{}, err
 		}
 
-		ref, err = ibd.push(ctx, ref, ps, iTarget, kTarget)
+		ref, err = ibd.push(ctx, ib, ref, ps, iTarget, kTarget, settings)
 		if err != nil {
 			return store.BuildResult{}, err
 		}
 
-		anyInPlaceBuild = anyInPlaceBuild ||
-			!iTarget.AnyFastBuildInfo().Empty() || !iTarget.AnyLiveUpdateInfo().Empty()
-		return store.NewImageBuildResult(iTarget.ID(), ref), nil
+		return store.NewImageBuildResult(iTarget.ID(), ref).WithContextDigest(contextDigest), nil
 	})
 	if err != nil {
 		return store.BuildResultSet{}, err
@@ -153,10 +165,84 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 
 	// (If we pass an empty list of refs here (as we will do if only deploying
 	// yaml), we just don't inject any image refs into the yaml, nbd.
-	return ibd.deploy(ctx, st, ps, iTargetMap, kTarget, q.results, anyInPlaceBuild)
+	return ibd.deploy(ctx, st, ps, iTargetMap, kTarget, q.results, anyInPlaceBuild, settings, ownerLabel)
+}
+
+// Picks the image builder for this build: BuildKit, if the user gave us the
+// address of a BuildKit daemon (with --buildkit-addr or in the Tiltfile),
+// and the Docker daemon otherwise.
+func (ibd *ImageBuildAndDeployer) imageBuilder(settings model.UpdateSettings) (build.ImageBuilder, *imageAndCacheBuilder) {
+	addr := string(ibd.bkAddr)
+	if addr == "" {
+		addr = settings.BuildKitAddr
+	}
+	if addr == "" {
+		return ibd.ib, ibd.icb
+	}
+
+	// Local clusters like KIND can't pull from a registry, so we
+	// give them a tarball instead.
+	export := build.BuildKitExportRegistry
+	if ibd.env.LoadsImagesIntoNodes() {
+		export = build.BuildKitExportArchive
+	}
+
+	ib := build.NewBuildKitImageBuilder(addr, export, ibd.clock)
+	return ib, ibd.icb.withImageBuilder(ib)
+}
+
+// Returns a digest of the build context of a docker_build, or an empty digest
+// if we can't compute one.
+func (ibd *ImageBuildAndDeployer) contextDigest(ctx context.Context, iTarget model.ImageTarget) digest.Digest {
+	db, ok := iTarget.BuildDetails.(model.DockerBuild)
+	if !ok {
+		return ""
+	}
+
+	d, err := build.DigestBuildContext(ctx, dockerfile.Dockerfile(db.Dockerfile), db.BuildPath,
+		ignore.CreateBuildContextFilter(iTarget), db.BuildArgs)
+	if err != nil {
+		logger.Get(ctx).Debugf("Error computing digest of build context for %s: %v", iTarget.ConfigurationRef, err)
+		return ""
+	}
+	return d
+}
+
+// If the build context has the same contents as the image we deployed last time,
+// re-use that image, and skip the build.
+func (ibd *ImageBuildAndDeployer) reuseUnchangedImage(ctx context.Context, ib build.ImageBuilder, ps *build.PipelineState,
+	iTarget model.ImageTarget, kTarget model.K8sTarget, settings model.UpdateSettings,
+	state store.BuildState, d digest.Digest) (store.BuildResult, bool, error) {
+	last := state.LastResult
+	if d == "" || last.ContextDigest != d || !last.HasImage() || last.IsInPlaceUpdate() {
+		return store.BuildResult{}, false, nil
+	}
+
+	exists, err := ib.ImageExists(ctx, last.Image)
+	if err != nil || !exists {
+		return store.BuildResult{}, false, nil
+	}
+
+	ps.StartPipelineStep(ctx, "Reusing image: [%s]", iTarget.ConfigurationRef.String())
+	ps.Printf(ctx, "Build context unchanged; skipping build of %s", last.Image.String())
+	ps.EndPipelineStep(ctx)
+
+	// We can't tell if the cluster's nodes still have the image (e.g., the
+	// cluster may have been re-created since we loaded it), so we load it again.
+	if ibd.env.LoadsImagesIntoNodes() {
+		_, err := ibd.push(ctx, ib, last.Image, ps, iTarget, kTarget, settings)
+		if err != nil {
+			return store.BuildResult{}, false, err
+		}
+	}
+
+	result := store.NewImageBuildResult(iTarget.ID(), last.Image).WithContextDigest(d)
+	result.NoOp = true
+	return result, true, nil
 }
 
-func (ibd *ImageBuildAndDeployer) push(ctx context.Context, ref reference.NamedTagged, ps *build.PipelineState, iTarget model.ImageTarget, kTarget model.K8sTarget) (reference.NamedTagged, error) {
+func (ibd *ImageBuildAndDeployer) push(ctx context.Context, ib build.ImageBuilder, ref reference.NamedTagged, ps *build.PipelineState,
+	iTarget model.ImageTarget, kTarget model.K8sTarget, settings model.UpdateSettings) (reference.NamedTagged, error) {
 	ps.StartPipelineStep(ctx, "Pushing %s", ref.String())
 	defer ps.EndPipelineStep(ctx)
 
@@ -167,40 +253,82 @@ func (ibd *ImageBuildAndDeployer) push(ctx context.Context, ref reference.NamedT
 
 	// We can also skip the push of the image if it isn't used
 	// in any k8s resources! (e.g., it's consumed by another image).
-	if ibd.canAlwaysSkipPush() || !isImageDeployedToK8s(iTarget, kTarget) || cbSkip {
+	bkb, isBuildKit := ib.(*build.BuildKitImageBuilder)
+	if (!isBuildKit && ibd.canAlwaysSkipPush()) || !isImageDeployedToK8s(iTarget, kTarget) || cbSkip {
 		ps.Printf(ctx, "Skipping push")
 		return ref, nil
 	}
 
-	var err error
-	if ibd.env == k8s.EnvKIND {
-		ps.Printf(ctx, "Pushing to KIND")
-		err := ibd.kp.PushToKIND(ctx, ref, ps.Writer(ctx))
+	if isBuildKit && bkb.Export() == build.BuildKitExportRegistry {
+		ps.Printf(ctx, "Skipping push: BuildKit pushed the image when it built it")
+		return ref, nil
+	}
+
+	if isBuildKit && bkb.Export() == build.BuildKitExportArchive {
+		ps.Printf(ctx, "Loading image archive into %s", ibd.env)
+		err := withRetries(ctx, ps, settings, "Load image archive", func() error {
+			err := ibd.loader.LoadImageArchive(ctx, bkb.ArchivePath(ref), ps.Writer(ctx))
+			if err != nil {
+				return fmt.Errorf("Error loading image archive into %s: %v", ibd.env, err)
+			}
+			return nil
+		})
 		if err != nil {
-			return nil, fmt.Errorf("Error pushing to KIND: %v", err)
+			return nil, err
 		}
-	} else {
-		ps.Printf(ctx, "Pushing to registry")
-		ref, err = ibd.ib.PushImage(ctx, ref, ps.Writer(ctx))
+		return ref, nil
+	}
+
+	// KIND and k3d nodes can't see the local Docker daemon's images, but we can
+	// load the image into the nodes directly, so we don't need a registry.
+	// We deploy with imagePullPolicy: IfNotPresent (see createEntitiesToDeploy),
+	// so the nodes never try to pull it.
+	if ibd.env.LoadsImagesIntoNodes() {
+		ps.Printf(ctx, "Loading image into %s", ibd.env)
+		err := withRetries(ctx, ps, settings, "Load image", func() error {
+			err := ibd.loader.LoadImage(ctx, ref, ps.Writer(ctx))
+			if err != nil {
+				return fmt.Errorf("Error loading image into %s: %v", ibd.env, err)
+			}
+			return nil
+		})
 		if err != nil {
 			return nil, err
 		}
+		return ref, nil
+	}
+
+	ps.Printf(ctx, "Pushing to registry")
+	var pushed reference.NamedTagged
+	err := withRetries(ctx, ps, settings, "Push", func() error {
+		var err error
+		pushed, err = ib.PushImage(ctx, ref, ps.Writer(ctx))
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return ref, nil
+	return pushed, nil
 }
 
 // Returns: the entities deployed and the namespace of the pod with the given image name/tag.
 func (ibd *ImageBuildAndDeployer) deploy(ctx context.Context, st store.RStore, ps *build.PipelineState,
-	iTargetMap map[model.TargetID]model.ImageTarget, kTarget model.K8sTarget, results store.BuildResultSet, needsSynclet bool) (store.BuildResultSet, error) {
+	iTargetMap map[model.TargetID]model.ImageTarget, kTarget model.K8sTarget, results store.BuildResultSet, needsSynclet bool,
+	settings model.UpdateSettings, ownerLabel model.LabelPair) (store.BuildResultSet, error) {
 	ps.StartPipelineStep(ctx, "Deploying")
 	defer ps.EndPipelineStep(ctx)
 
 	ps.StartBuildStep(ctx, "Injecting images into Kubernetes YAML")
 
 	deployID := model.NewDeployID()
-	deployLabel := k8s.TiltDeployLabel(deployID)
-	newK8sEntities, err := ibd.createEntitiesToDeploy(ctx, iTargetMap, kTarget, results, needsSynclet, deployLabel)
+	labels := []model.LabelPair{k8s.TiltDeployLabel(deployID), ownerLabel}
+	newK8sEntities, err := ibd.createEntitiesToDeploy(ctx, iTargetMap, kTarget, results, needsSynclet, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ibd.confirmChanges(ctx, st, kTarget, newK8sEntities)
 	if err != nil {
 		return nil, err
 	}
@@ -214,7 +342,12 @@ func (ibd *ImageBuildAndDeployer) deploy(ctx context.Context, st store.RStore, p
 		l.Infof("   %s", displayName)
 	}
 
-	deployed, err := ibd.k8sClient.Upsert(ctx, newK8sEntities)
+	var deployed []k8s.K8sEntity
+	err = withRetries(ctx, ps, settings, "Apply", func() error {
+		var err error
+		deployed, err = ibd.k8sClient.Upsert(ctx, newK8sEntities)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -229,6 +362,142 @@ func (ibd *ImageBuildAndDeployer) deploy(ctx context.Context, st store.RStore, p
 	return results, nil
 }
 
+// With --confirm-changes, holds back changes that need the user's approval.
+//
+// If the user already approved these changes, applies them.
+func (ibd *ImageBuildAndDeployer) confirmChanges(ctx context.Context, st store.RStore,
+	kTarget model.K8sTarget, entities []k8s.K8sEntity) error {
+	mn := model.ManifestName(kTarget.Name)
+
+	state := st.RLockState()
+	confirm := state.ConfirmChanges
+	yamlChanged := true
+	var approved []k8s.EntityDiff
+	if ms, ok := state.ManifestState(mn); ok {
+		// We forget the last deploy when the Tiltfile changes the manifest.
+		yamlChanged = ms.BuildStatus(kTarget.ID()).LastSuccessfulResult.IsEmpty()
+		approved = ms.ApprovedK8sChanges
+	}
+	st.RUnlockState()
+
+	if !confirm {
+		return nil
+	}
+
+	diffs, err := ibd.k8sClient.Diff(ctx, entities)
+	if err != nil {
+		return errors.Wrap(err, "diff")
+	}
+
+	changes := changesNeedingApproval(diffs, yamlChanged)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	l := logger.Get(ctx)
+	if changesApproved(changes, approved) {
+		l.Infof("Applying approved changes")
+		return nil
+	}
+
+	st.Dispatch(NewK8sChangesPendingAction(mn, changes))
+	l.Infof("These changes need your approval:")
+	for _, c := range changes {
+		l.Infof("   %s", c)
+		if c.Recreate {
+			l.Infof("      (will be deleted and re-created)")
+		}
+		for _, f := range c.Fields {
+			l.Infof("      %s", f)
+		}
+	}
+	l.Infof("Waiting for approval. Press (c) in the HUD to review and approve the changes")
+	return AwaitingApprovalErrorf("waiting for approval of changes to %s", mn)
+}
+
+// Whether the user approved all of these changes.
+//
+// A rebuild gives each image that Tilt builds a new tag, so we ignore new
+// image tags when we compare a change to the approved one. Anything else that
+// changed since the user approved it, including whether the object needs to
+// be re-created, needs approval again.
+func changesApproved(changes, approved []k8s.EntityDiff) bool {
+	for _, c := range changes {
+		ok := false
+		for _, a := range approved {
+			if a.Kind == c.Kind && a.Name == c.Name && a.Namespace == c.Namespace &&
+				a.Recreate == c.Recreate && sameFieldDiffs(a.Fields, c.Fields) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func sameFieldDiffs(a, b []k8s.FieldDiff) bool {
+	a, b = withoutRetaggedImages(a), withoutRetaggedImages(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Drops the fields where the only change is a new Tilt-built tag of the same image.
+func withoutRetaggedImages(fields []k8s.FieldDiff) []k8s.FieldDiff {
+	var result []k8s.FieldDiff
+	for _, f := range fields {
+		oldRef, oldOK := decodeImageRef(f.Old)
+		newRef, newOK := decodeImageRef(f.New)
+		if oldOK && newOK && oldRef.Name() == newRef.Name() &&
+			strings.HasPrefix(newRef.Tag(), build.ImageTagPrefix) {
+			continue
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+// Decodes a JSON-encoded diff value that holds a tagged image reference.
+func decodeImageRef(val string) (reference.NamedTagged, bool) {
+	var s string
+	if json.Unmarshal([]byte(val), &s) != nil {
+		return nil, false
+	}
+	ref, err := container.ParseNamedTagged(s)
+	if err != nil {
+		return nil, false
+	}
+	return ref, true
+}
+
+// Returns the changes that we need the user to approve before we apply them:
+// anything that deletes and re-creates a live object, and any change to a live
+// object when its YAML may have changed since our last deploy (e.g., because
+// the Tiltfile changed).
+//
+// Creating new objects never needs approval.
+func changesNeedingApproval(diffs []k8s.EntityDiff, yamlChanged bool) []k8s.EntityDiff {
+	var result []k8s.EntityDiff
+	for _, d := range diffs {
+		if d.Create {
+			continue
+		}
+		if d.Recreate || (yamlChanged && len(d.Fields) > 0) {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
 func (ibd *ImageBuildAndDeployer) indentLogger(ctx context.Context) (context.Context, logger.Logger) {
 	l := logger.Get(ctx)
 	writer := logger.NewPrefixedWriter(logger.Blue(l).Sprint("  │ "), l.Writer(logger.InfoLvl))
@@ -238,7 +507,7 @@ func (ibd *ImageBuildAndDeployer) indentLogger(ctx context.Context) (context.Con
 
 func (ibd *ImageBuildAndDeployer) createEntitiesToDeploy(ctx context.Context,
 	iTargetMap map[model.TargetID]model.ImageTarget, k8sTarget model.K8sTarget,
-	results store.BuildResultSet, needsSynclet bool, deployLabel model.LabelPair) ([]k8s.K8sEntity, error) {
+	results store.BuildResultSet, needsSynclet bool, labels []model.LabelPair) ([]k8s.K8sEntity, error) {
 	newK8sEntities := []k8s.K8sEntity{}
 
 	// TODO(nick): The parsed YAML should probably be a part of the model?
@@ -252,7 +521,7 @@ func (ibd *ImageBuildAndDeployer) createEntitiesToDeploy(ctx context.Context,
 	injectedDepIDs := map[model.TargetID]bool{}
 	for _, e := range entities {
 		injectedSynclet := false
-		e, err = k8s.InjectLabels(e, []model.LabelPair{k8s.TiltRunLabel(), {Key: k8s.ManifestNameLabel, Value: k8sTarget.Name.String()}, deployLabel})
+		e, err = k8s.InjectLabels(e, append([]model.LabelPair{k8s.TiltRunLabel(), {Key: k8s.ManifestNameLabel, Value: k8sTarget.Name.String()}}, labels...))
 		if err != nil {
 			return nil, errors.Wrap(err, "deploy")
 		}
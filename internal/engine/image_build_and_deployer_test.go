@@ -5,6 +5,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -13,10 +16,12 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/windmilleng/wmclient/pkg/dirs"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 
+	"github.com/windmilleng/tilt/internal/build"
 	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/docker"
 	"github.com/windmilleng/tilt/internal/k8s"
@@ -259,6 +264,49 @@ func TestImageIsDirtyAfterContainerBuild(t *testing.T) {
 	assert.Equal(t, 1, f.docker.PushCount)
 }
 
+func TestImageIsNoOpWhenContextUnchanged(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	f.WriteFile("main.go", "package main")
+	manifest := NewSanchoDockerBuildManifest(f)
+	iTargetID := manifest.ImageTargets[0].ID()
+	result1, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, "", result1[iTargetID].ContextDigest.String())
+	assert.False(t, result1[iTargetID].NoOp)
+
+	// Touching a file doesn't change the contents of the build context.
+	f.docker.ImageListCount = 1
+	f.WriteFile("main.go", "package main")
+	stateSet := store.BuildStateSet{
+		iTargetID: store.NewBuildState(result1[iTargetID], []string{f.JoinPath("main.go")}),
+	}
+	result2, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), stateSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, f.docker.BuildCount)
+	assert.Equal(t, 1, f.docker.PushCount)
+	assert.True(t, result2[iTargetID].NoOp)
+	assert.Equal(t, result1[iTargetID].Image.String(), result2[iTargetID].Image.String())
+
+	// Editing it does.
+	f.WriteFile("main.go", "package main // edited")
+	result3, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), stateSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, f.docker.BuildCount)
+	assert.Equal(t, 2, f.docker.PushCount)
+	assert.False(t, result3[iTargetID].NoOp)
+	assert.NotEqual(t, result1[iTargetID].ContextDigest, result3[iTargetID].ContextDigest)
+}
+
 func TestMultiStageDockerBuild(t *testing.T) {
 	f := newIBDFixture(t, k8s.EnvGKE)
 	defer f.TearDown()
@@ -271,7 +319,7 @@ func TestMultiStageDockerBuild(t *testing.T) {
 
 	assert.Equal(t, 2, f.docker.BuildCount)
 	assert.Equal(t, 1, f.docker.PushCount)
-	assert.Equal(t, 0, f.kp.pushCount)
+	assert.Equal(t, 0, f.loader.imageCount)
 
 	expected := expectedFile{
 		Path: "Dockerfile",
@@ -369,10 +417,289 @@ func TestKINDPush(t *testing.T) {
 	}
 
 	assert.Equal(t, 1, f.docker.BuildCount)
-	assert.Equal(t, 1, f.kp.pushCount)
+	assert.Equal(t, 1, f.loader.imageCount)
+	assert.Equal(t, 0, f.docker.PushCount)
+}
+
+func TestK3DLoadsImageIntoNodes(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvK3D)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	iTarget := manifest.ImageTargetAt(0)
+	ref := container.MustParseNamedTagged("gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95")
+	ps := build.NewPipelineState(f.ctx, 1, fakeClock{})
+	_, err := f.ibd.push(f.ctx, f.ibd.ib, ref, ps, iTarget, manifest.K8sTarget(), model.DefaultUpdateSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, f.loader.imageCount)
+	assert.Equal(t, 0, f.docker.PushCount)
+}
+
+func TestKINDPushRetriesTransientError(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvKIND)
+	defer f.TearDown()
+	f.setMaxRetries(2)
+	f.loader.errs = []error{fmt.Errorf("connection reset by peer")}
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, f.docker.BuildCount)
+	assert.Equal(t, 2, f.loader.imageCount)
+}
+
+func TestBuildKitAddrSelectsBuildKitBuilder(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	settings := model.DefaultUpdateSettings()
+	ib, _ := f.ibd.imageBuilder(settings)
+	_, isBuildKit := ib.(*build.BuildKitImageBuilder)
+	assert.False(t, isBuildKit)
+
+	settings.BuildKitAddr = "unix:///run/buildkit/buildkitd.sock"
+	ib, icb := f.ibd.imageBuilder(settings)
+	bkb, isBuildKit := ib.(*build.BuildKitImageBuilder)
+	if assert.True(t, isBuildKit) {
+		assert.Equal(t, build.BuildKitExportRegistry, bkb.Export())
+		assert.Equal(t, ib, icb.ib)
+	}
+}
+
+func TestBuildKitArchiveLoadedIntoKIND(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvKIND)
+	defer f.TearDown()
+	f.ibd.bkAddr = "unix:///run/buildkit/buildkitd.sock"
+
+	ib, _ := f.ibd.imageBuilder(model.DefaultUpdateSettings())
+	bkb := ib.(*build.BuildKitImageBuilder)
+	assert.Equal(t, build.BuildKitExportArchive, bkb.Export())
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	iTarget := manifest.ImageTargetAt(0)
+	ref := container.MustParseNamedTagged("gcr.io/some-project-162817/sancho:tilt-build-1546304461")
+	ps := build.NewPipelineState(f.ctx, 1, fakeClock{})
+	_, err := f.ibd.push(f.ctx, ib, ref, ps, iTarget, manifest.K8sTarget(), model.DefaultUpdateSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, f.loader.archiveCount)
+	assert.Equal(t, 0, f.loader.imageCount)
+	assert.Equal(t, 0, f.docker.PushCount)
+}
+
+func TestReuseUnchangedImageLoadsArchiveIntoKIND(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvKIND)
+	defer f.TearDown()
+	f.ibd.bkAddr = "unix:///run/buildkit/buildkitd.sock"
+
+	settings := model.DefaultUpdateSettings()
+	ib, _ := f.ibd.imageBuilder(settings)
+	bkb := ib.(*build.BuildKitImageBuilder)
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	iTarget := manifest.ImageTargetAt(0)
+	ref := container.MustParseNamedTagged("gcr.io/some-project-162817/sancho:tilt-build-1546304461")
+	path := bkb.ArchivePath(ref)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte("image"), 0644))
+	defer func() { _ = os.Remove(path) }()
+
+	// The cluster may have been re-created since we loaded the image,
+	// so we load it again even though we skip the build.
+	d := digest.Digest("sha256:11cd0b38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aab")
+	last := store.NewImageBuildResult(iTarget.ID(), ref).WithContextDigest(d)
+	ps := build.NewPipelineState(f.ctx, 1, fakeClock{})
+	result, ok, err := f.ibd.reuseUnchangedImage(f.ctx, ib, ps, iTarget, manifest.K8sTarget(), settings,
+		store.NewBuildState(last, nil), d)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, result.NoOp)
+	assert.Equal(t, 1, f.loader.archiveCount)
+}
+
+func TestReuseUnchangedImageLoadsImageIntoKIND(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvKIND)
+	defer f.TearDown()
+
+	settings := model.DefaultUpdateSettings()
+	ib, _ := f.ibd.imageBuilder(settings)
+	f.docker.ImageListCount = 1
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	iTarget := manifest.ImageTargetAt(0)
+	ref := container.MustParseNamedTagged("gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95")
+	d := digest.Digest("sha256:11cd0b38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aab")
+	last := store.NewImageBuildResult(iTarget.ID(), ref).WithContextDigest(d)
+	ps := build.NewPipelineState(f.ctx, 1, fakeClock{})
+	_, ok, err := f.ibd.reuseUnchangedImage(f.ctx, ib, ps, iTarget, manifest.K8sTarget(), settings,
+		store.NewBuildState(last, nil), d)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, f.loader.imageCount)
 	assert.Equal(t, 0, f.docker.PushCount)
 }
 
+func TestApplyGivesUpAfterMaxRetries(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+	f.setMaxRetries(1)
+	f.k8s.UpsertError = fmt.Errorf("kubectl apply:\nstderr: Unable to connect to the server: i/o timeout")
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Apply failed after 2 attempts")
+	}
+}
+
+func TestApplyDoesNotRetryPermanentError(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+	f.setMaxRetries(1)
+	f.k8s.UpsertError = fmt.Errorf("kubectl apply:\nstderr: forbidden")
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "attempts")
+	}
+}
+
+func TestConfirmChangesWaitsForApproval(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	f.setConfirmChanges(manifest, nil)
+	f.k8s.DiffResults = []k8s.EntityDiff{sanchoReplicasDiff()}
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	assert.True(t, IsAwaitingApprovalError(err), "expected AwaitingApprovalError, got %v", err)
+	assert.Equal(t, "", f.k8s.Yaml)
+
+	var pending []k8s.EntityDiff
+	for _, a := range f.st.Actions {
+		if a, ok := a.(K8sChangesPendingAction); ok {
+			pending = a.Changes
+		}
+	}
+	assert.Equal(t, []k8s.EntityDiff{sanchoReplicasDiff()}, pending)
+}
+
+func TestConfirmChangesAppliesApprovedChanges(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	f.setConfirmChanges(manifest, []k8s.EntityDiff{sanchoReplicasDiff()})
+	f.k8s.DiffResults = []k8s.EntityDiff{sanchoReplicasDiff()}
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, f.k8s.Yaml, "name: sancho")
+}
+
+func TestConfirmChangesAsksAgainWhenChangesDiffer(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	f.setConfirmChanges(manifest, []k8s.EntityDiff{sanchoReplicasDiff()})
+
+	// The user approved updating the Deployment in place, not re-creating it.
+	diff := sanchoReplicasDiff()
+	diff.Recreate = true
+	f.k8s.DiffResults = []k8s.EntityDiff{diff}
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	assert.True(t, IsAwaitingApprovalError(err), "expected AwaitingApprovalError, got %v", err)
+	assert.Equal(t, "", f.k8s.Yaml)
+}
+
+func TestConfirmChangesCreatesNewObjects(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	f.setConfirmChanges(manifest, nil)
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, f.k8s.Yaml, "name: sancho")
+}
+
+func TestChangesApproved(t *testing.T) {
+	approved := []k8s.EntityDiff{sanchoReplicasDiff()}
+	assert.True(t, changesApproved([]k8s.EntityDiff{sanchoReplicasDiff()}, approved))
+
+	// A rebuild gives the image a new tag, so the diff the user
+	// approved isn't exactly the diff we apply.
+	newTag := sanchoReplicasDiff()
+	newTag.Fields = append(newTag.Fields, k8s.FieldDiff{
+		Path: "spec.template.spec.containers[name=sancho].image",
+		Old:  `"gcr.io/some-project-162817/sancho:tilt-1"`,
+		New:  `"gcr.io/some-project-162817/sancho:tilt-2"`,
+	})
+	assert.True(t, changesApproved([]k8s.EntityDiff{newTag}, approved))
+
+	// Any other change to the diff needs approval again.
+	moreReplicas := sanchoReplicasDiff()
+	moreReplicas.Fields[0].New = "5"
+	assert.False(t, changesApproved([]k8s.EntityDiff{moreReplicas}, approved))
+
+	newField := sanchoReplicasDiff()
+	newField.Fields = append(newField.Fields, k8s.FieldDiff{
+		Path: "spec.template.spec.containers[name=sancho].args",
+		New:  `["--debug"]`,
+	})
+	assert.False(t, changesApproved([]k8s.EntityDiff{newField}, approved))
+
+	otherImage := sanchoReplicasDiff()
+	otherImage.Fields = append(otherImage.Fields, k8s.FieldDiff{
+		Path: "spec.template.spec.containers[name=redis].image",
+		Old:  `"redis:5"`,
+		New:  `"redis:6"`,
+	})
+	assert.False(t, changesApproved([]k8s.EntityDiff{otherImage}, approved))
+
+	recreated := sanchoReplicasDiff()
+	recreated.Recreate = true
+	assert.False(t, changesApproved([]k8s.EntityDiff{recreated}, approved))
+
+	otherNamespace := sanchoReplicasDiff()
+	otherNamespace.Namespace = "staging"
+	assert.False(t, changesApproved([]k8s.EntityDiff{otherNamespace}, approved))
+
+	other := k8s.EntityDiff{Kind: "Job", Name: "migrate", Recreate: true}
+	assert.False(t, changesApproved([]k8s.EntityDiff{newTag, other}, approved))
+}
+
+func TestChangesNeedingApproval(t *testing.T) {
+	created := k8s.EntityDiff{Kind: "Service", Name: "sancho", Create: true}
+	changed := sanchoReplicasDiff()
+	recreated := k8s.EntityDiff{Kind: "Job", Name: "migrate", Recreate: true}
+	unchanged := k8s.EntityDiff{Kind: "ConfigMap", Name: "config"}
+	diffs := []k8s.EntityDiff{created, changed, recreated, unchanged}
+
+	assert.Equal(t, []k8s.EntityDiff{changed, recreated}, changesNeedingApproval(diffs, true))
+
+	// If the YAML hasn't changed since our last deploy, only
+	// objects that get re-created need approval.
+	assert.Equal(t, []k8s.EntityDiff{recreated}, changesNeedingApproval(diffs, false))
+}
+
 func TestCustomBuildDisablePush(t *testing.T) {
 	f := newIBDFixture(t, k8s.EnvKIND)
 	defer f.TearDown()
@@ -385,7 +712,7 @@ func TestCustomBuildDisablePush(t *testing.T) {
 
 	// but we also didn't try to build or push an image
 	assert.Equal(t, 0, f.docker.BuildCount)
-	assert.Equal(t, 0, f.kp.pushCount)
+	assert.Equal(t, 0, f.loader.imageCount)
 	assert.Equal(t, 0, f.docker.PushCount)
 }
 
@@ -628,7 +955,7 @@ type ibdFixture struct {
 	k8s    *k8s.FakeK8sClient
 	ibd    *ImageBuildAndDeployer
 	st     *store.TestingStore
-	kp     *fakeKINDPusher
+	loader *fakeClusterImageLoader
 }
 
 func newIBDFixture(t *testing.T, env k8s.Env) *ibdFixture {
@@ -637,9 +964,9 @@ func newIBDFixture(t *testing.T, env k8s.Env) *ibdFixture {
 	docker := docker.NewFakeClient()
 	ctx, _, ta := testutils.CtxAndAnalyticsForTest()
 	kClient := k8s.NewFakeK8sClient()
-	kp := &fakeKINDPusher{}
+	loader := &fakeClusterImageLoader{}
 	clock := fakeClock{time.Date(2019, 1, 1, 1, 1, 1, 1, time.UTC)}
-	ibd, err := provideImageBuildAndDeployer(ctx, docker, kClient, env, dir, clock, kp, ta)
+	ibd, err := provideImageBuildAndDeployer(ctx, docker, kClient, env, dir, clock, loader, ta)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -650,7 +977,32 @@ func newIBDFixture(t *testing.T, env k8s.Env) *ibdFixture {
 		k8s:            kClient,
 		ibd:            ibd,
 		st:             store.NewTestingStore(),
-		kp:             kp,
+		loader:         loader,
+	}
+}
+
+func (f *ibdFixture) setMaxRetries(n int) {
+	state := store.NewState()
+	state.UpdateSettings.MaxRetries = n
+	state.UpdateSettings.RetryBackoff = time.Millisecond
+	f.st.SetState(*state)
+}
+
+func (f *ibdFixture) setConfirmChanges(m model.Manifest, approved []k8s.EntityDiff) {
+	state := store.NewState()
+	state.ConfirmChanges = true
+	mt := store.NewManifestTarget(m)
+	mt.State.ApprovedK8sChanges = approved
+	state.UpsertManifestTarget(mt)
+	f.st.SetState(*state)
+}
+
+func sanchoReplicasDiff() k8s.EntityDiff {
+	return k8s.EntityDiff{
+		Kind:      "Deployment",
+		Name:      "sancho",
+		Namespace: "default",
+		Fields:    []k8s.FieldDiff{{Path: "spec.replicas", Old: "3", New: "1"}},
 	}
 }
 
@@ -659,11 +1011,25 @@ func (f *ibdFixture) TearDown() {
 	f.TempDirFixture.TearDown()
 }
 
-type fakeKINDPusher struct {
-	pushCount int
+type fakeClusterImageLoader struct {
+	imageCount   int
+	archiveCount int
+
+	// Errors to return from the next image loads, in order.
+	errs []error
+}
+
+func (l *fakeClusterImageLoader) LoadImage(ctx context.Context, ref reference.NamedTagged, w io.Writer) error {
+	l.imageCount++
+	if len(l.errs) > 0 {
+		err := l.errs[0]
+		l.errs = l.errs[1:]
+		return err
+	}
+	return nil
 }
 
-func (kp *fakeKINDPusher) PushToKIND(ctx context.Context, ref reference.NamedTagged, w io.Writer) error {
-	kp.pushCount++
+func (l *fakeClusterImageLoader) LoadImageArchive(ctx context.Context, path string, w io.Writer) error {
+	l.archiveCount++
 	return nil
 }
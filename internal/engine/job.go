@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func handleJobChangeAction(ctx context.Context, state *store.EngineState, job *batchv1.Job) {
+	manifestName := model.ManifestName(job.ObjectMeta.Labels[k8s.ManifestNameLabel])
+	mt, ok := state.ManifestTargets[manifestName]
+	if !ok || mt.State.Disabled || !mt.Manifest.IsK8s() {
+		return
+	}
+
+	// Ignore Jobs from old deploys. We delete and re-create the Job
+	// on every deploy, so it's normal to see the old one go away.
+	ms := mt.State
+	deployID, err := strconv.Atoi(job.ObjectMeta.Labels[k8s.TiltDeployIDLabel])
+	if err != nil || model.DeployID(deployID) != ms.DeployID {
+		return
+	}
+
+	runtime := ms.GetOrCreateK8sRuntimeState()
+	if runtime.Jobs == nil {
+		runtime.Jobs = make(map[string]*store.Job)
+	}
+	for name, j := range runtime.Jobs {
+		if j.DeployID != ms.DeployID {
+			delete(runtime.Jobs, name)
+		}
+	}
+
+	old := runtime.Jobs[job.Name]
+	newJob := jobFromK8s(job, ms.DeployID)
+	runtime.Jobs[job.Name] = &newJob
+	ms.RuntimeState = runtime
+
+	if newJob.Finished() && (old == nil || !old.Finished()) {
+		if newJob.Status == store.JobStatusFailed {
+			logger.Get(ctx).Infof("Job %s failed: %s", newJob.Name, newJob.Message)
+		} else {
+			logger.Get(ctx).Infof("Job %s completed", newJob.Name)
+		}
+	}
+}
+
+// Convert a Kubernetes Job into a simpler Job model to store in the engine state.
+//
+// We use the Job's conditions rather than the phase of its pods, because a Job
+// may retry a failed pod, and only the Job knows when it's given up.
+func jobFromK8s(job *batchv1.Job, deployID model.DeployID) store.Job {
+	result := store.Job{
+		Name:      job.Name,
+		Namespace: k8s.Namespace(job.Namespace),
+		DeployID:  deployID,
+		Status:    store.JobStatusActive,
+		StartTime: timeOrZero(job.Status.StartTime),
+	}
+
+	for _, c := range job.Status.Conditions {
+		if c.Status != v1.ConditionTrue {
+			continue
+		}
+
+		switch c.Type {
+		case batchv1.JobComplete:
+			result.Status = store.JobStatusComplete
+			result.CompletionTime = timeOrZero(job.Status.CompletionTime)
+			if result.CompletionTime.IsZero() {
+				result.CompletionTime = c.LastTransitionTime.Time
+			}
+		case batchv1.JobFailed:
+			result.Status = store.JobStatusFailed
+			result.CompletionTime = c.LastTransitionTime.Time
+			result.Message = c.Message
+			if result.Message == "" {
+				result.Message = c.Reason
+			}
+		}
+	}
+	return result
+}
+
+func timeOrZero(t *metav1.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/manifestbuilder"
+	"github.com/windmilleng/tilt/internal/testutils/podbuilder"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestJobFromK8s(t *testing.T) {
+	start := time.Now()
+	finish := start.Add(time.Minute)
+
+	job := fakeJob("migrate", podbuilder.FakeDeployID)
+	job.Status.StartTime = &metav1.Time{Time: start}
+	assert.Equal(t, store.Job{
+		Name:      "migrate",
+		DeployID:  podbuilder.FakeDeployID,
+		Status:    store.JobStatusActive,
+		StartTime: start,
+	}, jobFromK8s(job, podbuilder.FakeDeployID))
+
+	complete := withJobCondition(job.DeepCopy(), batchv1.JobComplete, "", finish)
+	complete.Status.CompletionTime = &metav1.Time{Time: finish}
+	result := jobFromK8s(complete, podbuilder.FakeDeployID)
+	assert.Equal(t, store.JobStatusComplete, result.Status)
+	assert.Equal(t, finish, result.CompletionTime)
+
+	failed := withJobCondition(job.DeepCopy(), batchv1.JobFailed, "Job has reached the specified backoff limit", finish)
+	result = jobFromK8s(failed, podbuilder.FakeDeployID)
+	assert.Equal(t, store.JobStatusFailed, result.Status)
+	assert.Equal(t, "Job has reached the specified backoff limit", result.Message)
+	assert.Equal(t, finish, result.CompletionTime)
+}
+
+func TestJobStatusAndExitCode(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	m := f.newJobManifest("migrate")
+	f.Start([]model.Manifest{m}, true)
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+
+	job := fakeJob("migrate", podbuilder.FakeDeployID)
+	f.jobEvent(job)
+	f.WaitUntilHUDResource("job active", "migrate", func(res view.Resource) bool {
+		return res.K8sInfo().JobStatus == string(store.JobStatusActive)
+	})
+
+	pod := podbuilder.New(t, m).WithPhase(string(v1.PodFailed)).Build()
+	pod.Status.ContainerStatuses[0].State.Terminated = &v1.ContainerStateTerminated{ExitCode: 3}
+	f.podEvent(pod)
+
+	// The pod failed, but the Job hasn't given up on retries yet.
+	f.WaitUntilManifestState("pod exited", "migrate", func(ms store.ManifestState) bool {
+		_, ok := ms.MostRecentPod().ExitCode()
+		return ok
+	})
+	f.withManifestState("migrate", func(ms store.ManifestState) {
+		assert.Equal(t, "", crashReason(&store.ManifestTarget{Manifest: m, State: &ms}))
+	})
+
+	finish := time.Now()
+	f.jobEvent(withJobCondition(job.DeepCopy(), batchv1.JobFailed, "BackoffLimitExceeded", finish))
+	f.WaitUntilHUDResource("job failed", "migrate", func(res view.Resource) bool {
+		return res.K8sInfo().JobStatus == string(store.JobStatusFailed)
+	})
+
+	rv := f.hudResource("migrate")
+	k8sInfo := rv.K8sInfo()
+	assert.Equal(t, string(store.JobStatusFailed), k8sInfo.Status())
+	if assert.NotNil(t, k8sInfo.JobExitCode) {
+		assert.Equal(t, 3, *k8sInfo.JobExitCode)
+	}
+	assert.True(t, finish.Equal(k8sInfo.JobCompletionTime))
+	f.withManifestState("migrate", func(ms store.ManifestState) {
+		assert.Equal(t, "job migrate failed: BackoffLimitExceeded",
+			crashReason(&store.ManifestTarget{Manifest: m, State: &ms}))
+	})
+}
+
+func TestJobFromOldDeployIgnored(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	m := f.newJobManifest("migrate")
+	f.Start([]model.Manifest{m}, true)
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+
+	f.jobEvent(fakeJob("migrate", model.DeployID(111)))
+	f.jobEvent(fakeJob("migrate", podbuilder.FakeDeployID))
+	f.WaitUntilManifestState("job seen", "migrate", func(ms store.ManifestState) bool {
+		return !ms.K8sRuntimeState().CurrentJob().Empty()
+	})
+	f.withManifestState("migrate", func(ms store.ManifestState) {
+		jobs := ms.K8sRuntimeState().Jobs
+		if assert.Equal(t, 1, len(jobs)) {
+			assert.Equal(t, podbuilder.FakeDeployID, jobs["migrate"].DeployID)
+		}
+	})
+}
+
+// `tilt ci` doesn't watch files, but still needs to watch Jobs
+// to know when they've completed.
+func TestJobWatchedWithoutWatchingFiles(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	ciMode := func(ia InitAction) InitAction {
+		ia.EngineMode = store.EngineModeCI
+		return ia
+	}
+
+	m := f.newJobManifest("migrate")
+	f.Start([]model.Manifest{m}, false, ciMode)
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+
+	job := fakeJob("migrate", podbuilder.FakeDeployID)
+	job = withJobCondition(job, batchv1.JobComplete, "", time.Now())
+	f.kClient.EmitJob(k8s.TiltRunSelector(), job)
+	f.WaitUntilManifestState("job complete", "migrate", func(ms store.ManifestState) bool {
+		return ms.K8sRuntimeState().CurrentJob().Status == store.JobStatusComplete
+	})
+}
+
+func (f *testFixture) newJobManifest(name string) model.Manifest {
+	m := manifestbuilder.New(f, model.ManifestName(name)).
+		WithK8sYAML(testyaml.BlorgJobYAML).
+		Build()
+	kTarget := m.K8sTarget()
+	kTarget.HasPodTemplates = true
+	kTarget.HasJobs = true
+	return m.WithDeployTarget(kTarget)
+}
+
+func (f *testFixture) jobEvent(job *batchv1.Job) {
+	f.store.Dispatch(NewJobChangeAction(job))
+}
+
+func fakeJob(name string, deployID model.DeployID) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				k8s.ManifestNameLabel: name,
+				k8s.TiltDeployIDLabel: deployID.String(),
+			},
+		},
+	}
+}
+
+func withJobCondition(job *batchv1.Job, cType batchv1.JobConditionType, reason string, t time.Time) *batchv1.Job {
+	job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+		Type:               cType,
+		Status:             v1.ConditionTrue,
+		Reason:             reason,
+		LastTransitionTime: metav1.Time{Time: t},
+	})
+	return job
+}
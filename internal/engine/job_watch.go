@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// Watches the Jobs that Tilt deployed, so that we can report
+// whether they succeeded or failed.
+type JobWatcher struct {
+	kCli     k8s.Client
+	watching bool
+}
+
+func NewJobWatcher(kCli k8s.Client) *JobWatcher {
+	return &JobWatcher{
+		kCli: kCli,
+	}
+}
+
+func (w *JobWatcher) needsWatch(st store.RStore) bool {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	atLeastOneJob := false
+	for _, m := range state.Manifests() {
+		if m.IsK8s() && m.K8sTarget().HasJobs {
+			atLeastOneJob = true
+		}
+	}
+	return atLeastOneJob && !w.watching
+}
+
+func (w *JobWatcher) OnChange(ctx context.Context, st store.RStore) {
+	if !w.needsWatch(st) {
+		return
+	}
+	w.watching = true
+
+	ch, err := w.kCli.WatchJobs(ctx, []model.LabelPair{k8s.TiltRunLabel()})
+	if err != nil {
+		err = errors.Wrap(err, "Error watching jobs. Are you connected to kubernetes?\n")
+		st.Dispatch(NewErrorAction(err))
+		return
+	}
+
+	go w.dispatchJobChangesLoop(ctx, ch, st)
+}
+
+func (w *JobWatcher) dispatchJobChangesLoop(ctx context.Context, ch <-chan *batchv1.Job, st store.RStore) {
+	for {
+		select {
+		case job, ok := <-ch:
+			if !ok {
+				return
+			}
+			st.Dispatch(NewJobChangeAction(job))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+var _ store.Subscriber = &JobWatcher{}
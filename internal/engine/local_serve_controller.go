@@ -0,0 +1,219 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/model"
+	"github.com/windmilleng/tilt/pkg/procutil"
+)
+
+// Runs the serve_cmd of local resources.
+//
+// Starts the process after the resource's first successful build,
+// restarts it after every subsequent successful build, and kills
+// its whole process group when Tilt exits.
+type LocalServeController struct {
+	procs map[model.ManifestName]*localServeProc
+}
+
+func NewLocalServeController() *LocalServeController {
+	return &LocalServeController{
+		procs: make(map[model.ManifestName]*localServeProc),
+	}
+}
+
+// Diff the currently running processes against the set of local resources,
+// i.e., what we SHOULD be running, returning the changes we need to make.
+func (c *LocalServeController) diff(ctx context.Context, st store.RStore) (toStart []*localServeProc, toStop []*localServeProc) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	seen := make(map[model.ManifestName]bool)
+	for _, mt := range state.Targets() {
+		manifest := mt.Manifest
+		if !manifest.IsLocal() {
+			continue
+		}
+
+		lt := manifest.LocalTarget()
+		if lt.ServeCmd.Empty() || mt.State.Disabled {
+			continue
+		}
+
+		// If the latest build failed or was superseded, leave the old process running (if any),
+		// the same way we leave the old pod running on a failed image build.
+		lastBuild := mt.State.LastBuild()
+		if lastBuild.Empty() || lastBuild.Error != nil || lastBuild.Superseded {
+			if _, ok := c.procs[manifest.Name]; ok {
+				seen[manifest.Name] = true
+			}
+			continue
+		}
+
+		seen[manifest.Name] = true
+
+		existing, ok := c.procs[manifest.Name]
+		if ok {
+			if existing.buildStartTime.Equal(lastBuild.StartTime) &&
+				existing.cmd.String() == lt.ServeCmd.String() &&
+				existing.workdir == lt.Workdir {
+				continue
+			}
+			toStop = append(toStop, existing)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		proc := &localServeProc{
+			ctx:            ctx,
+			cancel:         cancel,
+			name:           manifest.Name,
+			cmd:            lt.ServeCmd,
+			workdir:        lt.Workdir,
+			buildStartTime: lastBuild.StartTime,
+			done:           make(chan struct{}),
+		}
+		c.procs[manifest.Name] = proc
+		toStart = append(toStart, proc)
+	}
+
+	for name, proc := range c.procs {
+		if !seen[name] {
+			toStop = append(toStop, proc)
+			delete(c.procs, name)
+		}
+	}
+
+	return toStart, toStop
+}
+
+func (c *LocalServeController) OnChange(ctx context.Context, st store.RStore) {
+	toStart, toStop := c.diff(ctx, st)
+	for _, proc := range toStop {
+		proc.stop()
+	}
+
+	for _, proc := range toStart {
+		c.start(proc, st)
+	}
+}
+
+// Kill all the processes we started before Tilt exits.
+func (c *LocalServeController) TearDown(ctx context.Context) {
+	for name, proc := range c.procs {
+		proc.stop()
+		delete(c.procs, name)
+	}
+}
+
+func (c *LocalServeController) start(proc *localServeProc, st store.RStore) {
+	cmd := exec.Command(proc.cmd.Argv[0], proc.cmd.Argv[1:]...)
+	cmd.Dir = proc.workdir
+	cmd.SysProcAttr = &syscall.SysProcAttr{}
+	procutil.SetOptNewProcessGroup(cmd.SysProcAttr)
+
+	// Funnel the output through a pipe, so that we don't read any logs
+	// until we know the PID they belong to.
+	r, w := io.Pipe()
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	err := cmd.Start()
+	if err != nil {
+		close(proc.done)
+		logger.Get(proc.ctx).Infof("Error starting %s: %v", proc.name, err)
+		st.Dispatch(LocalServeStatusAction{
+			ManifestName: proc.name,
+			Status:       store.LocalServeStatusCrashed,
+		})
+		return
+	}
+
+	pid := cmd.Process.Pid
+	st.Dispatch(LocalServeStatusAction{
+		ManifestName: proc.name,
+		Status:       store.LocalServeStatusRunning,
+		PID:          pid,
+		StartTime:    time.Now(),
+	})
+
+	actionWriter := LocalServeLogActionWriter{
+		store:        st,
+		manifestName: proc.name,
+		pid:          pid,
+	}
+	go func() {
+		_, _ = io.Copy(actionWriter, r)
+	}()
+
+	go func() {
+		select {
+		case <-proc.ctx.Done():
+			procutil.KillProcessGroup(cmd)
+		case <-proc.done:
+		}
+	}()
+
+	go func() {
+		err := cmd.Wait()
+		_ = w.Close()
+		close(proc.done)
+
+		if proc.ctx.Err() != nil {
+			// We killed the process ourselves, so don't report it.
+			return
+		}
+
+		status := store.LocalServeStatusExited
+		if err != nil {
+			status = store.LocalServeStatusCrashed
+			_, _ = fmt.Fprintf(actionWriter, "%s exited: %v\n", proc.cmd, err)
+		}
+		st.Dispatch(LocalServeStatusAction{
+			ManifestName: proc.name,
+			Status:       status,
+			PID:          pid,
+		})
+	}()
+}
+
+type localServeProc struct {
+	ctx            context.Context
+	cancel         func()
+	name           model.ManifestName
+	cmd            model.Cmd
+	workdir        string
+	buildStartTime time.Time
+
+	// Closed when the process exits.
+	done chan struct{}
+}
+
+// Kill the process group and wait for the process to exit.
+func (p *localServeProc) stop() {
+	p.cancel()
+	<-p.done
+}
+
+type LocalServeLogActionWriter struct {
+	store        store.RStore
+	manifestName model.ManifestName
+	pid          int
+}
+
+func (w LocalServeLogActionWriter) Write(p []byte) (n int, err error) {
+	w.store.Dispatch(LocalServeLogAction{
+		LogEvent: store.NewLogEvent(w.manifestName, p),
+		PID:      w.pid,
+	})
+	return len(p), nil
+}
+
+var _ store.Subscriber = &LocalServeController{}
+var _ store.TearDowner = &LocalServeController{}
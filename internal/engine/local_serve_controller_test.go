@@ -0,0 +1,194 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestLocalServeStartsAfterBuild(t *testing.T) {
+	f := newLSCFixture(t)
+	defer f.TearDown()
+
+	f.upsertServe("web", "echo hello && sleep 60")
+	f.lsc.OnChange(f.ctx, f.st)
+	assert.Equal(t, 0, len(f.lsc.procs))
+
+	f.completeBuild("web", time.Now())
+	f.lsc.OnChange(f.ctx, f.st)
+	assert.Equal(t, 1, len(f.lsc.procs))
+
+	action := f.waitForStatus(store.LocalServeStatusRunning)
+	assert.Equal(t, model.ManifestName("web"), action.ManifestName)
+	assert.NotEqual(t, 0, action.PID)
+	f.waitForLog("hello")
+}
+
+func TestLocalServeRestartsAfterRebuild(t *testing.T) {
+	f := newLSCFixture(t)
+	defer f.TearDown()
+
+	f.upsertServe("web", "sleep 60")
+	f.completeBuild("web", time.Now())
+	f.lsc.OnChange(f.ctx, f.st)
+	first := f.waitForStatus(store.LocalServeStatusRunning)
+
+	f.completeBuild("web", time.Now().Add(time.Second))
+	f.lsc.OnChange(f.ctx, f.st)
+
+	second := f.waitForStatusMatching(func(a LocalServeStatusAction) bool {
+		return a.Status == store.LocalServeStatusRunning && a.PID != first.PID
+	})
+	assert.NotEqual(t, 0, second.PID)
+	assert.Equal(t, 1, len(f.lsc.procs))
+
+	// We killed the old process ourselves, so it shouldn't be reported as crashed.
+	for _, a := range f.statusActions() {
+		assert.NotEqual(t, store.LocalServeStatusCrashed, a.Status)
+	}
+}
+
+func TestLocalServeCrash(t *testing.T) {
+	f := newLSCFixture(t)
+	defer f.TearDown()
+
+	f.upsertServe("web", "echo oh no && exit 1")
+	f.completeBuild("web", time.Now())
+	f.lsc.OnChange(f.ctx, f.st)
+
+	f.waitForStatus(store.LocalServeStatusCrashed)
+	f.waitForLog("exit status 1")
+}
+
+func TestLocalServeTearDown(t *testing.T) {
+	f := newLSCFixture(t)
+	defer f.TearDown()
+
+	f.upsertServe("web", "sleep 60")
+	f.completeBuild("web", time.Now())
+	f.lsc.OnChange(f.ctx, f.st)
+	f.waitForStatus(store.LocalServeStatusRunning)
+
+	proc := f.lsc.procs["web"]
+	f.lsc.TearDown(f.ctx)
+	assert.Equal(t, 0, len(f.lsc.procs))
+
+	select {
+	case <-proc.done:
+	default:
+		t.Fatal("Expected process to be killed on TearDown")
+	}
+}
+
+type lscFixture struct {
+	*tempdir.TempDirFixture
+	ctx        context.Context
+	cancel     func()
+	st         *store.Store
+	getActions func() []store.Action
+	lsc        *LocalServeController
+}
+
+func newLSCFixture(t *testing.T) *lscFixture {
+	f := tempdir.NewTempDirFixture(t)
+	st, getActions := store.NewStoreForTesting()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Keep the store loop running until we cancel it.
+	state := st.LockMutableStateForTesting()
+	state.WatchFiles = true
+	st.UnlockMutableState()
+
+	go func() {
+		_ = st.Loop(ctx)
+	}()
+
+	return &lscFixture{
+		TempDirFixture: f,
+		ctx:            ctx,
+		cancel:         cancel,
+		st:             st,
+		getActions:     getActions,
+		lsc:            NewLocalServeController(),
+	}
+}
+
+func (f *lscFixture) upsertServe(name string, serveCmd string) {
+	lt := model.NewLocalTarget(model.TargetName(name), model.Cmd{}, f.Path(), nil).
+		WithServeCmd(model.ToShellCmd(serveCmd))
+	m := model.Manifest{Name: model.ManifestName(name)}.WithDeployTarget(lt)
+
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	f.st.UnlockMutableState()
+}
+
+func (f *lscFixture) completeBuild(name string, startTime time.Time) {
+	state := f.st.LockMutableStateForTesting()
+	ms := state.ManifestTargets[model.ManifestName(name)].State
+	ms.BuildHistory = append([]model.BuildRecord{{
+		StartTime:  startTime,
+		FinishTime: startTime,
+	}}, ms.BuildHistory...)
+	f.st.UnlockMutableState()
+}
+
+func (f *lscFixture) statusActions() []LocalServeStatusAction {
+	var result []LocalServeStatusAction
+	for _, a := range f.getActions() {
+		if a, ok := a.(LocalServeStatusAction); ok {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func (f *lscFixture) waitForStatus(status store.LocalServeStatus) LocalServeStatusAction {
+	return f.waitForStatusMatching(func(a LocalServeStatusAction) bool {
+		return a.Status == status
+	})
+}
+
+func (f *lscFixture) waitForStatusMatching(matches func(a LocalServeStatusAction) bool) LocalServeStatusAction {
+	start := time.Now()
+	for time.Since(start) < time.Second {
+		for _, a := range f.statusActions() {
+			if matches(a) {
+				return a
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	f.T().Fatalf("timed out waiting for status action. Saw: %+v", f.statusActions())
+	return LocalServeStatusAction{}
+}
+
+func (f *lscFixture) waitForLog(s string) {
+	start := time.Now()
+	for time.Since(start) < time.Second {
+		var log strings.Builder
+		for _, a := range f.getActions() {
+			if la, ok := a.(LocalServeLogAction); ok {
+				log.Write(la.Message())
+			}
+		}
+		if strings.Contains(log.String(), s) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	f.T().Fatalf("timed out waiting for log %q", s)
+}
+
+func (f *lscFixture) TearDown() {
+	f.lsc.TearDown(f.ctx)
+	f.cancel()
+	f.TempDirFixture.TearDown()
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/windmilleng/tilt/internal/build"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/model"
+	"github.com/windmilleng/tilt/pkg/procutil"
+)
+
+var _ BuildAndDeployer = &LocalTargetBuildAndDeployer{}
+
+// LocalTargetBuildAndDeployer runs the command of a LocalTarget on the host machine.
+type LocalTargetBuildAndDeployer struct {
+	clock build.Clock
+}
+
+func NewLocalTargetBuildAndDeployer(c build.Clock) *LocalTargetBuildAndDeployer {
+	return &LocalTargetBuildAndDeployer{
+		clock: c,
+	}
+}
+
+func (bd *LocalTargetBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.RStore, specs []model.TargetSpec, stateSet store.BuildStateSet) (resultSet store.BuildResultSet, err error) {
+	targets := bd.extract(specs)
+	if len(targets) != 1 {
+		return store.BuildResultSet{}, SilentRedirectToNextBuilderf(
+			"LocalTargetBuildAndDeployer requires exactly one LocalTarget (got %d)", len(targets))
+	}
+
+	targ := targets[0]
+	span, ctx := opentracing.StartSpanFromContext(ctx, "LocalTargetBuildAndDeployer-BuildAndDeploy")
+	span.SetTag("target", targ.Name)
+	defer span.Finish()
+
+	// Serve-only targets have nothing to run here; the LocalServeController
+	// (re)starts their process once the build completes.
+	if !targ.Cmd.Empty() {
+		ps := build.NewPipelineState(ctx, 1, bd.clock)
+		defer func() { ps.End(ctx, err) }()
+
+		ps.StartPipelineStep(ctx, "Running command: %s", targ.Cmd)
+		err = bd.run(ctx, targ.Cmd, targ.Workdir)
+		ps.EndPipelineStep(ctx)
+		if err != nil {
+			// (Never fall back from the LocalTargetBaD, none of our other BaDs can handle this target)
+			return store.BuildResultSet{}, DontFallBackErrorf("Command %q failed: %v", targ.Cmd.String(), err)
+		}
+	}
+
+	return store.BuildResultSet{targ.ID(): store.NewLocalBuildResult(targ.ID())}, nil
+}
+
+func (bd *LocalTargetBuildAndDeployer) extract(specs []model.TargetSpec) []model.LocalTarget {
+	var targs []model.LocalTarget
+	for _, s := range specs {
+		switch s := s.(type) {
+		case model.LocalTarget:
+			targs = append(targs, s)
+		default:
+			// unrecognized target
+			return nil
+		}
+	}
+	return targs
+}
+
+func (bd *LocalTargetBuildAndDeployer) run(ctx context.Context, c model.Cmd, workdir string) error {
+	if c.Empty() {
+		return fmt.Errorf("missing command")
+	}
+
+	cmd := exec.Command(c.Argv[0], c.Argv[1:]...)
+	cmd.Dir = workdir
+
+	w := logger.Get(ctx).Writer(logger.InfoLvl)
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	return procutil.Run(ctx, cmd)
+}
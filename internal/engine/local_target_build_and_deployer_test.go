@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils"
+	"github.com/windmilleng/tilt/internal/testutils/manifestbuilder"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestLocalTargetBuildAndDeploy(t *testing.T) {
+	f := newLTFixture(t)
+	defer f.TearDown()
+
+	manifest := manifestbuilder.New(f, "codegen").
+		WithLocalResource("echo hello > hello.txt", []string{f.Path()}).
+		Build()
+	targ := manifest.LocalTarget()
+
+	res, err := f.ltbad.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+
+	assert.Equal(t, targ.ID(), res[targ.ID()].TargetID)
+	contents, err := ioutil.ReadFile(f.JoinPath("hello.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(contents))
+}
+
+func TestLocalTargetBuildAndDeployFailure(t *testing.T) {
+	f := newLTFixture(t)
+	defer f.TearDown()
+
+	manifest := manifestbuilder.New(f, "codegen").
+		WithLocalResource("echo oh no && exit 1", nil).
+		Build()
+
+	_, err := f.ltbad.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if assert.Error(t, err) {
+		assert.True(t, IsDontFallBackError(err))
+		assert.Contains(t, err.Error(), "exit status 1")
+	}
+	assert.Contains(t, f.out.String(), "oh no")
+}
+
+func TestLocalTargetBuildAndDeployRedirectsOtherTargets(t *testing.T) {
+	f := newLTFixture(t)
+	defer f.TearDown()
+
+	manifest := manifestbuilder.New(f, "fe").WithK8sYAML(SanchoYAML).Build()
+
+	_, err := f.ltbad.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if assert.Error(t, err) {
+		_, ok := err.(RedirectToNextBuilder)
+		assert.True(t, ok)
+	}
+}
+
+type ltFixture struct {
+	*tempdir.TempDirFixture
+	ctx   context.Context
+	out   *bytes.Buffer
+	ltbad *LocalTargetBuildAndDeployer
+	st    *store.Store
+}
+
+func newLTFixture(t *testing.T) *ltFixture {
+	out := new(bytes.Buffer)
+	ctx, _, _ := testutils.ForkedCtxAndAnalyticsForTest(out)
+	f := tempdir.NewTempDirFixture(t)
+	st, _ := store.NewStoreForTesting()
+	return &ltFixture{
+		TempDirFixture: f,
+		ctx:            ctx,
+		out:            out,
+		ltbad:          NewLocalTargetBuildAndDeployer(fakeClock{}),
+		st:             st,
+	}
+}
@@ -35,6 +35,7 @@ func handlePodChangeAction(ctx context.Context, state *store.EngineState, pod *v
 	podInfo.Deleting = pod.DeletionTimestamp != nil && !pod.DeletionTimestamp.IsZero()
 	podInfo.Phase = pod.Status.Phase
 	podInfo.Status = podStatusToString(*pod)
+	podInfo.Ready = isPodReady(*pod)
 	podInfo.StatusMessages = podStatusErrorMessages(*pod)
 
 	prunePods(ms)
@@ -87,6 +88,11 @@ func ensureManifestTargetWithPod(state *store.EngineState, pod *v1.Pod) (*store.
 		return nil, nil
 	}
 
+	if mt.State.Disabled {
+		// The pods of a disabled manifest are being deleted; don't track them.
+		return nil, nil
+	}
+
 	ms := mt.State
 
 	deployID := ms.DeployID
@@ -179,14 +185,19 @@ func containerForStatus(ctx context.Context, pod *v1.Pod, cStatus v1.ContainerSt
 		ports = append(ports, cPort.ContainerPort)
 	}
 
-	return store.Container{
+	c := store.Container{
 		Name:     cName,
 		ID:       cID,
 		Ports:    ports,
 		Ready:    cStatus.Ready,
 		ImageRef: cRef,
 		Restarts: int(cStatus.RestartCount),
-	}, nil
+	}
+	if terminated := cStatus.State.Terminated; terminated != nil {
+		c.Terminated = true
+		c.ExitCode = int(terminated.ExitCode)
+	}
+	return c, nil
 }
 
 func checkForContainerCrash(ctx context.Context, state *store.EngineState, mt *store.ManifestTarget) {
@@ -220,6 +220,17 @@ func containerStatusErrorMessages(container v1.ContainerStatus) []string {
 	return result
 }
 
+// Whether the pod's Ready condition is true. Kubernetes sets it once all
+// the containers pass their readiness probes.
+func isPodReady(pod v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
 func isPodStillInitializing(pod v1.Pod) bool {
 	for _, container := range pod.Status.InitContainerStatuses {
 		state := container.State
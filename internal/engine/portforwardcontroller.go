@@ -2,6 +2,7 @@ package engine
 
 import (
 	"context"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 
@@ -11,16 +12,28 @@ import (
 	"github.com/windmilleng/tilt/pkg/model"
 )
 
+// When a port-forward breaks, we wait this long before reconnecting,
+// doubling the wait after each failed attempt.
+const portForwardBackoff = time.Second
+const portForwardMaxBackoff = 30 * time.Second
+
+// After this many failed attempts in a row, we report the port-forward as failed.
+// We keep trying in the background, in case the pod comes back.
+const portForwardMaxAttempts = 5
+
 type PortForwardController struct {
 	kClient k8s.Client
 
 	activeForwards map[k8s.PodID]portForwardEntry
+
+	backoff time.Duration
 }
 
 func NewPortForwardController(kClient k8s.Client) *PortForwardController {
 	return &PortForwardController{
 		kClient:        kClient,
 		activeForwards: make(map[k8s.PodID]portForwardEntry),
+		backoff:        portForwardBackoff,
 	}
 }
 
@@ -92,27 +105,93 @@ func (m *PortForwardController) OnChange(ctx context.Context, st store.RStore) {
 	toStart, toShutdown := m.diff(ctx, st)
 	for _, entry := range toShutdown {
 		entry.cancel()
+		st.Dispatch(PortForwardsStoppedAction{ManifestName: entry.name, PodID: entry.podID})
 	}
 
 	for _, entry := range toStart {
-		entry := entry
-		ns := entry.namespace
-		podID := entry.podID
 		for _, forward := range entry.forwards {
 			// TODO(nick): Handle the case where DockerForDesktop is handling
 			// the port-forwarding natively already
-			_, closer, err := m.kClient.ForwardPort(ctx, ns, podID, forward.LocalPort, forward.ContainerPort)
+			tunnel, err := m.kClient.ForwardPort(entry.ctx, entry.namespace, entry.podID, forward.LocalPort, forward.ContainerPort)
 			if err != nil {
 				logger.Get(ctx).Infof("Error port-forwarding %s: %v", entry.name, err)
-				continue
 			}
+			go m.keepAlive(st, entry, forward, tunnel, err)
+		}
+	}
+}
+
+// Watches a port-forward until its pod goes away, and reconnects with
+// backoff whenever it breaks (e.g., because the pod restarted, or the
+// laptop went to sleep).
+func (m *PortForwardController) keepAlive(st store.RStore, entry portForwardEntry, forward model.PortForward,
+	tunnel k8s.PortForwardTunnel, err error) {
+	ctx := entry.ctx
+	failures := 0
+	for {
+		if err == nil {
+			if failures > 0 {
+				logger.Get(ctx).Infof("Reconnected port-forward to %s (localhost:%d)", entry.name, forward.LocalPort)
+			}
+			failures = 0
+			m.dispatchStatus(st, entry, forward, store.PortForwardStatusActive, nil)
+
+			select {
+			case <-ctx.Done():
+				tunnel.Close()
+				return
+			case err = <-tunnel.Done:
+			}
+			logger.Get(ctx).Infof("Port-forward to %s (localhost:%d) broke: %v. Reconnecting...",
+				entry.name, forward.LocalPort, err)
+		} else {
+			failures++
+		}
+
+		status := store.PortForwardStatusReconnecting
+		if failures >= portForwardMaxAttempts {
+			status = store.PortForwardStatusFailed
+			if failures == portForwardMaxAttempts {
+				logger.Get(ctx).Infof("Unable to reconnect port-forward to %s (localhost:%d): %v",
+					entry.name, forward.LocalPort, err)
+			}
+		}
+		m.dispatchStatus(st, entry, forward, status, err)
 
-			go func() {
-				<-entry.ctx.Done()
-				closer()
-			}()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(m.retryDelay(failures)):
 		}
+
+		tunnel, err = m.kClient.ForwardPort(ctx, entry.namespace, entry.podID, forward.LocalPort, forward.ContainerPort)
+	}
+}
+
+func (m *PortForwardController) dispatchStatus(st store.RStore, entry portForwardEntry, forward model.PortForward,
+	status store.PortForwardStatus, err error) {
+	// Once the forward is shut down, its state is gone from the store.
+	if entry.ctx.Err() != nil {
+		return
+	}
+	st.Dispatch(PortForwardStatusAction{
+		ManifestName: entry.name,
+		PodID:        entry.podID,
+		Forward:      forward,
+		Status:       status,
+		Error:        err,
+	})
+}
+
+func (m *PortForwardController) retryDelay(failures int) time.Duration {
+	delay := m.backoff
+	for i := 0; i < failures && delay < portForwardMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > portForwardMaxBackoff {
+		delay = portForwardMaxBackoff
 	}
+	return delay
 }
 
 var _ store.Subscriber = &PortForwardController{}
@@ -2,7 +2,11 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"io/ioutil"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
@@ -10,6 +14,7 @@ import (
 	"github.com/windmilleng/tilt/internal/k8s"
 	"github.com/windmilleng/tilt/internal/store"
 	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+	"github.com/windmilleng/tilt/pkg/logger"
 	"github.com/windmilleng/tilt/pkg/model"
 )
 
@@ -92,29 +97,165 @@ func TestPortForwardAutoDiscovery(t *testing.T) {
 	assert.Equal(t, 8000, f.kCli.LastForwardPortRemotePort)
 }
 
+func TestPortForwardReconnects(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	f.setUpForward("pod-id")
+	f.plc.OnChange(f.ctx, f.st)
+	f.waitForStatus(store.PortForwardStatusActive)
+
+	f.kCli.BreakPortForwards(fmt.Errorf("lost connection to pod"))
+	action := f.waitForStatus(store.PortForwardStatusReconnecting)
+	assert.Equal(t, "lost connection to pod", action.Error.Error())
+	assert.Equal(t, k8s.PodID("pod-id"), action.PodID)
+	assert.Equal(t, 8080, action.Forward.LocalPort)
+
+	f.waitForStatus(store.PortForwardStatusActive)
+	assert.Equal(t, 2, f.kCli.GetForwardPortCount())
+}
+
+func TestPortForwardFailsAfterMaxAttempts(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	f.kCli.SetForwardPortError(fmt.Errorf("pod not found"))
+	f.setUpForward("pod-id")
+	f.plc.OnChange(f.ctx, f.st)
+
+	action := f.waitForStatus(store.PortForwardStatusFailed)
+	assert.Equal(t, "pod not found", action.Error.Error())
+	assert.True(t, f.kCli.GetForwardPortCount() >= portForwardMaxAttempts)
+
+	// We keep trying, and recover when the pod comes back.
+	f.kCli.SetForwardPortError(nil)
+	f.waitForStatus(store.PortForwardStatusActive)
+}
+
+func TestPortForwardStopped(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	f.setUpForward("pod-id")
+	f.plc.OnChange(f.ctx, f.st)
+	f.waitForStatus(store.PortForwardStatusActive)
+
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["fe"].State.RuntimeState = store.NewK8sRuntimeState(0, store.Pod{PodID: "pod-id", Phase: v1.PodPending})
+	f.st.UnlockMutableState()
+
+	f.plc.OnChange(f.ctx, f.st)
+	a := store.WaitForAction(t, reflect.TypeOf(PortForwardsStoppedAction{}), f.getActions)
+	assert.Equal(t, PortForwardsStoppedAction{ManifestName: "fe", PodID: "pod-id"}, a)
+}
+
+func TestCurrentPortForwards(t *testing.T) {
+	state := store.NewState()
+	m := model.Manifest{Name: "fe"}.WithDeployTarget(model.K8sTarget{})
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	ms := state.ManifestTargets["fe"].State
+	ms.RuntimeState = store.NewK8sRuntimeState(0, store.Pod{PodID: "pod-id", Phase: v1.PodRunning})
+
+	handlePortForwardStatusAction(state, PortForwardStatusAction{
+		ManifestName: "fe",
+		PodID:        "old-pod-id",
+		Forward:      model.PortForward{LocalPort: 8080},
+		Status:       store.PortForwardStatusFailed,
+	})
+	assert.Empty(t, ms.CurrentPortForwards())
+
+	handlePortForwardStatusAction(state, PortForwardStatusAction{
+		ManifestName: "fe",
+		PodID:        "pod-id",
+		Forward:      model.PortForward{LocalPort: 9000},
+		Status:       store.PortForwardStatusReconnecting,
+		Error:        fmt.Errorf("lost connection to pod"),
+	})
+	handlePortForwardStatusAction(state, PortForwardStatusAction{
+		ManifestName: "fe",
+		PodID:        "pod-id",
+		Forward:      model.PortForward{LocalPort: 8080},
+		Status:       store.PortForwardStatusActive,
+	})
+	assert.Equal(t, []store.PortForwardState{
+		{Forward: model.PortForward{LocalPort: 8080}, PodID: "pod-id", Status: store.PortForwardStatusActive},
+		{Forward: model.PortForward{LocalPort: 9000}, PodID: "pod-id", Status: store.PortForwardStatusReconnecting, Error: "lost connection to pod"},
+	}, ms.CurrentPortForwards())
+
+	handlePortForwardsStoppedAction(state, PortForwardsStoppedAction{ManifestName: "fe", PodID: "pod-id"})
+	assert.Empty(t, ms.CurrentPortForwards())
+}
+
 type plcFixture struct {
 	*tempdir.TempDirFixture
-	ctx  context.Context
-	kCli *k8s.FakeK8sClient
-	st   *store.Store
-	plc  *PortForwardController
+	ctx        context.Context
+	cancel     func()
+	kCli       *k8s.FakeK8sClient
+	st         *store.Store
+	getActions func() []store.Action
+	plc        *PortForwardController
+
+	// Actions before this index have been seen already.
+	seenActions int
 }
 
 func newPLCFixture(t *testing.T) *plcFixture {
 	f := tempdir.NewTempDirFixture(t)
-	st, _ := store.NewStoreForTesting()
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.DebugLvl, ioutil.Discard))
+	ctx, cancel := context.WithCancel(ctx)
+	st, getActions := store.NewStoreForTesting()
+	go func() {
+		_ = st.Loop(ctx)
+	}()
+
 	kCli := k8s.NewFakeK8sClient()
 	plc := NewPortForwardController(kCli)
+	plc.backoff = time.Millisecond
 	return &plcFixture{
 		TempDirFixture: f,
-		ctx:            context.Background(),
+		ctx:            ctx,
+		cancel:         cancel,
 		st:             st,
+		getActions:     getActions,
 		kCli:           kCli,
 		plc:            plc,
 	}
 }
 
+func (f *plcFixture) setUpForward(podID k8s.PodID) {
+	state := f.st.LockMutableStateForTesting()
+	// Keep the store loop running after the manifest is added.
+	state.WatchFiles = true
+	m := model.Manifest{Name: "fe"}.WithDeployTarget(model.K8sTarget{
+		PortForwards: []model.PortForward{{LocalPort: 8080, ContainerPort: 8081}},
+	})
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	state.ManifestTargets["fe"].State.RuntimeState = store.NewK8sRuntimeState(0, store.Pod{PodID: podID, Phase: v1.PodRunning})
+	f.st.UnlockMutableState()
+}
+
+// Waits for a port-forward status action that we haven't seen yet.
+func (f *plcFixture) waitForStatus(status store.PortForwardStatus) PortForwardStatusAction {
+	timeout := time.After(time.Second)
+	for {
+		actions := f.getActions()
+		for i := f.seenActions; i < len(actions); i++ {
+			if a, ok := actions[i].(PortForwardStatusAction); ok && a.Status == status {
+				f.seenActions = i + 1
+				return a
+			}
+		}
+
+		select {
+		case <-timeout:
+			f.T().Fatalf("Timed out waiting for port-forward status %q. Actions: %v", status, actions)
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
 func (f *plcFixture) TearDown() {
+	f.cancel()
 	f.kCli.TearDown()
 	f.TempDirFixture.TearDown()
 }
@@ -0,0 +1,206 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// Kinds that we always look for when pruning, even if they're not in the
+// current Tiltfile (e.g., because the last one was removed while Tilt was down).
+var defaultPruneKinds = []schema.GroupVersionKind{
+	{Version: "v1", Kind: "ConfigMap"},
+	{Version: "v1", Kind: "Pod"},
+	{Version: "v1", Kind: "Secret"},
+	{Version: "v1", Kind: "Service"},
+	{Version: "v1", Kind: "ServiceAccount"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "extensions", Version: "v1beta1", Kind: "Ingress"},
+}
+
+// Deletes k8s objects that we deployed from this Tiltfile, but that aren't
+// in any manifest anymore (e.g., because they were removed from the YAML,
+// or their resource was renamed).
+//
+// Runs after each successful Tiltfile load.
+type PruneController struct {
+	kCli k8s.Client
+
+	// Where kubectl puts objects that don't specify a namespace.
+	defaultNs k8s.Namespace
+
+	// Where the k8s client puts every namespaced object, if set.
+	nsOverride k8s.NamespaceOverride
+
+	// The finish time of the last Tiltfile load that we looked at.
+	lastLoad time.Time
+
+	// Every kind that we've seen in a manifest, so that we still look for
+	// objects of that kind after it disappears from the Tiltfile.
+	kinds map[schema.GroupVersionKind]bool
+}
+
+func NewPruneController(kCli k8s.Client, ns k8s.Namespace, nsOverride k8s.NamespaceOverride) *PruneController {
+	kinds := make(map[schema.GroupVersionKind]bool)
+	for _, gvk := range defaultPruneKinds {
+		kinds[gvk] = true
+	}
+	if ns == "" {
+		ns = k8s.DefaultNamespace
+	}
+	return &PruneController{
+		kCli:       kCli,
+		defaultNs:  ns,
+		nsOverride: nsOverride,
+		kinds:      kinds,
+	}
+}
+
+func (c *PruneController) OnChange(ctx context.Context, st store.RStore) {
+	tiltfilePath, manifests, ok := c.needsPrune(ctx, st)
+	if !ok {
+		return
+	}
+
+	err := c.prune(ctx, tiltfilePath, manifests)
+	if err != nil {
+		logger.Get(ctx).Infof("Error pruning k8s objects: %v", err)
+	}
+}
+
+// Returns the current manifests if there's been a Tiltfile load
+// since the last time we checked, and we should prune after it.
+func (c *PruneController) needsPrune(ctx context.Context, st store.RStore) (string, []model.Manifest, bool) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	lastLoad := state.TiltfileState.LastBuild()
+	if lastLoad.Empty() || lastLoad.FinishTime.Equal(c.lastLoad) {
+		return "", nil, false
+	}
+	c.lastLoad = lastLoad.FinishTime
+
+	if lastLoad.Error != nil || !state.UpdateSettings.K8sPrune || state.TiltfilePath == "" {
+		return "", nil, false
+	}
+
+	// If we only loaded some of the resources, everything else
+	// would look like it's been removed.
+	if len(state.InitManifests) > 0 {
+		logger.Get(ctx).Debugf("Not pruning k8s objects, because only some resources were loaded")
+		return "", nil, false
+	}
+
+	return state.TiltfilePath, state.Manifests(), true
+}
+
+func (c *PruneController) prune(ctx context.Context, tiltfilePath string, manifests []model.Manifest) error {
+	current, err := ParseYAMLFromManifests(manifests...)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range current {
+		c.kinds[e.GVK()] = true
+	}
+
+	owned, err := c.kCli.ListByLabel(ctx, c.pruneKinds(), []model.LabelPair{k8s.TiltOwnerLabel(tiltfilePath)})
+	if err != nil {
+		return err
+	}
+
+	toPrune := entitiesToPrune(owned, current, c.defaultNs, c.nsOverride)
+	if len(toPrune) == 0 {
+		return nil
+	}
+
+	l := logger.Get(ctx)
+	l.Infof("Pruning k8s objects that are no longer in the Tiltfile:")
+	for _, e := range toPrune {
+		l.Infof("   %s/%s (namespace: %s)", e.GVK().Kind, e.Name(), e.Namespace())
+	}
+	return c.kCli.Delete(ctx, toPrune)
+}
+
+// Returns the kinds to look for, in a stable order.
+func (c *PruneController) pruneKinds() []schema.GroupVersionKind {
+	result := make([]schema.GroupVersionKind, 0, len(c.kinds))
+	for gvk := range c.kinds {
+		result = append(result, gvk)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+	return result
+}
+
+type pruneKey struct {
+	kind      string
+	namespace k8s.Namespace
+	name      string
+}
+
+func newPruneKey(e k8s.K8sEntity, ns k8s.Namespace) pruneKey {
+	return pruneKey{kind: e.GVK().Kind, namespace: ns, name: e.Name()}
+}
+
+// Returns the owned objects that don't match any object in the current manifests.
+//
+// The YAML often leaves the namespace blank for kubectl to fill in, so we
+// match those objects against the default namespace. With a namespace override,
+// we match them against the override instead, whatever the YAML says.
+// Cluster-scoped objects don't have a namespace, so we match them on kind and name.
+func entitiesToPrune(owned, current []k8s.K8sEntity, defaultNs k8s.Namespace, nsOverride k8s.NamespaceOverride) []k8s.K8sEntity {
+	keep := make(map[pruneKey]bool, 2*len(current))
+	for _, e := range current {
+		ns := e.MetaNamespace()
+		if !nsOverride.Empty() {
+			ns = k8s.Namespace(nsOverride)
+		} else if ns == "" {
+			ns = defaultNs
+		}
+		keep[newPruneKey(e, ns)] = true
+
+		// If the object is cluster-scoped, it's listed without a namespace.
+		keep[newPruneKey(e, "")] = true
+	}
+
+	var result []k8s.K8sEntity
+	seen := make(map[types.UID]bool)
+	for _, e := range owned {
+		// Objects created by other objects (like the pods of a Deployment)
+		// go away with their owners.
+		if len(e.OwnerReferences()) > 0 {
+			continue
+		}
+
+		if keep[newPruneKey(e, e.MetaNamespace())] {
+			continue
+		}
+
+		// The same object may be listed under more than one API group.
+		uid := e.UID()
+		if uid != "" {
+			if seen[uid] {
+				continue
+			}
+			seen[uid] = true
+		}
+
+		result = append(result, e)
+	}
+	return result
+}
+
+var _ store.Subscriber = &PruneController{}
@@ -0,0 +1,283 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestPruneDeletesRemovedObjects(t *testing.T) {
+	f := newPruneFixture(t)
+	defer f.TearDown()
+
+	f.setManifests(model.Manifest{Name: "be"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.BlorgBackendYAML}))
+	f.setCluster(f.owned(testyaml.BlorgBackendYAML), f.owned(testyaml.SanchoYAML))
+	f.finishLoad(nil)
+
+	f.pc.OnChange(f.ctx, f.st)
+	assert.Contains(t, f.kCli.DeletedYaml, "name: sancho")
+	assert.NotContains(t, f.kCli.DeletedYaml, "name: devel-nick-blorg-be")
+}
+
+func TestPruneIgnoresObjectsFromOtherTiltfiles(t *testing.T) {
+	f := newPruneFixture(t)
+	defer f.TearDown()
+
+	f.setManifests(model.Manifest{Name: "be"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.BlorgBackendYAML}))
+	f.setCluster(
+		f.owned(testyaml.BlorgBackendYAML),
+		f.withLabels(testyaml.SanchoYAML, k8s.TiltOwnerLabel(f.JoinPath("other", "Tiltfile"))),
+		f.withLabels(testyaml.PodYAML))
+	f.finishLoad(nil)
+
+	f.pc.OnChange(f.ctx, f.st)
+	assert.NotEmpty(t, f.kCli.LastListGVKs)
+	assert.Equal(t, "", f.kCli.DeletedYaml)
+}
+
+func TestPruneOncePerTiltfileLoad(t *testing.T) {
+	f := newPruneFixture(t)
+	defer f.TearDown()
+
+	f.setManifests(model.Manifest{Name: "be"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.BlorgBackendYAML}))
+	f.finishLoad(nil)
+
+	f.pc.OnChange(f.ctx, f.st)
+	assert.NotEmpty(t, f.kCli.LastListGVKs)
+
+	f.kCli.LastListGVKs = nil
+	f.pc.OnChange(f.ctx, f.st)
+	assert.Empty(t, f.kCli.LastListGVKs)
+
+	// Don't prune after a failed load.
+	f.finishLoad(fmt.Errorf("syntax error"))
+	f.pc.OnChange(f.ctx, f.st)
+	assert.Empty(t, f.kCli.LastListGVKs)
+
+	f.finishLoad(nil)
+	f.pc.OnChange(f.ctx, f.st)
+	assert.NotEmpty(t, f.kCli.LastListGVKs)
+}
+
+func TestPruneRemembersKinds(t *testing.T) {
+	f := newPruneFixture(t)
+	defer f.TearDown()
+
+	f.setManifests(model.Manifest{Name: "be"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.BlorgBackendYAML}))
+	f.finishLoad(nil)
+	f.pc.OnChange(f.ctx, f.st)
+	assert.Contains(t, kinds(f.kCli.LastListGVKs), "extensions/v1beta1, Kind=Deployment")
+
+	f.setManifests(model.Manifest{Name: "sancho"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.SanchoYAML}))
+	f.finishLoad(nil)
+	f.pc.OnChange(f.ctx, f.st)
+	assert.Contains(t, kinds(f.kCli.LastListGVKs), "extensions/v1beta1, Kind=Deployment")
+}
+
+func TestPruneDisabled(t *testing.T) {
+	f := newPruneFixture(t)
+	defer f.TearDown()
+
+	state := f.st.LockMutableStateForTesting()
+	state.UpdateSettings.K8sPrune = false
+	f.st.UnlockMutableState()
+
+	f.setManifests(model.Manifest{Name: "be"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.BlorgBackendYAML}))
+	f.finishLoad(nil)
+
+	f.pc.OnChange(f.ctx, f.st)
+	assert.Empty(t, f.kCli.LastListGVKs)
+}
+
+func TestPruneSkippedWhenOnlySomeResourcesLoaded(t *testing.T) {
+	f := newPruneFixture(t)
+	defer f.TearDown()
+
+	state := f.st.LockMutableStateForTesting()
+	state.InitManifests = []model.ManifestName{"be"}
+	f.st.UnlockMutableState()
+
+	f.setManifests(model.Manifest{Name: "be"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.BlorgBackendYAML}))
+	f.finishLoad(nil)
+
+	f.pc.OnChange(f.ctx, f.st)
+	assert.Empty(t, f.kCli.LastListGVKs)
+}
+
+func TestEntitiesToPrune(t *testing.T) {
+	current, err := k8s.ParseYAMLFromString(testyaml.BlorgBackendYAML)
+	require.NoError(t, err)
+
+	sancho, err := k8s.ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+
+	// A pod created by the sancho deployment.
+	pods, err := k8s.ParseYAMLFromString(testyaml.PodYAML)
+	require.NoError(t, err)
+	pods[0].Obj.(metav1.Object).SetOwnerReferences([]metav1.OwnerReference{{Kind: "ReplicaSet", Name: "sancho-12345"}})
+
+	owned := append(append(append([]k8s.K8sEntity{}, current...), sancho...), pods...)
+	result := entitiesToPrune(owned, current, k8s.DefaultNamespace, "")
+	if assert.Equal(t, 1, len(result)) {
+		assert.Equal(t, "sancho", result[0].Name())
+	}
+
+	// The same object, listed under two API groups.
+	k8s.SetUIDForTest(t, &sancho[0], "sancho-uid")
+	dupe := sancho[0].DeepCopy()
+	result = entitiesToPrune([]k8s.K8sEntity{sancho[0], dupe}, current, k8s.DefaultNamespace, "")
+	assert.Equal(t, 1, len(result))
+}
+
+func TestEntitiesToPruneMatchesNamespace(t *testing.T) {
+	owned, err := k8s.ParseYAMLFromString(configMapYAML("a") + "\n---\n" + configMapYAML("b"))
+	require.NoError(t, err)
+	current, err := k8s.ParseYAMLFromString(configMapYAML("a"))
+	require.NoError(t, err)
+
+	result := entitiesToPrune(owned, current, k8s.DefaultNamespace, "")
+	if assert.Equal(t, 1, len(result)) {
+		assert.Equal(t, k8s.Namespace("b"), result[0].Namespace())
+	}
+}
+
+func TestEntitiesToPruneBlankNamespace(t *testing.T) {
+	owned, err := k8s.ParseYAMLFromString(configMapYAML("sandbox") + "\n---\n" + configMapYAML("default"))
+	require.NoError(t, err)
+	current, err := k8s.ParseYAMLFromString(configMapYAML(""))
+	require.NoError(t, err)
+
+	// Objects without a namespace go in the namespace of the kubeconfig context.
+	result := entitiesToPrune(owned, current, "sandbox", "")
+	if assert.Equal(t, 1, len(result)) {
+		assert.Equal(t, k8s.Namespace("default"), result[0].Namespace())
+	}
+}
+
+func TestEntitiesToPruneNamespaceOverride(t *testing.T) {
+	owned, err := k8s.ParseYAMLFromString(configMapYAML("alice") + "\n---\n" + configMapYAML("a"))
+	require.NoError(t, err)
+	current, err := k8s.ParseYAMLFromString(configMapYAML("a"))
+	require.NoError(t, err)
+
+	// With --namespace, the object in the YAML was deployed to the override namespace.
+	result := entitiesToPrune(owned, current, "alice", "alice")
+	if assert.Equal(t, 1, len(result)) {
+		assert.Equal(t, k8s.Namespace("a"), result[0].Namespace())
+	}
+}
+
+func configMapYAML(namespace string) string {
+	yaml := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+`
+	if namespace != "" {
+		yaml += fmt.Sprintf("  namespace: %s\n", namespace)
+	}
+	return yaml + `data:
+  key: value
+`
+}
+
+type pruneFixture struct {
+	*tempdir.TempDirFixture
+	ctx  context.Context
+	st   *store.Store
+	kCli *k8s.FakeK8sClient
+	pc   *PruneController
+}
+
+func newPruneFixture(t *testing.T) *pruneFixture {
+	f := tempdir.NewTempDirFixture(t)
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.DebugLvl, ioutil.Discard))
+	st, _ := store.NewStoreForTesting()
+	kCli := k8s.NewFakeK8sClient()
+
+	state := st.LockMutableStateForTesting()
+	state.TiltfilePath = f.JoinPath("Tiltfile")
+	state.UpdateSettings = model.DefaultUpdateSettings()
+	st.UnlockMutableState()
+
+	return &pruneFixture{
+		TempDirFixture: f,
+		ctx:            ctx,
+		st:             st,
+		kCli:           kCli,
+		pc:             NewPruneController(kCli, k8s.DefaultNamespace, ""),
+	}
+}
+
+func (f *pruneFixture) setManifests(manifests ...model.Manifest) {
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets = make(map[model.ManifestName]*store.ManifestTarget)
+	state.ManifestDefinitionOrder = nil
+	for _, m := range manifests {
+		state.UpsertManifestTarget(store.NewManifestTarget(m))
+	}
+	f.st.UnlockMutableState()
+}
+
+func (f *pruneFixture) finishLoad(err error) {
+	state := f.st.LockMutableStateForTesting()
+	start := time.Now()
+	if last := state.TiltfileState.LastBuild(); !last.Empty() && !start.After(last.FinishTime) {
+		start = last.FinishTime.Add(time.Millisecond)
+	}
+	state.TiltfileState.AddCompletedBuild(model.BuildRecord{
+		StartTime:  start,
+		FinishTime: start.Add(time.Millisecond),
+		Error:      err,
+	})
+	f.st.UnlockMutableState()
+}
+
+func (f *pruneFixture) setCluster(entities ...[]k8s.K8sEntity) {
+	f.kCli.ListResults = nil
+	for _, e := range entities {
+		f.kCli.ListResults = append(f.kCli.ListResults, e...)
+	}
+}
+
+// Parses the YAML and labels it as deployed from this fixture's Tiltfile.
+func (f *pruneFixture) owned(yaml string) []k8s.K8sEntity {
+	return f.withLabels(yaml, k8s.TiltOwnerLabel(f.JoinPath("Tiltfile")))
+}
+
+func (f *pruneFixture) withLabels(yaml string, labels ...model.LabelPair) []k8s.K8sEntity {
+	entities, err := k8s.ParseYAMLFromString(yaml)
+	require.NoError(f.T(), err)
+	for i, e := range entities {
+		entities[i], err = k8s.InjectLabels(e, labels)
+		require.NoError(f.T(), err)
+	}
+	return entities
+}
+
+func (f *pruneFixture) TearDown() {
+	f.kCli.TearDown()
+	f.TempDirFixture.TearDown()
+}
+
+func kinds(gvks []schema.GroupVersionKind) []string {
+	result := make([]string, 0, len(gvks))
+	for _, gvk := range gvks {
+		result = append(result, gvk.String())
+	}
+	return result
+}
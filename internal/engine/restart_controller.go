@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// Pod statuses that mean that a container crashed.
+var crashPodStatuses = map[string]bool{
+	"CrashLoopBackOff": true,
+	"Error":            true,
+	"OOMKilled":        true,
+}
+
+// Redeploys resources that crashed, according to their restart policy.
+//
+// Waits with exponential backoff between restarts, and gives up
+// after too many restarts in a row.
+type RestartController struct {
+	mu sync.Mutex
+
+	// Restarts that we're waiting to dispatch, keyed by the build
+	// that deployed the crashing resource.
+	pending map[model.ManifestName]pendingRestart
+}
+
+type pendingRestart struct {
+	lastBuildStartTime time.Time
+	timer              *time.Timer
+}
+
+func NewRestartController() *RestartController {
+	return &RestartController{
+		pending: make(map[model.ManifestName]pendingRestart),
+	}
+}
+
+func (c *RestartController) OnChange(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	crashed := make(map[model.ManifestName]bool)
+	for _, mt := range state.Targets() {
+		mn := mt.Manifest.Name
+		if !shouldRestartOnCrash(state, mt) || crashReason(mt) == "" {
+			continue
+		}
+		crashed[mn] = true
+
+		lastBuildStartTime := mt.State.LastBuild().StartTime
+		if p, ok := c.pending[mn]; ok && p.lastBuildStartTime.Equal(lastBuildStartTime) {
+			continue
+		}
+
+		// Don't wait to give up.
+		policy := mt.Manifest.RestartPolicy
+		delay := time.Duration(0)
+		if mt.State.CrashRestartCount < policy.MaxRestarts {
+			delay = policy.Backoff(mt.State.CrashRestartCount)
+		}
+
+		action := CrashRestartAction{
+			ManifestName:       mn,
+			LastBuildStartTime: lastBuildStartTime,
+		}
+		c.stop(mn)
+		c.pending[mn] = pendingRestart{
+			lastBuildStartTime: lastBuildStartTime,
+			timer: time.AfterFunc(delay, func() {
+				st.Dispatch(action)
+			}),
+		}
+	}
+
+	// Forget restarts of resources that recovered, got rebuilt, or went away.
+	for mn := range c.pending {
+		if !crashed[mn] {
+			c.stop(mn)
+		}
+	}
+}
+
+// Must hold the lock.
+func (c *RestartController) stop(mn model.ManifestName) {
+	p, ok := c.pending[mn]
+	if !ok {
+		return
+	}
+	p.timer.Stop()
+	delete(c.pending, mn)
+}
+
+func (c *RestartController) TearDown(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for mn := range c.pending {
+		c.stop(mn)
+	}
+}
+
+// Whether we should restart the resource if it crashes right now.
+func shouldRestartOnCrash(state store.EngineState, mt *store.ManifestTarget) bool {
+	ms := mt.State
+	return mt.Manifest.RestartPolicy.Mode == model.RestartModeOnFailure &&
+		!ms.Disabled &&
+		!ms.GaveUpRestarting &&
+		!ms.NeedsRebuildFromCrash &&
+		!state.CurrentlyBuilding[mt.Manifest.Name] &&
+		ms.CurrentBuild.Empty() &&
+		!ms.LastBuild().Empty()
+}
+
+// A human-readable description of why the resource crashed,
+// or the empty string if it's not crashing.
+func crashReason(mt *store.ManifestTarget) string {
+	ms := mt.State
+	switch {
+	case mt.Manifest.IsK8s() && mt.Manifest.K8sTarget().HasJobs:
+		// The Job retries failed pods on its own, so only the Job
+		// knows when it's really failed.
+		job := ms.K8sRuntimeState().CurrentJob()
+		if job.Status == store.JobStatusFailed {
+			return fmt.Sprintf("job %s failed: %s", job.Name, job.Message)
+		}
+	case mt.Manifest.IsK8s():
+		for _, pod := range ms.K8sRuntimeState().PodList() {
+			if pod.Deleting {
+				continue
+			}
+			if crashPodStatuses[pod.Status] {
+				return fmt.Sprintf("pod %s is in %s", pod.PodID, pod.Status)
+			}
+			if pod.Phase == v1.PodFailed {
+				return fmt.Sprintf("pod %s failed", pod.PodID)
+			}
+		}
+	case mt.Manifest.IsDC():
+		if ms.DCRuntimeState().Status == dockercompose.StatusCrash {
+			return "container exited unexpectedly"
+		}
+	}
+	return ""
+}
+
+// The log of whatever crashed.
+func crashLog(mt *store.ManifestTarget) model.Log {
+	ms := mt.State
+	switch {
+	case mt.Manifest.IsK8s():
+		return ms.MostRecentPod().CurrentLog
+	case mt.Manifest.IsDC():
+		return ms.DCRuntimeState().CurrentLog
+	}
+	return model.Log{}
+}
+
+var _ store.Subscriber = &RestartController{}
+var _ store.TearDowner = &RestartController{}
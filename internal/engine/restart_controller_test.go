@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestRestartControllerDispatchesAfterBackoff(t *testing.T) {
+	f := newRestartFixture(t)
+	defer f.TearDown()
+
+	policy := model.OnFailureRestartPolicy(3)
+	policy.InitialBackoff = 50 * time.Millisecond
+	start := f.upsertCrashedDC("db", policy)
+
+	f.rc.OnChange(f.ctx, f.st)
+	assert.Equal(t, 0, len(f.actions()))
+
+	// Seeing the same crash again doesn't schedule another restart.
+	f.rc.OnChange(f.ctx, f.st)
+
+	f.waitForActions(1)
+	assert.Equal(t, CrashRestartAction{ManifestName: "db", LastBuildStartTime: start}, f.actions()[0])
+}
+
+func TestRestartControllerGivesUpImmediately(t *testing.T) {
+	f := newRestartFixture(t)
+	defer f.TearDown()
+
+	policy := model.OnFailureRestartPolicy(1)
+	policy.InitialBackoff = time.Hour
+	f.upsertCrashedDC("db", policy)
+
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["db"].State.CrashRestartCount = 1
+	f.st.UnlockMutableState()
+
+	f.rc.OnChange(f.ctx, f.st)
+	f.waitForActions(1)
+}
+
+func TestRestartControllerCancelsWhenRecovered(t *testing.T) {
+	f := newRestartFixture(t)
+	defer f.TearDown()
+
+	policy := model.OnFailureRestartPolicy(3)
+	policy.InitialBackoff = 50 * time.Millisecond
+	f.upsertCrashedDC("db", policy)
+	f.rc.OnChange(f.ctx, f.st)
+
+	state := f.st.LockMutableStateForTesting()
+	ms := state.ManifestTargets["db"].State
+	ms.RuntimeState = ms.DCRuntimeState().WithStatus(dockercompose.StatusUp)
+	f.st.UnlockMutableState()
+	f.rc.OnChange(f.ctx, f.st)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 0, len(f.actions()))
+}
+
+func TestRestartControllerIgnoresPolicyNever(t *testing.T) {
+	f := newRestartFixture(t)
+	defer f.TearDown()
+
+	f.upsertCrashedDC("db", model.RestartPolicy{})
+	f.rc.OnChange(f.ctx, f.st)
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 0, len(f.actions()))
+}
+
+type restartFixture struct {
+	t       *testing.T
+	ctx     context.Context
+	cancel  func()
+	st      *store.Store
+	actions func() []store.Action
+	rc      *RestartController
+}
+
+func newRestartFixture(t *testing.T) *restartFixture {
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.DebugLvl, ioutil.Discard))
+	ctx, cancel := context.WithCancel(ctx)
+	st, getActions := store.NewStoreForTesting()
+
+	// Keep the store loop running until we cancel it.
+	state := st.LockMutableStateForTesting()
+	state.WatchFiles = true
+	st.UnlockMutableState()
+
+	go func() {
+		_ = st.Loop(ctx)
+	}()
+
+	return &restartFixture{
+		t:       t,
+		ctx:     ctx,
+		cancel:  cancel,
+		st:      st,
+		actions: getActions,
+		rc:      NewRestartController(),
+	}
+}
+
+func (f *restartFixture) upsertCrashedDC(name model.ManifestName, policy model.RestartPolicy) time.Time {
+	m := model.Manifest{Name: name}.
+		WithDeployTarget(model.DockerComposeTarget{Name: name.TargetName()}).
+		WithRestartPolicy(policy)
+	start := time.Now()
+
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	ms := state.ManifestTargets[name].State
+	ms.AddCompletedBuild(model.BuildRecord{StartTime: start, FinishTime: start})
+	ms.RuntimeState = dockercompose.State{Status: dockercompose.StatusCrash}
+	f.st.UnlockMutableState()
+	return start
+}
+
+func (f *restartFixture) waitForActions(n int) {
+	ctx, cancel := context.WithTimeout(f.ctx, time.Second)
+	defer cancel()
+	for len(f.actions()) < n {
+		select {
+		case <-ctx.Done():
+			f.t.Fatalf("Timed out waiting for %d actions", n)
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func (f *restartFixture) TearDown() {
+	f.rc.TearDown(f.ctx)
+	f.cancel()
+}
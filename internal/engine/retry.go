@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/build"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// Error messages that mean the operation might succeed if we try again.
+//
+// Most of the errors from pushing and deploying come back to us as strings
+// (from the Docker daemon's JSON stream or from kubectl), so we match on the text.
+var transientErrorMessages = []string{
+	"connection refused",
+	"connection reset by peer",
+	"broken pipe",
+	"i/o timeout",
+	"tls handshake timeout",
+	"timeout exceeded",
+	"timed out",
+	"unexpected eof",
+	"no such host",
+	"internal server error",
+	"bad gateway",
+	"service unavailable",
+	"gateway timeout",
+	"too many requests",
+	"unexpected http status: 5",
+	"unable to connect to the server",
+	"the server is currently unable to handle the request",
+	"the server was unable to return a response",
+}
+
+// Error messages that mean trying again won't help, even if they
+// also look like a transient error.
+var permanentErrorMessages = []string{
+	"unauthorized",
+	"authentication required",
+	"denied",
+	"forbidden",
+	"name unknown",
+	"manifest invalid",
+}
+
+// Whether the error is likely to go away if we try again, like a network blip
+// or a registry that's temporarily returning 5xx errors.
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	cause := errors.Cause(err)
+	if cause == context.Canceled {
+		return false
+	}
+	if cause == context.DeadlineExceeded || cause == io.ErrUnexpectedEOF {
+		return true
+	}
+	if netErr, ok := cause.(net.Error); ok && (netErr.Timeout() || netErr.Temporary()) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, s := range permanentErrorMessages {
+		if strings.Contains(msg, s) {
+			return false
+		}
+	}
+	for _, s := range transientErrorMessages {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Runs the given operation, retrying it with backoff if it fails with a transient error.
+//
+// Each retry is logged to the build log, so that the user can see why
+// the build is taking longer than usual.
+func withRetries(ctx context.Context, ps *build.PipelineState, settings model.UpdateSettings,
+	op string, f func() error) error {
+	for retry := 0; ; retry++ {
+		err := f()
+		if err == nil || !isTransientError(err) {
+			return err
+		}
+
+		if retry >= settings.MaxRetries {
+			if retry == 0 {
+				return err
+			}
+			return errors.Wrapf(err, "%s failed after %d attempts", op, retry+1)
+		}
+
+		delay := settings.RetryDelay(retry)
+		ps.Printf(ctx, "%s failed with a transient error (attempt %d of %d): %v", op, retry+1, settings.MaxRetries+1, err)
+		ps.Printf(ctx, "Retrying in %s...", delay)
+
+		select {
+		case <-ctx.Done():
+			// Report the cancellation, not the error we were going to retry,
+			// so that a canceled build doesn't look like a failed one.
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/build"
+	"github.com/windmilleng/tilt/pkg/logger"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestIsTransientError(t *testing.T) {
+	for _, test := range []struct {
+		err       error
+		transient bool
+	}{
+		{fmt.Errorf("received unexpected HTTP status: 503 Service Unavailable"), true},
+		{fmt.Errorf("Get https://gcr.io/v2/: net/http: TLS handshake timeout"), true},
+		{fmt.Errorf("read tcp 10.0.0.1:443: read: connection reset by peer"), true},
+		{fmt.Errorf("kubectl apply:\nstderr: Unable to connect to the server: dial tcp: i/o timeout"), true},
+		{errors.Wrap(context.DeadlineExceeded, "pushing image"), true},
+		{fmt.Errorf("unauthorized: authentication required"), false},
+		{fmt.Errorf("denied: requested access to the resource is denied"), false},
+		{fmt.Errorf(`kubectl apply:\nstderr: error validating "STDIN": unknown field "foo"`), false},
+		{errors.Wrap(context.Canceled, "pushing image"), false},
+	} {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			assert.Equal(t, test.transient, isTransientError(test.err))
+		})
+	}
+}
+
+func TestWithRetriesSucceedsAfterTransientError(t *testing.T) {
+	f := newRetryFixture()
+
+	calls := 0
+	err := withRetries(f.ctx, f.ps, f.settings(3), "Push", func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("received unexpected HTTP status: 502 Bad Gateway")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Contains(t, f.out.String(), "Push failed with a transient error (attempt 1 of 4)")
+	assert.Contains(t, f.out.String(), "Push failed with a transient error (attempt 2 of 4)")
+}
+
+func TestWithRetriesGivesUp(t *testing.T) {
+	f := newRetryFixture()
+
+	calls := 0
+	err := withRetries(f.ctx, f.ps, f.settings(2), "Apply", func() error {
+		calls++
+		return fmt.Errorf("the server is currently unable to handle the request")
+	})
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Apply failed after 3 attempts")
+	}
+	assert.Equal(t, 3, calls)
+}
+
+func TestWithRetriesPermanentError(t *testing.T) {
+	f := newRetryFixture()
+
+	calls := 0
+	err := withRetries(f.ctx, f.ps, f.settings(3), "Push", func() error {
+		calls++
+		return fmt.Errorf("unauthorized: authentication required")
+	})
+
+	assert.EqualError(t, err, "unauthorized: authentication required")
+	assert.Equal(t, 1, calls)
+	assert.NotContains(t, f.out.String(), "Retrying")
+}
+
+func TestWithRetriesCanceledDuringBackoff(t *testing.T) {
+	f := newRetryFixture()
+	ctx, cancel := context.WithCancel(f.ctx)
+
+	calls := 0
+	err := withRetries(ctx, f.ps, f.settings(3), "Push", func() error {
+		calls++
+		cancel()
+		return fmt.Errorf("received unexpected HTTP status: 503 Service Unavailable")
+	})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, calls)
+}
+
+type retryFixture struct {
+	ctx context.Context
+	out *bytes.Buffer
+	ps  *build.PipelineState
+}
+
+func newRetryFixture() *retryFixture {
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, out))
+	return &retryFixture{
+		ctx: ctx,
+		out: out,
+		ps:  build.NewPipelineState(ctx, 1, fakeClock{time.Now()}),
+	}
+}
+
+func (f *retryFixture) settings(maxRetries int) model.UpdateSettings {
+	s := model.DefaultUpdateSettings()
+	s.MaxRetries = maxRetries
+	s.RetryBackoff = time.Millisecond
+	return s
+}
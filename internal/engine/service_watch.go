@@ -37,7 +37,8 @@ func (w *ServiceWatcher) needsWatch(st store.RStore) bool {
 			atLeastOneK8s = true
 		}
 	}
-	return atLeastOneK8s && state.WatchFiles && !w.watching
+	watch := state.WatchFiles || state.EngineMode == store.EngineModeCI
+	return atLeastOneK8s && watch && !w.watching
 }
 
 func (w *ServiceWatcher) OnChange(ctx context.Context, st store.RStore) {
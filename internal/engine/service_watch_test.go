@@ -42,6 +42,31 @@ func TestServiceWatch(t *testing.T) {
 	f.assertObservedServiceChangeActions(expectedSCA)
 }
 
+func TestServiceWatchInCIMode(t *testing.T) {
+	f := newSWFixture(t)
+	defer f.TearDown()
+
+	f.addManifest("server")
+	state := f.store.LockMutableStateForTesting()
+	state.WatchFiles = false
+	state.EngineMode = store.EngineModeCI
+	f.store.UnlockMutableState()
+
+	f.sw.OnChange(f.ctx, f.store)
+
+	nodePort := 9998
+	s := f.serviceNamed("foo", nodePort)
+	f.kClient.EmitService(k8s.TiltRunSelector(), s)
+
+	expectedSCA := ServiceChangeAction{Service: s, URL: &url.URL{
+		Scheme: "http",
+		Host:   fmt.Sprintf("%s:%d", f.nip, nodePort),
+		Path:   "/",
+	}}
+
+	f.assertObservedServiceChangeActions(expectedSCA)
+}
+
 func (f *swFixture) addManifest(manifestName string) {
 	state := f.store.LockMutableStateForTesting()
 	state.WatchFiles = true
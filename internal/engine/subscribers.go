@@ -12,6 +12,7 @@ func ProvideSubscribers(
 	hud hud.HeadsUpDisplay,
 	pw *PodWatcher,
 	sw *ServiceWatcher,
+	jw *JobWatcher,
 	plm *PodLogManager,
 	pfc *PortForwardController,
 	fwm *WatchManager,
@@ -27,11 +28,18 @@ func ProvideSubscribers(
 	sail client.SailClient,
 	tvc *TiltVersionChecker,
 	ta *TiltAnalyticsSubscriber,
-	ewm *EventWatchManager) []store.Subscriber {
+	ewm *EventWatchManager,
+	lsc *LocalServeController,
+	cic *CIController,
+	dc *DisableController,
+	hc *HistoryController,
+	rc *RestartController,
+	prc *PruneController) []store.Subscriber {
 	return []store.Subscriber{
 		hud,
 		pw,
 		sw,
+		jw,
 		plm,
 		pfc,
 		fwm,
@@ -48,5 +56,11 @@ func ProvideSubscribers(
 		tvc,
 		ta,
 		ewm,
+		lsc,
+		cic,
+		dc,
+		hc,
+		rc,
+		prc,
 	}
 }
@@ -45,7 +45,8 @@ var watchBufferMaxDuration = watchBufferMaxTimeInMs * time.Millisecond
 // TODO(nick): maybe this should be called 'BuildEngine' or something?
 // Upper seems like a poor and undescriptive name.
 type Upper struct {
-	store *store.Store
+	store   *store.Store
+	history *HistoryController
 }
 
 type FsWatcherMaker func(paths []string, ignore watch.PathMatcher, l logger.Logger) (watch.Notify, error)
@@ -65,7 +66,7 @@ func ProvideTimerMaker() timerMaker {
 	}
 }
 
-func NewUpper(ctx context.Context, st *store.Store, subs []store.Subscriber) Upper {
+func NewUpper(ctx context.Context, st *store.Store, subs []store.Subscriber, history *HistoryController) Upper {
 	// There's not really a good reason to add all the subscribers
 	// in NewUpper(), but it's as good a place as any.
 	for _, sub := range subs {
@@ -73,7 +74,8 @@ func NewUpper(ctx context.Context, st *store.Store, subs []store.Subscriber) Upp
 	}
 
 	return Upper{
-		store: st,
+		store:   st,
+		history: history,
 	}
 }
 
@@ -84,12 +86,17 @@ func (u Upper) Dispatch(action store.Action) {
 func (u Upper) Start(
 	ctx context.Context,
 	args []string,
+	configArgs []string,
 	b model.TiltBuild,
 	watch bool,
 	fileName string,
 	useActionWriter bool,
 	sailMode model.SailMode,
-	analyticsOpt analytics.Opt) error {
+	analyticsOpt analytics.Opt,
+	maxParallelUpdates int,
+	confirmChanges bool,
+	engineMode store.EngineMode,
+	ciTimeout time.Duration) error {
 
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Start")
 	defer span.Finish()
@@ -110,15 +117,27 @@ func (u Upper) Start(
 
 	configFiles := []string{absTfPath}
 
+	// CI runs always start from scratch.
+	var history store.History
+	if engineMode != store.EngineModeCI {
+		history = u.history.Load(ctx, absTfPath)
+	}
+
 	return u.Init(ctx, InitAction{
-		WatchFiles:    watch,
-		TiltfilePath:  absTfPath,
-		ConfigFiles:   configFiles,
-		InitManifests: manifestNames,
-		TiltBuild:     b,
-		StartTime:     startTime,
-		EnableSail:    sailMode.IsEnabled(),
-		AnalyticsOpt:  analyticsOpt,
+		WatchFiles:         watch,
+		TiltfilePath:       absTfPath,
+		ConfigFiles:        configFiles,
+		InitManifests:      manifestNames,
+		ConfigArgs:         configArgs,
+		TiltBuild:          b,
+		StartTime:          startTime,
+		EnableSail:         sailMode.IsEnabled(),
+		AnalyticsOpt:       analyticsOpt,
+		MaxParallelUpdates: maxParallelUpdates,
+		ConfirmChanges:     confirmChanges,
+		EngineMode:         engineMode,
+		CITimeout:          ciTimeout,
+		History:            history,
 	})
 }
 
@@ -147,6 +166,8 @@ var UpperReducer = store.Reducer(func(ctx context.Context, state *store.EngineSt
 		handlePodChangeAction(ctx, state, action.Pod)
 	case ServiceChangeAction:
 		handleServiceEvent(ctx, state, action)
+	case JobChangeAction:
+		handleJobChangeAction(ctx, state, action.Job)
 	case store.K8sEventAction:
 		handleK8sEvent(ctx, state, action)
 	case PodLogAction:
@@ -167,8 +188,24 @@ var UpperReducer = store.Reducer(func(ctx context.Context, state *store.EngineSt
 		handleDockerComposeEvent(ctx, state, action)
 	case DockerComposeLogAction:
 		handleDockerComposeLogAction(state, action)
+	case CrashRestartAction:
+		handleCrashRestartAction(state, action)
+	case LocalServeStatusAction:
+		handleLocalServeStatusAction(state, action)
+	case LocalServeLogAction:
+		handleLocalServeLogAction(state, action)
 	case server.AppendToTriggerQueueAction:
 		appendToTriggerQueue(state, action.Name)
+	case store.SetResourceEnabledAction:
+		handleSetResourceEnabledAction(ctx, state, action)
+	case K8sChangesPendingAction:
+		handleK8sChangesPendingAction(state, action)
+	case store.ApproveK8sChangesAction:
+		handleApproveK8sChangesAction(ctx, state, action)
+	case PortForwardStatusAction:
+		handlePortForwardStatusAction(state, action)
+	case PortForwardsStoppedAction:
+		handlePortForwardsStoppedAction(state, action)
 	case hud.StartProfilingAction:
 		handleStartProfilingAction(state)
 	case hud.StopProfilingAction:
@@ -200,17 +237,24 @@ var UpperReducer = store.Reducer(func(ctx context.Context, state *store.EngineSt
 })
 
 func handleBuildStarted(ctx context.Context, state *store.EngineState, action BuildStartedAction) {
+	state.StartedBuildCount++
+
 	mn := action.ManifestName
-	ms, ok := state.ManifestState(mn)
+	mt, ok := state.ManifestTargets[mn]
 	if !ok {
 		return
 	}
+	ms := mt.State
 
 	bs := model.BuildRecord{
 		Edits:     append([]string{}, action.FilesChanged...),
 		StartTime: action.StartTime,
 		Reason:    action.Reason,
 	}
+	if action.Reason.IsCrashOnly() && ms.CrashRestartReason != "" {
+		bs.Log = model.NewLog(fmt.Sprintf("Restarting after crash (attempt %d of %d): %s\n",
+			ms.CrashRestartCount, mt.Manifest.RestartPolicy.MaxRestarts, ms.CrashRestartReason))
+	}
 	ms.ConfigFilesThatCausedChange = []string{}
 	ms.CurrentBuild = bs
 
@@ -219,7 +263,7 @@ func handleBuildStarted(ctx context.Context, state *store.EngineState, action Bu
 			pod.CurrentLog = model.Log{}
 			pod.UpdateStartTime = action.StartTime
 		}
-	} else {
+	} else if mt.Manifest.IsDC() {
 		ms.RuntimeState = ms.DCRuntimeState().WithCurrentLog(model.Log{})
 	}
 
@@ -227,19 +271,75 @@ func handleBuildStarted(ctx context.Context, state *store.EngineState, action Bu
 	// triggered by a explicit change (i.e., not a crash rebuild)
 	if !action.Reason.IsCrashOnly() {
 		ms.CrashLog = model.Log{}
+		ms.CrashRestartCount = 0
+		ms.CrashRestartReason = ""
+		ms.GaveUpRestarting = false
 	}
 
-	state.CurrentlyBuilding = mn
+	state.CurrentlyBuilding[mn] = true
 	removeFromTriggerQueue(state, mn)
 }
 
+func handleCrashRestartAction(state *store.EngineState, action CrashRestartAction) {
+	mn := action.ManifestName
+	mt, ok := state.ManifestTargets[mn]
+	if !ok {
+		return
+	}
+	ms := mt.State
+
+	// Double-check that the crash is still current.
+	if !shouldRestartOnCrash(*state, mt) ||
+		!ms.LastBuild().StartTime.Equal(action.LastBuildStartTime) {
+		return
+	}
+	reason := crashReason(mt)
+	if reason == "" {
+		return
+	}
+
+	ms.CrashLog = crashLog(mt)
+	ms.CrashRestartReason = reason
+
+	var msg string
+	maxRestarts := mt.Manifest.RestartPolicy.MaxRestarts
+	if ms.CrashRestartCount >= maxRestarts {
+		ms.GaveUpRestarting = true
+		msg = fmt.Sprintf("%s crashed (%s) after %d restarts. Giving up on restarting it. "+
+			"Fix the crash and save a file, or trigger an update, to try again.", mn, reason, ms.CrashRestartCount)
+	} else {
+		ms.CrashRestartCount++
+		ms.NeedsRebuildFromCrash = true
+		msg = fmt.Sprintf("%s crashed (%s). Redeploying it (attempt %d of %d).", mn, reason, ms.CrashRestartCount, maxRestarts)
+	}
+
+	handleLogAction(state, store.NewLogEvent(mn, []byte(msg+"\n")))
+}
+
+// A build is a no-op if it re-used at least one image because its build context
+// didn't change, and didn't deploy any image that differs from the last build.
+func isNoOpBuild(ms *store.ManifestState, results store.BuildResultSet) bool {
+	anyNoOp := false
+	for id, result := range results {
+		if !result.HasImage() {
+			continue
+		}
+
+		last := ms.BuildStatus(id).LastSuccessfulResult
+		if !last.HasImage() || last.Image.String() != result.Image.String() {
+			return false
+		}
+		anyNoOp = anyNoOp || result.NoOp
+	}
+	return anyNoOp
+}
+
 func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, cb BuildCompleteAction) error {
 	defer func() {
-		engineState.CurrentlyBuilding = ""
+		delete(engineState.CurrentlyBuilding, cb.ManifestName)
 	}()
 
 	engineState.CompletedBuildCount++
-	engineState.BuildControllerActionCount++
 
 	defer func() {
 		if engineState.CompletedBuildCount == engineState.InitialBuildsQueued {
@@ -249,15 +349,42 @@ func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 
 	err := cb.Error
 
-	mt, ok := engineState.ManifestTargets[engineState.CurrentlyBuilding]
+	mt, ok := engineState.ManifestTargets[cb.ManifestName]
 	if !ok {
 		return nil
 	}
 
 	ms := mt.State
 	bs := ms.CurrentBuild
-	bs.Error = err
 	bs.FinishTime = time.Now()
+
+	if ms.Disabled {
+		// The resource was disabled mid-build, so throw away the result.
+		// The DisableController deletes whatever the build deployed.
+		ms.CurrentBuild = model.BuildRecord{}
+		return nil
+	}
+
+	if cb.Superseded {
+		// Leave the pending changes and runtime state alone;
+		// the next build will pick up where this one left off.
+		bs.Superseded = true
+		ms.AddCompletedBuild(bs)
+		ms.CurrentBuild = model.BuildRecord{}
+		return nil
+	}
+
+	if IsAwaitingApprovalError(err) {
+		// Nothing failed, and nothing was deployed. The k8s changes wait in
+		// PendingK8sChanges until the user approves them, which triggers the
+		// next build.
+		ms.AddCompletedBuild(bs)
+		ms.CurrentBuild = model.BuildRecord{}
+		return nil
+	}
+
+	bs.Error = err
+	bs.NoOp = err == nil && isNoOpBuild(ms, cb.Result)
 	ms.AddCompletedBuild(bs)
 
 	ms.CurrentBuild = model.BuildRecord{}
@@ -266,7 +393,8 @@ func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 	if err != nil {
 		if isPermanentError(err) {
 			return err
-		} else if engineState.WatchFiles {
+		} else if engineState.WatchFiles || engineState.EngineMode == store.EngineModeCI {
+			// In CI mode, the CIController reports the failure.
 			l := logger.Get(ctx)
 			p := logger.Red(l).Sprintf("Build Failed:")
 			l.Infof("%s %v", p, err)
@@ -289,9 +417,17 @@ func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 		}
 
 		ms.LastSuccessfulDeployTime = time.Now()
+		ms.PendingK8sChanges = nil
+		ms.ApprovedK8sChanges = nil
 
 		for id, result := range cb.Result {
-			ms.MutableBuildStatus(id).LastSuccessfulResult = result
+			// NoOp only describes the build that produced the result.
+			result.NoOp = false
+
+			status := ms.MutableBuildStatus(id)
+			status.LastSuccessfulResult = result
+			status.LastSuccessfulBuildTime = bs.StartTime
+			status.RestoredFromHistory = false
 		}
 
 		for _, pod := range ms.K8sRuntimeState().Pods {
@@ -385,6 +521,97 @@ func appendToTriggerQueue(state *store.EngineState, mn model.ManifestName) {
 	state.TriggerQueue = append(state.TriggerQueue, mn)
 }
 
+func handleSetResourceEnabledAction(ctx context.Context, state *store.EngineState, action store.SetResourceEnabledAction) {
+	mn := action.ManifestName
+	ms, ok := state.ManifestState(mn)
+	if !ok || ms.Disabled == !action.Enabled {
+		return
+	}
+
+	if action.Enabled {
+		// The BuildController treats the resource as if it had never been
+		// built, and deploys it from scratch.
+		ms.Disabled = false
+		logger.Get(ctx).Infof("Enabled resource %s", mn)
+		return
+	}
+
+	ms.Disabled = true
+	removeFromTriggerQueue(state, mn)
+
+	// Everything we deployed is about to be deleted, so forget about it.
+	ms.BuildStatuses = make(map[model.TargetID]*store.BuildStatus)
+	ms.BuildHistory = nil
+	ms.PreviousBuildHistory = nil
+	ms.PendingManifestChange = time.Time{}
+	ms.LastSuccessfulDeployTime = time.Time{}
+	ms.PreviousDeployTime = time.Time{}
+	ms.LiveUpdatedContainerIDs = container.NewIDSet()
+	ms.NeedsRebuildFromCrash = false
+	ms.RuntimeState = nil
+	ms.PendingK8sChanges = nil
+	ms.ApprovedK8sChanges = nil
+	logger.Get(ctx).Infof("Disabled resource %s", mn)
+}
+
+func handlePortForwardStatusAction(state *store.EngineState, action PortForwardStatusAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+
+	if ms.PortForwards == nil {
+		ms.PortForwards = make(map[int]store.PortForwardState)
+	}
+
+	pf := store.PortForwardState{
+		Forward: action.Forward,
+		PodID:   action.PodID,
+		Status:  action.Status,
+	}
+	if action.Error != nil {
+		pf.Error = action.Error.Error()
+	}
+	ms.PortForwards[action.Forward.LocalPort] = pf
+}
+
+func handlePortForwardsStoppedAction(state *store.EngineState, action PortForwardsStoppedAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+
+	for port, pf := range ms.PortForwards {
+		if pf.PodID == action.PodID {
+			delete(ms.PortForwards, port)
+		}
+	}
+}
+
+func handleK8sChangesPendingAction(state *store.EngineState, action K8sChangesPendingAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+	ms.PendingK8sChanges = action.Changes
+	ms.ApprovedK8sChanges = nil
+}
+
+func handleApproveK8sChangesAction(ctx context.Context, state *store.EngineState, action store.ApproveK8sChangesAction) {
+	mn := action.ManifestName
+	ms, ok := state.ManifestState(mn)
+	if !ok || len(ms.PendingK8sChanges) == 0 {
+		return
+	}
+
+	// The next build applies exactly these changes. If anything else
+	// changed in the meantime, we ask again.
+	ms.ApprovedK8sChanges = ms.PendingK8sChanges
+	ms.PendingManifestChange = time.Now()
+	logger.Get(ctx).Infof("Approved changes to resource %s", mn)
+	appendToTriggerQueue(state, mn)
+}
+
 func removeFromTriggerQueue(state *store.EngineState, mn model.ManifestName) {
 	for i, triggerName := range state.TriggerQueue {
 		if triggerName == mn {
@@ -492,7 +719,8 @@ func handleConfigsReloaded(
 	newDefOrder := make([]model.ManifestName, len(manifests))
 	for i, m := range manifests {
 		mt, ok := state.ManifestTargets[m.ManifestName()]
-		if !ok {
+		isNew := !ok
+		if isNew {
 			mt = store.NewManifestTarget(m)
 		}
 
@@ -510,6 +738,11 @@ func handleConfigsReloaded(
 			ms.PendingManifestChange = time.Now()
 			ms.ConfigFilesThatCausedChange = configFilesThatChanged
 		}
+
+		if rh, ok := state.History.Resources[m.Name]; ok && isNew {
+			rh.Restore(mt.State, m)
+			delete(state.History.Resources, m.Name)
+		}
 		state.UpsertManifestTarget(mt)
 	}
 	// TODO(dmiller) handle deleting manifests
@@ -520,6 +753,7 @@ func handleConfigsReloaded(
 
 	state.Features = event.Features
 	state.TeamName = event.TeamName
+	state.UpdateSettings = event.UpdateSettings
 
 	// Remove pending file changes that were consumed by this build.
 	for file, modTime := range state.PendingConfigFileChanges {
@@ -532,7 +766,7 @@ func handleConfigsReloaded(
 func handleBuildLogAction(state *store.EngineState, action BuildLogAction) {
 	manifestName := action.Source()
 	ms, ok := state.ManifestState(manifestName)
-	if !ok || state.CurrentlyBuilding != manifestName {
+	if !ok || !state.CurrentlyBuilding[manifestName] {
 		// This is OK. The user could have edited the manifest recently.
 		return
 	}
@@ -586,7 +820,7 @@ func handleServiceEvent(ctx context.Context, state *store.EngineState, action Se
 	}
 
 	ms, ok := state.ManifestState(manifestName)
-	if !ok {
+	if !ok || ms.Disabled {
 		return
 	}
 
@@ -635,9 +869,18 @@ func handleInitAction(ctx context.Context, engineState *store.EngineState, actio
 	engineState.TiltfilePath = action.TiltfilePath
 	engineState.ConfigFiles = action.ConfigFiles
 	engineState.InitManifests = action.InitManifests
+	engineState.ConfigArgs = action.ConfigArgs
 	engineState.SailEnabled = action.EnableSail
 	engineState.AnalyticsOpt = action.AnalyticsOpt
 	engineState.WatchFiles = action.WatchFiles
+	engineState.MaxParallelUpdatesOverride = action.MaxParallelUpdates
+	engineState.ConfirmChanges = action.ConfirmChanges
+	engineState.EngineMode = action.EngineMode
+	engineState.CITimeout = action.CITimeout
+	engineState.History = action.History
+	if action.History.Log != "" {
+		engineState.Log = model.NewLog(action.History.Log + engineState.Log.String())
+	}
 
 	// NOTE(dmiller): this kicks off a Tiltfile build
 	engineState.PendingConfigFileChanges[action.TiltfilePath] = time.Now()
@@ -657,7 +900,7 @@ func handleDockerComposeEvent(ctx context.Context, engineState *store.EngineStat
 	evt := action.Event
 	mn := evt.Service
 	ms, ok := engineState.ManifestState(model.ManifestName(mn))
-	if !ok {
+	if !ok || ms.Disabled {
 		// No corresponding manifest, nothing to do
 		return
 	}
@@ -705,6 +948,46 @@ func handleDockerComposeLogAction(state *store.EngineState, action DockerCompose
 	ms.RuntimeState = dcState.WithCurrentLog(model.AppendLog(dcState.CurrentLog, action, state.LogTimestamps, ""))
 }
 
+func handleLocalServeStatusAction(state *store.EngineState, action LocalServeStatusAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok || ms.Disabled {
+		// No corresponding manifest, nothing to do
+		return
+	}
+
+	lState := ms.LocalRuntimeState()
+	if action.Status == store.LocalServeStatusRunning {
+		// A new process started, so start a new log.
+		lState = store.LocalRuntimeState{
+			PID:       action.PID,
+			StartTime: action.StartTime,
+		}
+	} else if action.PID != 0 && action.PID != lState.PID {
+		// An exit from a process we've already replaced.
+		// (PID 0 means the process failed to start at all.)
+		return
+	}
+
+	lState.Status = action.Status
+	ms.RuntimeState = lState
+}
+
+func handleLocalServeLogAction(state *store.EngineState, action LocalServeLogAction) {
+	ms, ok := state.ManifestState(action.Source())
+	if !ok {
+		// This is OK. The user could have edited the manifest recently.
+		return
+	}
+
+	lState := ms.LocalRuntimeState()
+	if lState.PID != action.PID {
+		return
+	}
+
+	lState.CurrentLog = model.AppendLog(lState.CurrentLog, action, state.LogTimestamps, "")
+	ms.RuntimeState = lState
+}
+
 func handleTiltfileLogAction(ctx context.Context, state *store.EngineState, action TiltfileLogAction) {
 	state.TiltfileState.CurrentBuild.Log = model.AppendLog(state.TiltfileState.CurrentBuild.Log, action, state.LogTimestamps, "")
 	state.TiltfileState.CombinedLog = model.AppendLog(state.TiltfileState.CombinedLog, action, state.LogTimestamps, "")
@@ -21,6 +21,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/windmilleng/wmclient/pkg/analytics"
+	"github.com/windmilleng/wmclient/pkg/dirs"
 	"gopkg.in/yaml.v2"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -130,6 +131,9 @@ func (c buildAndDeployCall) oneState() store.BuildState {
 }
 
 type fakeBuildAndDeployer struct {
+	// The BuildController may call BuildAndDeploy from several goroutines at once.
+	mu sync.Mutex
+
 	t     *testing.T
 	calls chan buildAndDeployCall
 
@@ -150,6 +154,9 @@ type fakeBuildAndDeployer struct {
 	// Set this to simulate the build failing. Do not set this directly, use fixture.SetNextBuildFailure
 	nextBuildFailure error
 
+	// Set this to simulate a slow build that only finishes when it's canceled.
+	nextBuildWaitsForCancel bool
+
 	buildLogOutput map[model.TargetID]string
 }
 
@@ -170,6 +177,9 @@ func (b *fakeBuildAndDeployer) nextBuildResult(iTarget model.ImageTarget, deploy
 }
 
 func (b *fakeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.RStore, specs []model.TargetSpec, state store.BuildStateSet) (store.BuildResultSet, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	b.buildCount++
 
 	call := buildAndDeployCall{count: b.buildCount, specs: specs, state: state}
@@ -246,6 +256,12 @@ func (b *fakeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.RSto
 	b.nextLiveUpdateContainerIDs = nil
 	b.nextDockerComposeContainerID = ""
 
+	if b.nextBuildWaitsForCancel {
+		b.nextBuildWaitsForCancel = false
+		<-ctx.Done()
+		return store.BuildResultSet{}, ctx.Err()
+	}
+
 	err := b.nextBuildFailure
 	b.nextBuildFailure = nil
 
@@ -1041,6 +1057,50 @@ func (f *testFixture) testPodWithDeployID(podID string, manifest model.Manifest,
 		Build()
 }
 
+func TestCrashRestartPolicy(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	policy := model.OnFailureRestartPolicy(1)
+	policy.InitialBackoff = time.Millisecond
+	manifest := f.newManifest("foobar").WithRestartPolicy(policy)
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+
+	f.podEvent(f.testPod("my-pod", manifest, "CrashLoopBackOff", time.Now()))
+	f.nextCall()
+	f.waitForCompletedBuildCount(2)
+	f.withManifestState("foobar", func(ms store.ManifestState) {
+		assert.Equal(t, model.BuildReasonFlagCrash, ms.LastBuild().Reason)
+		assert.Contains(t, ms.LastBuild().Log.String(),
+			"Restarting after crash (attempt 1 of 1): pod my-pod is in CrashLoopBackOff")
+		assert.Equal(t, 1, ms.CrashRestartCount)
+	})
+
+	// It's still crashing after the restart, so we give up.
+	f.podEvent(f.testPod("my-pod", manifest, "CrashLoopBackOff", time.Now()))
+	f.WaitUntilManifestState("gave up restarting", "foobar", func(ms store.ManifestState) bool {
+		return ms.GaveUpRestarting
+	})
+	f.assertNoCall("we should give up restarting")
+	f.WaitUntil("alert logged", func(st store.EngineState) bool {
+		return strings.Contains(st.Log.String(), "foobar crashed (pod my-pod is in CrashLoopBackOff) after 1 restarts. Giving up")
+	})
+
+	// Any other build resets the restart count.
+	f.fsWatcher.events <- watch.NewFileEvent(f.JoinPath("main.go"))
+	f.nextCall()
+	f.waitForCompletedBuildCount(3)
+	f.withManifestState("foobar", func(ms store.ManifestState) {
+		assert.False(t, ms.GaveUpRestarting)
+		assert.Equal(t, 0, ms.CrashRestartCount)
+	})
+
+	assert.NoError(t, f.Stop())
+}
+
 func TestPodEvent(t *testing.T) {
 	f := newTestFixture(t)
 	defer f.TearDown()
@@ -1245,7 +1305,8 @@ func TestPodUnexpectedContainerStartsImageBuild(t *testing.T) {
 		StartTime:    time.Now(),
 	})
 	f.store.Dispatch(BuildCompleteAction{
-		Result: containerResultSet(manifest, "theOriginalContainer"),
+		ManifestName: manifest.Name,
+		Result:       containerResultSet(manifest, "theOriginalContainer"),
 	})
 	f.setDeployIDForManifest(manifest, podbuilder.FakeDeployID)
 
@@ -1291,7 +1352,8 @@ func TestPodUnexpectedContainerStartsImageBuildOutOfOrderEvents(t *testing.T) {
 	// ...and finish the build. Even though this action comes in AFTER the pod
 	// event w/ unexpected container,  we should still be able to detect the mismatch.
 	f.store.Dispatch(BuildCompleteAction{
-		Result: containerResultSet(manifest, "theOriginalContainer"),
+		ManifestName: manifest.Name,
+		Result:       containerResultSet(manifest, "theOriginalContainer"),
 	})
 
 	f.WaitUntilManifestState("NeedsRebuildFromCrash set to True", "foobar", func(ms store.ManifestState) bool {
@@ -1325,7 +1387,8 @@ func TestPodUnexpectedContainerAfterSuccessfulUpdate(t *testing.T) {
 	})
 	podStartTime := time.Now()
 	f.store.Dispatch(BuildCompleteAction{
-		Result: containerResultSet(manifest, "normal-container-id"),
+		ManifestName: manifest.Name,
+		Result:       containerResultSet(manifest, "normal-container-id"),
 	})
 	f.setDeployIDForManifest(manifest, podbuilder.FakeDeployID)
 
@@ -1355,7 +1418,8 @@ func TestPodUnexpectedContainerAfterSuccessfulUpdate(t *testing.T) {
 		WithCreationTime(podStartTime).
 		Build())
 	f.store.Dispatch(BuildCompleteAction{
-		Result: containerResultSet(manifest, "normal-container-id"),
+		ManifestName: manifest.Name,
+		Result:       containerResultSet(manifest, "normal-container-id"),
 	})
 
 	f.WaitUntilManifestState("NeedsRebuildFromCrash set to True", "foobar", func(ms store.ManifestState) bool {
@@ -2379,7 +2443,7 @@ func TestDockerComposeBuildCompletedSetsStatusToUpIfSuccessful(t *testing.T) {
 func TestEmptyTiltfile(t *testing.T) {
 	f := newTestFixture(t)
 	f.WriteFile("Tiltfile", "")
-	go f.upper.Start(f.ctx, []string{}, model.TiltBuild{}, false, f.JoinPath("Tiltfile"), true, model.SailModeDisabled, analytics.OptIn)
+	go f.upper.Start(f.ctx, []string{}, nil, model.TiltBuild{}, false, f.JoinPath("Tiltfile"), true, model.SailModeDisabled, analytics.OptIn, 0, false, store.EngineModeUp, 0)
 	f.WaitUntil("build is set", func(st store.EngineState) bool {
 		return !st.TiltfileState.LastBuild().Empty()
 	})
@@ -2584,6 +2648,65 @@ alert-injes…┊ ghij`)
 	assert.Nil(t, err)
 }
 
+func TestApproveK8sChangesRebuilds(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	manifest := f.newManifest("fe")
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+
+	changes := []k8s.EntityDiff{{Kind: "Deployment", Name: "fe", Recreate: true}}
+	f.store.Dispatch(NewK8sChangesPendingAction(manifest.Name, changes))
+	f.WaitUntilManifestState("changes pending", manifest.Name, func(ms store.ManifestState) bool {
+		return len(ms.PendingK8sChanges) > 0
+	})
+
+	f.store.Dispatch(store.ApproveK8sChangesAction{ManifestName: manifest.Name})
+	f.nextCall("rebuild after approval")
+	f.waitForCompletedBuildCount(2)
+
+	f.WaitUntilManifestState("changes cleared", manifest.Name, func(ms store.ManifestState) bool {
+		return len(ms.PendingK8sChanges) == 0 && len(ms.ApprovedK8sChanges) == 0
+	})
+
+	err := f.Stop()
+	assert.NoError(t, err)
+}
+
+func TestAwaitingApprovalIsNotABuildError(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	manifest := f.newManifest("fe")
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+
+	changes := []k8s.EntityDiff{{Kind: "Deployment", Name: "fe", Recreate: true}}
+	f.store.Dispatch(NewK8sChangesPendingAction(manifest.Name, changes))
+	f.SetNextBuildFailure(AwaitingApprovalErrorf("Waiting for approval"))
+	f.fsWatcher.events <- watch.NewFileEvent(f.JoinPath("main.go"))
+	f.nextCall()
+	f.waitForCompletedBuildCount(2)
+
+	f.withManifestState(manifest.Name, func(ms store.ManifestState) {
+		assert.NoError(t, ms.LastBuild().Error)
+		assert.Equal(t, changes, ms.PendingK8sChanges)
+
+		// The build consumed the file change.
+		pending, _ := ms.HasPendingChanges()
+		assert.False(t, pending)
+	})
+	f.assertNoCall("waiting for approval shouldn't trigger another build")
+
+	err := f.Stop()
+	assert.NoError(t, err)
+}
+
 func TestTiltfileChangedFilesOnlyLoggedAfterFirstBuild(t *testing.T) {
 	f := newTestFixture(t)
 	defer f.TearDown()
@@ -2766,6 +2889,7 @@ func newTestFixture(t *testing.T) *testFixture {
 	of := k8s.ProvideOwnerFetcher(kCli)
 	pw := NewPodWatcher(kCli, of)
 	sw := NewServiceWatcher(kCli, of, "")
+	jw := NewJobWatcher(kCli)
 
 	fakeHud := hud.NewFakeHud()
 
@@ -2789,7 +2913,7 @@ func newTestFixture(t *testing.T) *testFixture {
 
 	fakeDcc := dockercompose.NewFakeDockerComposeClient(t, ctx)
 
-	tfl := tiltfile.ProvideTiltfileLoader(ta, kCli, fakeDcc, "fake-context", k8s.EnvDockerDesktop, feature.MainDefaults)
+	tfl := tiltfile.ProvideTiltfileLoader(ta, kCli, fakeDcc, "fake-context", k8s.EnvDockerDesktop, feature.MainDefaults, dirs.NewWindmillDirAt(f.JoinPath(".windmill")))
 	cc := NewConfigsController(tfl, dockerClient)
 	dcw := NewDockerComposeEventWatcher(fakeDcc)
 	dclm := NewDockerComposeLogManager(fakeDcc)
@@ -2802,6 +2926,12 @@ func newTestFixture(t *testing.T) *testFixture {
 	ghc := &github.FakeClient{}
 	sc := &client.FakeSailClient{}
 	ewm := NewEventWatchManager(kCli, clockwork.NewRealClock())
+	lsc := NewLocalServeController()
+	cic := NewCIController()
+	dc := NewDisableController(kCli, fakeDcc)
+	hc := NewHistoryController(dirs.NewWindmillDirAt(f.JoinPath(".windmill")))
+	rc := NewRestartController()
+	prc := NewPruneController(kCli, k8s.DefaultNamespace, "")
 
 	ret := &testFixture{
 		TempDirFixture:        f,
@@ -2831,8 +2961,8 @@ func newTestFixture(t *testing.T) *testFixture {
 	}
 	tvc := NewTiltVersionChecker(func() github.Client { return ghc }, tiltVersionCheckTimerMaker)
 
-	subs := ProvideSubscribers(fakeHud, pw, sw, plm, pfc, fwm, bc, ic, cc, dcw, dclm, pm, sm, ar, hudsc, sc, tvc, tas, ewm)
-	ret.upper = NewUpper(ctx, st, subs)
+	subs := ProvideSubscribers(fakeHud, pw, sw, jw, plm, pfc, fwm, bc, ic, cc, dcw, dclm, pm, sm, ar, hudsc, sc, tvc, tas, ewm, lsc, cic, dc, hc, rc, prc)
+	ret.upper = NewUpper(ctx, st, subs, hc)
 
 	go func() {
 		fakeHud.Run(ctx, ret.upper.Dispatch, hud.DefaultRefreshInterval)
@@ -2932,7 +3062,7 @@ func (f *testFixture) SetNextBuildFailure(err error) {
 	// Don't set the nextBuildFailure flag when a completed build needs to be processed
 	// by the state machine.
 	f.WaitUntil("build complete processed", func(state store.EngineState) bool {
-		return state.CurrentlyBuilding == ""
+		return len(state.CurrentlyBuilding) == 0
 	})
 	_ = f.store.RLockState()
 	f.b.nextBuildFailure = err
@@ -3258,7 +3388,7 @@ func (f *testFixture) setupDCFixture() (redis, server model.Manifest) {
 
 	f.dcc.ServicesOutput = "redis\nserver\n"
 
-	tlr, err := f.tfl.Load(f.ctx, f.JoinPath("Tiltfile"), nil)
+	tlr, err := f.tfl.Load(f.ctx, f.JoinPath("Tiltfile"), nil, nil)
 	if err != nil {
 		f.T().Fatal(err)
 	}
@@ -44,6 +44,14 @@ func watchableTargetsForManifests(manifests []model.Manifest) []WatchableTarget
 			}
 		}
 
+		if m.IsLocal() {
+			lt := m.LocalTarget()
+			if !seen[lt.ID()] && len(lt.Dependencies()) > 0 {
+				watchable = append(watchable, lt)
+				seen[lt.ID()] = true
+			}
+		}
+
 		for _, iTarget := range m.ImageTargets {
 			if !seen[iTarget.ID()] {
 				watchable = append(watchable, iTarget)
@@ -133,7 +141,15 @@ func (w *WatchManager) diff(ctx context.Context, st store.RStore) (setup []Watch
 	setup = []WatchableTarget{}
 	teardown = []model.TargetID{}
 
-	watchable := watchableTargetsForManifests(state.Manifests())
+	var manifests []model.Manifest
+	for _, mt := range state.Targets() {
+		if mt.State != nil && mt.State.Disabled {
+			continue
+		}
+		manifests = append(manifests, mt.Manifest)
+	}
+
+	watchable := watchableTargetsForManifests(manifests)
 	targetsToProcess := make(map[model.TargetID]WatchableTarget)
 	for _, w := range watchable {
 		targetsToProcess[w.ID()] = w
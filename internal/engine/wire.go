@@ -42,6 +42,7 @@ var DeployerBaseWireSet = wire.NewSet(
 	NewLiveUpdateBuildAndDeployer,
 	NewDockerComposeBuildAndDeployer,
 	NewImageAndCacheBuilder,
+	NewLocalTargetBuildAndDeployer,
 	DefaultBuildOrder,
 
 	wire.Bind(new(BuildAndDeployer), new(CompositeBuildAndDeployer)),
@@ -52,6 +53,7 @@ var DeployerBaseWireSet = wire.NewSet(
 var DeployerWireSetTest = wire.NewSet(
 	DeployerBaseWireSet,
 	containerupdate.NewSyncletManagerForTests,
+	wire.Value(build.BuildKitAddr("")),
 
 	// A fake synclet wrapped in a GRPC interface
 	synclet.FakeGRPCWrapper,
@@ -72,7 +74,7 @@ func provideBuildAndDeployer(
 	sCli *synclet.TestSyncletClient,
 	dcc dockercompose.DockerComposeClient,
 	clock build.Clock,
-	kp KINDPusher,
+	loader ClusterImageLoader,
 	analytics *analytics.TiltAnalytics) (BuildAndDeployer, error) {
 	wire.Build(
 		DeployerWireSetTest,
@@ -89,7 +91,7 @@ func provideImageBuildAndDeployer(
 	env k8s.Env,
 	dir *dirs.WindmillDir,
 	clock build.Clock,
-	kp KINDPusher,
+	loader ClusterImageLoader,
 	analytics *analytics.TiltAnalytics) (*ImageBuildAndDeployer, error) {
 	wire.Build(
 		DeployerWireSetTest,
@@ -117,6 +119,7 @@ func provideDockerComposeBuildAndDeployer(
 
 		// EnvNone ensures that we get an exploding k8s client.
 		wire.Value(k8s.Env(k8s.EnvNone)),
+		wire.Value(k8s.NamespaceOverride("")),
 		k8s.ProvideClientConfig,
 		k8s.ProvideConfigNamespace,
 		k8s.ProvideKubeContext,
@@ -25,7 +25,7 @@ import (
 
 // Injectors from wire.go:
 
-func provideBuildAndDeployer(ctx context.Context, docker2 docker.Client, kClient k8s.Client, dir *dirs.WindmillDir, env k8s.Env, updateMode UpdateModeFlag, sCli *synclet.TestSyncletClient, dcc dockercompose.DockerComposeClient, clock build.Clock, kp KINDPusher, analytics2 *analytics.TiltAnalytics) (BuildAndDeployer, error) {
+func provideBuildAndDeployer(ctx context.Context, docker2 docker.Client, kClient k8s.Client, dir *dirs.WindmillDir, env k8s.Env, updateMode UpdateModeFlag, sCli *synclet.TestSyncletClient, dcc dockercompose.DockerComposeClient, clock build.Clock, loader ClusterImageLoader, analytics2 *analytics.TiltAnalytics) (BuildAndDeployer, error) {
 	dockerContainerUpdater := containerupdate.NewDockerContainerUpdater(docker2)
 	syncletClient, err := synclet.FakeGRPCWrapper(ctx, sCli)
 	if err != nil {
@@ -45,19 +45,22 @@ func provideBuildAndDeployer(ctx context.Context, docker2 docker.Client, kClient
 	imageBuilder := build.DefaultImageBuilder(dockerImageBuilder)
 	cacheBuilder := build.NewCacheBuilder(docker2)
 	execCustomBuilder := build.NewExecCustomBuilder(docker2, clock)
-	imageBuildAndDeployer := NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, kClient, env, analytics2, engineUpdateMode, clock, runtime, kp)
+	buildKitAddr := _wireBuildKitAddrValue
+	imageBuildAndDeployer := NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, kClient, env, analytics2, engineUpdateMode, clock, runtime, loader, buildKitAddr)
 	engineImageAndCacheBuilder := NewImageAndCacheBuilder(imageBuilder, cacheBuilder, execCustomBuilder, engineUpdateMode)
 	dockerComposeBuildAndDeployer := NewDockerComposeBuildAndDeployer(dcc, docker2, engineImageAndCacheBuilder, clock)
-	buildOrder := DefaultBuildOrder(liveUpdateBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, engineUpdateMode, env, runtime)
+	localTargetBuildAndDeployer := NewLocalTargetBuildAndDeployer(clock)
+	buildOrder := DefaultBuildOrder(liveUpdateBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, localTargetBuildAndDeployer, engineUpdateMode, env, runtime)
 	compositeBuildAndDeployer := NewCompositeBuildAndDeployer(buildOrder)
 	return compositeBuildAndDeployer, nil
 }
 
 var (
-	_wireLabelsValue = dockerfile.Labels{}
+	_wireLabelsValue       = dockerfile.Labels{}
+	_wireBuildKitAddrValue = build.BuildKitAddr("")
 )
 
-func provideImageBuildAndDeployer(ctx context.Context, docker2 docker.Client, kClient k8s.Client, env k8s.Env, dir *dirs.WindmillDir, clock build.Clock, kp KINDPusher, analytics2 *analytics.TiltAnalytics) (*ImageBuildAndDeployer, error) {
+func provideImageBuildAndDeployer(ctx context.Context, docker2 docker.Client, kClient k8s.Client, env k8s.Env, dir *dirs.WindmillDir, clock build.Clock, loader ClusterImageLoader, analytics2 *analytics.TiltAnalytics) (*ImageBuildAndDeployer, error) {
 	labels := _wireLabelsValue
 	dockerImageBuilder := build.NewDockerImageBuilder(docker2, labels)
 	imageBuilder := build.DefaultImageBuilder(dockerImageBuilder)
@@ -69,7 +72,8 @@ func provideImageBuildAndDeployer(ctx context.Context, docker2 docker.Client, kC
 	if err != nil {
 		return nil, err
 	}
-	imageBuildAndDeployer := NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, kClient, env, analytics2, updateMode, clock, runtime, kp)
+	buildKitAddr := _wireBuildKitAddrValue
+	imageBuildAndDeployer := NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, kClient, env, analytics2, updateMode, clock, runtime, loader, buildKitAddr)
 	return imageBuildAndDeployer, nil
 }
 
@@ -87,7 +91,8 @@ func provideDockerComposeBuildAndDeployer(ctx context.Context, dcCli dockercompo
 	updateModeFlag := _wireEngineUpdateModeFlagValue
 	env := _wireEnvValue
 	portForwarder := k8s.ProvidePortForwarder()
-	clientConfig := k8s.ProvideClientConfig()
+	namespaceOverride := _wireNamespaceOverrideValue
+	clientConfig := k8s.ProvideClientConfig(namespaceOverride)
 	namespace := k8s.ProvideConfigNamespace(clientConfig)
 	config, err := k8s.ProvideKubeConfig(clientConfig)
 	if err != nil {
@@ -99,7 +104,7 @@ func provideDockerComposeBuildAndDeployer(ctx context.Context, dcCli dockercompo
 	}
 	int2 := provideKubectlLogLevelInfo()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
-	client := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	client := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, namespaceOverride, kubectlRunner, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, client)
 	updateMode, err := ProvideUpdateMode(updateModeFlag, env, runtime)
 	if err != nil {
@@ -113,6 +118,7 @@ func provideDockerComposeBuildAndDeployer(ctx context.Context, dcCli dockercompo
 var (
 	_wireEngineUpdateModeFlagValue = UpdateModeFlag(UpdateModeAuto)
 	_wireEnvValue                  = k8s.Env(k8s.EnvNone)
+	_wireNamespaceOverrideValue    = k8s.NamespaceOverride("")
 )
 
 // wire.go:
@@ -120,12 +126,13 @@ var (
 var DeployerBaseWireSet = wire.NewSet(wire.Value(dockerfile.Labels{}), wire.Value(UpperReducer), minikube.ProvideMinikubeClient, build.DefaultImageBuilder, build.NewCacheBuilder, build.NewDockerImageBuilder, build.NewExecCustomBuilder, wire.Bind(new(build.CustomBuilder), new(build.ExecCustomBuilder)), NewImageBuildAndDeployer, containerupdate.NewDockerContainerUpdater, containerupdate.NewSyncletUpdater, containerupdate.NewExecUpdater, NewLiveUpdateBuildAndDeployer,
 	NewDockerComposeBuildAndDeployer,
 	NewImageAndCacheBuilder,
+	NewLocalTargetBuildAndDeployer,
 	DefaultBuildOrder, wire.Bind(new(BuildAndDeployer), new(CompositeBuildAndDeployer)), NewCompositeBuildAndDeployer,
 	ProvideUpdateMode,
 )
 
 var DeployerWireSetTest = wire.NewSet(
-	DeployerBaseWireSet, containerupdate.NewSyncletManagerForTests, synclet.FakeGRPCWrapper,
+	DeployerBaseWireSet, containerupdate.NewSyncletManagerForTests, wire.Value(build.BuildKitAddr("")), synclet.FakeGRPCWrapper,
 )
 
 var DeployerWireSet = wire.NewSet(
@@ -1,6 +1,8 @@
 package hud
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell"
@@ -39,6 +41,24 @@ func makeBuildStatus(res view.Resource, triggerMode model.TriggerMode) buildStat
 		}
 	}
 
+	if res.Disabled {
+		return buildStatus{
+			status: "Disabled",
+			muted:  true,
+		}
+	}
+
+	if len(res.WaitingOn) > 0 && res.CurrentBuild.Empty() {
+		names := make([]string, len(res.WaitingOn))
+		for i, mn := range res.WaitingOn {
+			names[i] = mn.String()
+		}
+		return buildStatus{
+			status: fmt.Sprintf("Waiting on %s", strings.Join(names, ", ")),
+			muted:  true,
+		}
+	}
+
 	if !res.CurrentBuild.Empty() && !res.CurrentBuild.Reason.IsCrashOnly() {
 		status = "In prog."
 		duration = time.Since(res.CurrentBuild.StartTime)
@@ -53,8 +73,14 @@ func makeBuildStatus(res view.Resource, triggerMode model.TriggerMode) buildStat
 		reason = res.PendingBuildReason
 	} else if !res.LastBuild().FinishTime.IsZero() {
 		lastBuild := res.LastBuild()
-		if lastBuild.Error != nil {
+		if lastBuild.Superseded {
+			status = "Superseded"
+		} else if len(res.PendingChanges) > 0 {
+			status = "Needs approval"
+		} else if lastBuild.Error != nil {
 			status = "Error"
+		} else if lastBuild.NoOp {
+			status = "No-op"
 		} else {
 			status = "OK"
 		}
@@ -7,6 +7,7 @@ import (
 
 const resourcesScollerName = "resources"
 const alertScrollerName = "alert"
+const changesScrollerName = "changes"
 
 func (h *Hud) activeScroller() scroller {
 	am := h.activeModal()
@@ -20,6 +21,8 @@ func (h *Hud) activeScroller() scroller {
 func (h *Hud) activeModal() modal {
 	if h.currentViewState.AlertMessage != "" {
 		return makeAlertModal(h.r.rty)
+	} else if h.currentViewState.ReviewChanges != "" {
+		return makeChangesModal(h.r.rty)
 	} else {
 		return nil
 	}
@@ -53,3 +56,17 @@ func makeAlertModal(r rty.RTY) modal {
 func (am alertModal) Close(vs *view.ViewState) {
 	vs.AlertMessage = ""
 }
+
+type changesModal struct {
+	rty.TextScroller
+}
+
+var _ modal = changesModal{}
+
+func makeChangesModal(r rty.RTY) modal {
+	return changesModal{r.TextScroller(changesScrollerName)}
+}
+
+func (cm changesModal) Close(vs *view.ViewState) {
+	vs.ReviewChanges = ""
+}
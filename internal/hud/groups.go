@@ -0,0 +1,149 @@
+package hud
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/rty"
+	"github.com/windmilleng/tilt/internal/sliceutils"
+)
+
+// The group for resources without labels, when other resources have them.
+const unlabeledGroup = "unlabeled"
+
+// A row in the resource list: either a resource, or the header of a group of resources.
+//
+// If no resources have labels, we don't show any groups, and every row is a resource.
+// Otherwise, every label gets a group with all the resources with that label,
+// so a resource with two labels shows up twice.
+type resourceRow struct {
+	// The label that this row belongs to, or is the header of.
+	// Empty for resources that aren't in a group, like the Tiltfile.
+	group string
+
+	// Index into View.Resources. -1 for group headers.
+	resourceIndex int
+}
+
+func (r resourceRow) isGroupHeader() bool {
+	return r.resourceIndex < 0
+}
+
+// A name for the row that's unique in the resource list, so that the scroller
+// can keep track of the selection.
+func (r resourceRow) scrollName(v view.View) string {
+	if r.isGroupHeader() {
+		return fmt.Sprintf("group:%s", r.group)
+	}
+	name := v.Resources[r.resourceIndex].Name.String()
+	if r.group == "" {
+		return name
+	}
+	return fmt.Sprintf("%s/%s", r.group, name)
+}
+
+func resourceRows(v view.View, vs view.ViewState) []resourceRow {
+	var rows []resourceRow
+	groups := make(map[string][]int)
+	hasLabels := false
+	for i, res := range v.Resources {
+		if res.IsTiltfile {
+			continue
+		}
+		for _, l := range res.Labels {
+			groups[l] = append(groups[l], i)
+			hasLabels = true
+		}
+	}
+
+	if !hasLabels {
+		for i := range v.Resources {
+			rows = append(rows, resourceRow{resourceIndex: i})
+		}
+		return rows
+	}
+
+	var labels []string
+	for l := range groups {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+
+	for i, res := range v.Resources {
+		if res.IsTiltfile {
+			rows = append(rows, resourceRow{resourceIndex: i})
+		} else if len(res.Labels) == 0 {
+			groups[unlabeledGroup] = append(groups[unlabeledGroup], i)
+		}
+	}
+	if _, ok := groups[unlabeledGroup]; ok && !sliceutils.Contains(labels, unlabeledGroup) {
+		labels = append(labels, unlabeledGroup)
+	}
+
+	for _, l := range labels {
+		rows = append(rows, resourceRow{group: l, resourceIndex: -1})
+		if vs.CollapsedGroups[l] {
+			continue
+		}
+		for _, i := range groups[l] {
+			rows = append(rows, resourceRow{group: l, resourceIndex: i})
+		}
+	}
+	return rows
+}
+
+// The resources in the given group.
+func groupResources(v view.View, group string) []view.Resource {
+	var result []view.Resource
+	for _, res := range v.Resources {
+		if res.IsTiltfile {
+			continue
+		}
+		if group == unlabeledGroup && len(res.Labels) == 0 {
+			result = append(result, res)
+			continue
+		}
+		if sliceutils.Contains(res.Labels, group) {
+			result = append(result, res)
+		}
+	}
+	return result
+}
+
+func renderGroupHeader(group string, resources []view.Resource, collapsed bool, selected bool) rty.Component {
+	sb := rty.NewStringBuilder()
+	p := "▼"
+	if collapsed {
+		p = "▶"
+	}
+	color := cLightText
+	if selected {
+		color = tcell.ColorDefault
+	}
+	sb.Fg(color).Textf("%s %s ", p, strings.ToUpper(group))
+	sb.Fg(cLightText).Textf("(%d)", len(resources))
+
+	errorCount := 0
+	for _, res := range resources {
+		if isInError(res) {
+			errorCount++
+		}
+	}
+
+	l := rty.NewConcatLayout(rty.DirHor)
+	l.Add(sb.Build())
+	l.Add(rty.TextString(" "))
+	l.AddDynamic(rty.Fg(rty.NewFillerString('─'), cLightText))
+	if errorCount > 0 {
+		s := "error"
+		if errorCount > 1 {
+			s = "errors"
+		}
+		l.Add(rty.ColoredString(fmt.Sprintf(" ✖ %d %s ", errorCount, s), cBad))
+	}
+	return rty.OneLine(l)
+}
@@ -0,0 +1,71 @@
+package hud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestResourceRowsWithoutLabels(t *testing.T) {
+	v := groupsTestView(nil, nil)
+	rows := resourceRows(v, view.ViewState{})
+	assert.Equal(t, []string{"(Tiltfile)", "api", "db"}, scrollNames(v, rows))
+}
+
+func TestResourceRowsGrouped(t *testing.T) {
+	v := groupsTestView([]string{"backend", "payments"}, nil)
+	v.Resources = append(v.Resources, view.Resource{Name: "cache", Labels: []string{"backend"}})
+
+	rows := resourceRows(v, view.ViewState{})
+	assert.Equal(t, []string{
+		"(Tiltfile)",
+		"group:backend",
+		"backend/api",
+		"backend/cache",
+		"group:payments",
+		"payments/api",
+		"group:unlabeled",
+		"unlabeled/db",
+	}, scrollNames(v, rows))
+}
+
+func TestResourceRowsCollapsedGroup(t *testing.T) {
+	v := groupsTestView([]string{"backend"}, []string{"backend"})
+	vs := view.ViewState{CollapsedGroups: map[string]bool{"backend": true}}
+
+	rows := resourceRows(v, vs)
+	assert.Equal(t, []string{"(Tiltfile)", "group:backend"}, scrollNames(v, rows))
+}
+
+func TestSelectedResourceGrouped(t *testing.T) {
+	v := groupsTestView([]string{"backend"}, nil)
+
+	i, res := selectedResource(v, view.ViewState{SelectedIndex: 1})
+	assert.Equal(t, -1, i)
+	assert.Equal(t, model.ManifestName(""), res.Name)
+
+	i, res = selectedResource(v, view.ViewState{SelectedIndex: 4})
+	assert.Equal(t, 2, i)
+	assert.Equal(t, model.ManifestName("db"), res.Name)
+}
+
+func groupsTestView(apiLabels, dbLabels []string) view.View {
+	return view.View{
+		Resources: []view.Resource{
+			{Name: view.TiltfileResourceName, IsTiltfile: true},
+			{Name: "api", Labels: apiLabels},
+			{Name: "db", Labels: dbLabels},
+		},
+	}
+}
+
+func scrollNames(v view.View, rows []resourceRow) []string {
+	var result []string
+	for _, row := range rows {
+		result = append(result, row.scrollName(v))
+	}
+	return result
+}
@@ -144,7 +144,10 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 				// If we have an endpoint(s), open the first one
 				// TODO(nick): We might need some hints on what load balancer to
 				// open if we have multiple, or what path to default to on the opened manifest.
-				_, selected := h.selectedResource()
+				i, selected := h.selectedResource()
+				if i < 0 {
+					break
+				}
 				if len(selected.Endpoints) > 0 {
 					h.recordInteraction("open_preview")
 					err := browser.OpenURL(selected.Endpoints[0])
@@ -170,6 +173,35 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 				h.refreshSelectedIndex()
 			case r == 'q': // [Q]uit
 				escape()
+			case r == 'd': // [D]isable/enable
+				i, selected := h.selectedResource()
+				if i < 0 || selected.IsTiltfile {
+					break
+				}
+				h.recordInteraction("toggle_disabled")
+				dispatch(store.SetResourceEnabledAction{
+					ManifestName: selected.Name,
+					Enabled:      selected.Disabled,
+				})
+			case r == 'c': // Review [C]hanges
+				i, selected := h.selectedResource()
+				if i < 0 {
+					break
+				}
+				if len(selected.PendingChanges) > 0 {
+					h.recordInteraction("review_changes")
+					h.currentViewState.ReviewChanges = selected.Name
+				} else {
+					h.currentViewState.AlertMessage = fmt.Sprintf("no changes waiting for approval for resource '%s'", selected.Name)
+				}
+			case r == 'a': // [A]pprove changes
+				mn := h.currentViewState.ReviewChanges
+				if mn == "" {
+					break
+				}
+				h.recordInteraction("approve_changes")
+				dispatch(store.ApproveK8sChangesAction{ManifestName: mn})
+				h.currentViewState.ReviewChanges = ""
 			case r == 'R': // hidden key for recovering from printf junk during demos
 				h.r.screen.Sync()
 			case r == 'x':
@@ -202,10 +234,10 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 			}
 			h.refreshSelectedIndex()
 		case tcell.KeyEnter:
-			if len(h.currentView.Resources) == 0 {
+			i, r := h.selectedResource()
+			if i < 0 {
 				break
 			}
-			_, r := h.selectedResource()
 
 			if h.webURL.Empty() {
 				break
@@ -215,11 +247,9 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 			h.a.Incr("ui.interactions.open_log", map[string]string{"is_tiltfile": strconv.FormatBool(r.Name == view.TiltfileResourceName)})
 			_ = browser.OpenURL(url.String())
 		case tcell.KeyRight:
-			i, _ := h.selectedResource()
-			h.currentViewState.Resources[i].CollapseState = view.CollapseNo
+			h.setSelectedCollapsed(false)
 		case tcell.KeyLeft:
-			i, _ := h.selectedResource()
-			h.currentViewState.Resources[i].CollapseState = view.CollapseYes
+			h.setSelectedCollapsed(true)
 		case tcell.KeyHome:
 			h.activeScroller().Top()
 		case tcell.KeyEnd:
@@ -284,6 +314,13 @@ func (h *Hud) setView(ctx context.Context, view view.View) error {
 	h.currentView = view
 	h.refreshSelectedIndex()
 
+	// Close the review once the changes are applied (or superseded).
+	if mn := h.currentViewState.ReviewChanges; mn != "" {
+		if res, ok := view.Resource(mn); !ok || len(res.PendingChanges) == 0 {
+			h.currentViewState.ReviewChanges = ""
+		}
+	}
+
 	// if the hud isn't running, make sure new logs are visible on stdout
 	logLen := view.Log.Len()
 	if !h.isRunning && h.currentViewState.ProcessedLogByteCount < logLen {
@@ -342,17 +379,52 @@ func (h *Hud) refreshSelectedIndex() {
 	h.currentViewState.SelectedIndex = i
 }
 
+// Expands or collapses the selected resource or group.
+func (h *Hud) setSelectedCollapsed(collapsed bool) {
+	row, ok := selectedRow(h.currentView, h.currentViewState)
+	if !ok {
+		return
+	}
+
+	if row.isGroupHeader() {
+		if h.currentViewState.CollapsedGroups == nil {
+			h.currentViewState.CollapsedGroups = make(map[string]bool)
+		}
+		h.currentViewState.CollapsedGroups[row.group] = collapsed
+		return
+	}
+
+	if row.resourceIndex >= len(h.currentViewState.Resources) {
+		return
+	}
+	var state view.CollapseState = view.CollapseNo
+	if collapsed {
+		state = view.CollapseYes
+	}
+	h.currentViewState.Resources[row.resourceIndex].CollapseState = state
+}
+
 func (h *Hud) selectedResource() (i int, resource view.Resource) {
 	return selectedResource(h.currentView, h.currentViewState)
 }
 
+// Returns the index of the selected resource in view.Resources, or -1 if
+// no resource is selected (e.g., if a group header is selected).
 func selectedResource(view view.View, state view.ViewState) (i int, resource view.Resource) {
-	i = state.SelectedIndex
-	if i >= 0 && i < len(view.Resources) {
-		resource = view.Resources[i]
+	row, ok := selectedRow(view, state)
+	if !ok || row.isGroupHeader() {
+		return -1, resource
 	}
-	return i, resource
+	return row.resourceIndex, view.Resources[row.resourceIndex]
+}
 
+func selectedRow(view view.View, state view.ViewState) (resourceRow, bool) {
+	rows := resourceRows(view, state)
+	i := state.SelectedIndex
+	if i < 0 || i >= len(rows) {
+		return resourceRow{}, false
+	}
+	return rows[i], true
 }
 
 var _ store.Subscriber = &Hud{}
@@ -12,6 +12,7 @@ import (
 	"github.com/windmilleng/tilt/internal/dockercompose"
 	"github.com/windmilleng/tilt/internal/hud/view"
 	"github.com/windmilleng/tilt/internal/rty"
+	"github.com/windmilleng/tilt/internal/store"
 	"github.com/windmilleng/tilt/pkg/model"
 )
 
@@ -50,6 +51,7 @@ var cPending = tcell.ColorYellow
 
 var statusColors = map[string]tcell.Color{
 	"Running":                          cGood,
+	store.PodStatusPendingReadiness:    cPending,
 	"ContainerCreating":                cPending,
 	"Pending":                          cPending,
 	"PodInitializing":                  cPending,
@@ -63,6 +65,11 @@ var statusColors = map[string]tcell.Color{
 	string(dockercompose.StatusUp):     cGood,
 	string(dockercompose.StatusDown):   cBad,
 	"Completed":                        cGood,
+	string(store.JobStatusActive):      cPending,
+	string(store.JobStatusComplete):    cGood,
+	string(store.JobStatusFailed):      cBad,
+	"Crashed":                          cBad,
+	"Exited":                           cBad,
 }
 
 func (r *Renderer) layout(v view.View, vs view.ViewState) rty.Component {
@@ -81,6 +88,8 @@ func (r *Renderer) layout(v view.View, vs view.ViewState) rty.Component {
 
 	ret = r.maybeAddFullScreenLog(v, vs, ret)
 
+	ret = r.maybeAddChangesModal(v, vs, ret)
+
 	ret = r.maybeAddAlertModal(vs, ret)
 
 	return ret
@@ -118,6 +127,40 @@ func (r *Renderer) maybeAddAlertModal(vs view.ViewState, layout rty.Component) r
 	return layout
 }
 
+func (r *Renderer) maybeAddChangesModal(v view.View, vs view.ViewState, layout rty.Component) rty.Component {
+	if vs.ReviewChanges == "" {
+		return layout
+	}
+	res, ok := v.Resource(vs.ReviewChanges)
+	if !ok || len(res.PendingChanges) == 0 {
+		return layout
+	}
+
+	sl := rty.NewTextScrollLayout(changesScrollerName)
+	sb := rty.NewStringBuilder()
+	for _, c := range res.PendingChanges {
+		sb.Fg(tcell.ColorDefault).Text(c.String())
+		if c.Recreate {
+			sb.Fg(cBad).Text(" (will be deleted and re-created)")
+		}
+		sb.Text("\n")
+		for _, f := range c.Fields {
+			color := cPending
+			if f.Old == "" {
+				color = cGood
+			} else if f.New == "" {
+				color = cBad
+			}
+			sb.Fg(color).Textf("   %s\n", f)
+		}
+	}
+	sl.Add(sb.Build())
+
+	w := rty.NewWindow(sl)
+	w.SetTitle(fmt.Sprintf("Changes to %s waiting for approval", res.Name))
+	return r.renderModal(rty.Fg(w, tcell.ColorDefault), layout, false)
+}
+
 func (r *Renderer) renderLogPane(v view.View, vs view.ViewState) rty.Component {
 	tabView := NewTabView(v, vs)
 	var height int
@@ -196,6 +239,12 @@ func keyLegend(v view.View, vs view.ViewState) string {
 	if vs.AlertMessage != "" {
 		return "Tilt (l)og ┊ (esc) close alert "
 	}
+	if vs.ReviewChanges != "" {
+		return "Browse (↓ ↑) ┊ (a) approve and apply ┊ (esc) close "
+	}
+	if _, selected := selectedResource(v, vs); len(selected.PendingChanges) > 0 {
+		return "Browse (↓ ↑), Expand (→) ┊ (enter) log, (b)rowser, (c) review changes ┊ (ctrl-C) quit  "
+	}
 	return defaultKeys
 }
 
@@ -212,7 +261,8 @@ func isCrashing(res view.Resource) bool {
 		res.LastBuild().Reason.Has(model.BuildReasonFlagCrash) ||
 		res.CurrentBuild.Reason.Has(model.BuildReasonFlagCrash) ||
 		res.PendingBuildReason.Has(model.BuildReasonFlagCrash) ||
-		res.IsDC() && res.DockerComposeTarget().Status() == string(dockercompose.StatusCrash)
+		res.IsDC() && res.DockerComposeTarget().Status() == string(dockercompose.StatusCrash) ||
+		res.IsLocal() && res.LocalInfo().ServeStatus == "Crashed"
 }
 
 func (r *Renderer) renderModal(fg rty.Component, bg rty.Component, fixed bool) rty.Component {
@@ -253,21 +303,25 @@ func (r *Renderer) renderResourceHeader(v view.View) rty.Component {
 }
 
 func (r *Renderer) renderResources(v view.View, vs view.ViewState) rty.Component {
-	rs := v.Resources
+	rows := resourceRows(v, vs)
 
 	cl := rty.NewConcatLayout(rty.DirVert)
 
-	childNames := make([]string, len(rs))
-	for i, r := range rs {
-		childNames[i] = r.Name.String()
+	childNames := make([]string, len(rows))
+	for i, row := range rows {
+		childNames[i] = row.scrollName(v)
 	}
 	// the items added to `l` below must be kept in sync with `childNames` above
-	l, selectedResource := r.rty.RegisterElementScroll(resourcesScollerName, childNames)
+	l, selectedRow := r.rty.RegisterElementScroll(resourcesScollerName, childNames)
 
-	if len(rs) > 0 {
-		for i, res := range rs {
-			l.Add(r.renderResource(res, vs.Resources[i], res.TriggerMode, selectedResource == res.Name.String()))
+	for i, row := range rows {
+		selected := selectedRow == childNames[i]
+		if row.isGroupHeader() {
+			l.Add(renderGroupHeader(row.group, groupResources(v, row.group), vs.CollapsedGroups[row.group], selected))
+			continue
 		}
+		res := v.Resources[row.resourceIndex]
+		l.Add(r.renderResource(res, vs.Resources[row.resourceIndex], res.TriggerMode, selected))
 	}
 
 	cl.Add(l)
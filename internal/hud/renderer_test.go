@@ -12,6 +12,7 @@ import (
 	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/dockercompose"
 	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/k8s"
 	"github.com/windmilleng/tilt/internal/rty"
 	"github.com/windmilleng/tilt/pkg/model"
 
@@ -814,6 +815,130 @@ func TestRenderTabView(t *testing.T) {
 	rtf.run("log tab pod", 117, 20, v, vs)
 }
 
+func TestRenderGroups(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	ts := time.Now().Add(-5 * time.Minute)
+	v := view.View{
+		Resources: []view.Resource{
+			{
+				Name:       view.TiltfileResourceName,
+				IsTiltfile: true,
+				BuildHistory: []model.BuildRecord{{
+					StartTime:  ts,
+					FinishTime: ts,
+				}},
+			},
+			{
+				Name:         "api",
+				Labels:       []string{"backend", "payments"},
+				ResourceInfo: view.K8sResourceInfo{PodStatus: "Running"},
+				BuildHistory: []model.BuildRecord{{
+					StartTime:  ts,
+					FinishTime: ts,
+				}},
+				LastDeployTime: ts,
+			},
+			{
+				Name:         "db",
+				Labels:       []string{"backend"},
+				ResourceInfo: view.K8sResourceInfo{PodStatus: "CrashLoopBackOff"},
+				BuildHistory: []model.BuildRecord{{
+					StartTime:  ts,
+					FinishTime: ts,
+				}},
+				LastDeployTime: ts,
+			},
+			{
+				Name:         "lint",
+				ResourceInfo: view.LocalResourceInfo{},
+			},
+		},
+	}
+
+	vs := fakeViewState(4, view.CollapseYes)
+	rtf.run("grouped resources", 70, 20, v, vs)
+
+	vs.CollapsedGroups = map[string]bool{"backend": true}
+	rtf.run("collapsed group", 70, 20, v, vs)
+}
+
+func TestRenderPendingChanges(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	ts := time.Now().Add(-5 * time.Minute)
+	v := view.View{
+		Resources: []view.Resource{
+			{
+				Name:         "migrate",
+				ResourceInfo: view.K8sResourceInfo{},
+				BuildHistory: []model.BuildRecord{{
+					StartTime:  ts,
+					FinishTime: ts,
+				}},
+				PendingChanges: []k8s.EntityDiff{
+					{
+						Kind:      "Deployment",
+						Name:      "api",
+						Namespace: "default",
+						Fields: []k8s.FieldDiff{
+							{Path: "spec.replicas", Old: "1", New: "10"},
+							{Path: "spec.template.spec.containers[name=api].env[name=DEBUG]", Old: `{"name":"DEBUG","value":"1"}`},
+						},
+					},
+					{
+						Kind:      "Job",
+						Name:      "migrate",
+						Namespace: "default",
+						Recreate:  true,
+						Fields: []k8s.FieldDiff{
+							{Path: "spec.backoffLimit", New: "4"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	vs := fakeViewState(1, view.CollapseNo)
+	rtf.run("pending changes", 80, 20, v, vs)
+
+	vs.ReviewChanges = "migrate"
+	rtf.run("review pending changes", 80, 20, v, vs)
+}
+
+func TestRenderBrokenPortForwards(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	ts := time.Now().Add(-5 * time.Minute)
+	v := view.View{
+		Resources: []view.Resource{
+			{
+				Name:           "vigoda",
+				LastDeployTime: ts,
+				BuildHistory: []model.BuildRecord{{
+					StartTime:  ts,
+					FinishTime: ts,
+				}},
+				ResourceInfo: view.K8sResourceInfo{
+					PodName:         "vigoda-pod",
+					PodStatus:       "Running",
+					PodCreationTime: ts,
+				},
+				Endpoints: []string{"http://localhost:8080"},
+				PortForwards: []view.PortForward{
+					{LocalPort: 8080, ContainerPort: 8081, Status: "active"},
+					{LocalPort: 9000, ContainerPort: 9000, Status: "reconnecting", Error: "lost connection to pod"},
+					{LocalPort: 9229, ContainerPort: 9229, Status: "failed", Error: "pod vigoda-pod not found"},
+				},
+			},
+		},
+	}
+
+	vs := fakeViewState(1, view.CollapseNo)
+	rtf.run("broken port-forwards", 80, 20, v, vs)
+}
+
 type rendererTestFixture struct {
 	i rty.InteractiveTester
 }
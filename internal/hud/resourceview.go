@@ -92,6 +92,8 @@ func statusColor(res view.Resource) tcell.Color {
 		return cBad
 	} else if res.IsYAML() && !res.LastDeployTime.IsZero() {
 		return cGood
+	} else if res.IsLocal() && res.ResourceInfo.Status() == "" && !res.LastBuild().FinishTime.IsZero() {
+		return cGood // no serve_cmd to monitor
 	} else if !res.LastBuild().FinishTime.IsZero() && res.ResourceInfo.Status() == "" {
 		return cPending // pod status hasn't shown up yet
 	} else {
@@ -137,13 +139,15 @@ func (v *ResourceView) titleText() rty.Component {
 		return titleTextDC(i)
 	case view.K8sResourceInfo:
 		return titleTextK8s(i)
+	case view.LocalResourceInfo:
+		return titleTextLocal(i)
 	default:
 		return nil
 	}
 }
 
 func titleTextK8s(k8sInfo view.K8sResourceInfo) rty.Component {
-	status := k8sInfo.PodStatus
+	status := k8sInfo.Status()
 	if status == "" {
 		status = "Pending"
 	}
@@ -160,6 +164,13 @@ func titleTextDC(dcInfo view.DCResourceInfo) rty.Component {
 	return sb.Build()
 }
 
+func titleTextLocal(localInfo view.LocalResourceInfo) rty.Component {
+	if localInfo.Status() == "" {
+		return nil
+	}
+	return rty.TextString(localInfo.Status())
+}
+
 func (v *ResourceView) titleTextBuild() rty.Component {
 	return buildStatusCell(makeBuildStatus(v.res, v.triggerMode))
 }
@@ -176,6 +187,7 @@ func (v *ResourceView) resourceExpandedPane() rty.Component {
 	rhs.Add(v.resourceExpandedHistory())
 	rhs.Add(v.resourceExpanded())
 	rhs.Add(v.resourceExpandedEndpoints())
+	rhs.Add(v.resourceExpandedPortForwards())
 	rhs.Add(v.resourceExpandedError())
 	l.AddDynamic(rhs)
 	return l
@@ -189,6 +201,8 @@ func (v *ResourceView) resourceExpanded() rty.Component {
 		return v.resourceExpandedK8s()
 	case view.YAMLResourceInfo:
 		return v.resourceExpandedYAML()
+	case view.LocalResourceInfo:
+		return v.resourceExpandedLocal()
 	default:
 		return rty.EmptyLayout
 	}
@@ -230,6 +244,23 @@ func (v *ResourceView) resourceExpandedDC() rty.Component {
 	return rty.OneLine(l)
 }
 
+func (v *ResourceView) resourceExpandedLocal() rty.Component {
+	localInfo := v.res.LocalInfo()
+	if localInfo.ServePID == 0 {
+		return rty.EmptyLayout
+	}
+
+	l := rty.NewConcatLayout(rty.DirHor)
+	sb := rty.NewStringBuilder()
+	sb.Fg(cLightText).Text("PID: ")
+	sb.Fg(tcell.ColorDefault).Textf("%d", localInfo.ServePID)
+	l.Add(sb.Build())
+	l.Add(rty.TextString(" "))
+	l.AddDynamic(rty.NewFillerString(' '))
+	l.Add(resourceTextAge(localInfo.ServeStartTime))
+	return rty.OneLine(l)
+}
+
 func (v *ResourceView) resourceTextDCContainer(dcInfo view.DCResourceInfo) rty.Component {
 	if dcInfo.ContainerID.String() == "" {
 		return rty.EmptyLayout
@@ -273,6 +304,15 @@ func (v *ResourceView) resourceExpandedK8s() rty.Component {
 		l.Add(middotText())
 	}
 
+	if k8sInfo.JobExitCode != nil {
+		l.Add(resourceTextJobExitCode(*k8sInfo.JobExitCode))
+		l.Add(middotText())
+	}
+	if !k8sInfo.JobCompletionTime.IsZero() {
+		l.Add(resourceTextJobCompleted(k8sInfo.JobCompletionTime))
+		l.Add(middotText())
+	}
+
 	l.Add(resourceTextAge(k8sInfo.PodCreationTime))
 	return rty.OneLine(l)
 }
@@ -295,6 +335,24 @@ func resourceTextPodRestarts(k8sInfo view.K8sResourceInfo) rty.Component {
 		Build()
 }
 
+func resourceTextJobExitCode(code int) rty.Component {
+	color := cGood
+	if code != 0 {
+		color = cBad
+	}
+	sb := rty.NewStringBuilder()
+	sb.Fg(cLightText).Text("EXIT ")
+	sb.Fg(color).Textf("%d", code)
+	return sb.Build()
+}
+
+func resourceTextJobCompleted(t time.Time) rty.Component {
+	sb := rty.NewStringBuilder()
+	sb.Fg(cLightText).Text("DONE ")
+	sb.Fg(tcell.ColorDefault).Textf("%s ago", formatDeployAge(time.Since(t)))
+	return sb.Build()
+}
+
 func resourceTextAge(t time.Time) rty.Component {
 	sb := rty.NewStringBuilder()
 	sb.Fg(cLightText).Text("AGE ")
@@ -325,6 +383,32 @@ func (v *ResourceView) resourceExpandedEndpoints() rty.Component {
 	return l
 }
 
+// Only shows the port-forwards that are broken, so that they don't
+// get lost among the ones that are working.
+func (v *ResourceView) resourceExpandedPortForwards() rty.Component {
+	rows := rty.NewConcatLayout(rty.DirVert)
+	for _, pf := range v.res.PortForwards {
+		if pf.IsActive() {
+			continue
+		}
+
+		color := cPending
+		if pf.IsFailed() {
+			color = cBad
+		}
+
+		sb := rty.NewStringBuilder()
+		sb.Fg(cLightText).Text("PORT-FORWARD: ")
+		sb.Fg(tcell.ColorDefault).Textf("localhost:%d → %d ", pf.LocalPort, pf.ContainerPort)
+		sb.Fg(color).Text(pf.Status)
+		if pf.Error != "" {
+			sb.Fg(cLightText).Textf(" (%s)", pf.Error)
+		}
+		rows.Add(sb.Build())
+	}
+	return rows
+}
+
 func resourceTextURLPrefix() rty.Component {
 	sb := rty.NewStringBuilder()
 	sb.Fg(cLightText).Text("URL: ")
@@ -417,6 +501,11 @@ func (v *ResourceView) resourceExpandedError() rty.Component {
 func (v *ResourceView) resourceExpandedRuntimeError() (rty.Component, bool) {
 	pane := rty.NewConcatLayout(rty.DirVert)
 	ok := false
+	if v.res.GaveUpRestarting {
+		pane.Add(rty.NewStringBuilder().Fg(cBad).
+			Textf("Gave up restarting after %d crashes", v.res.CrashRestartCount).Build())
+		ok = true
+	}
 	if isCrashing(v.res) {
 		runtimeLog := v.res.CrashLog.Tail(abbreviatedLogLineCount).String()
 		if runtimeLog == "" {
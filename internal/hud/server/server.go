@@ -39,6 +39,10 @@ type triggerPayload struct {
 	ManifestNames []string `json:"manifest_names"`
 }
 
+type enablePayload struct {
+	Enabled bool `json:"enabled"`
+}
+
 type HeadsUpServer struct {
 	store             *store.Store
 	router            *mux.Router
@@ -63,6 +67,7 @@ func ProvideHeadsUpServer(store *store.Store, assetServer assets.Server, analyti
 	r.HandleFunc("/api/analytics_opt", s.HandleAnalyticsOpt)
 	r.HandleFunc("/api/sail", s.HandleSail)
 	r.HandleFunc("/api/trigger", s.HandleTrigger)
+	r.HandleFunc("/api/resource/{name}/enable", s.HandleEnable)
 	r.HandleFunc("/api/snapshot/new", s.HandleNewSnapshot)
 	r.HandleFunc("/ws/view", s.ViewWebsocket)
 
@@ -207,6 +212,34 @@ func MaybeSendToTriggerQueue(st store.RStore, name string) error {
 	return nil
 }
 
+func (s *HeadsUpServer) HandleEnable(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "must be POST request", http.StatusBadRequest)
+		return
+	}
+
+	var payload enablePayload
+
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	mName := model.ManifestName(mux.Vars(req)["name"])
+	state := s.store.RLockState()
+	_, ok := state.Manifest(mName)
+	s.store.RUnlockState()
+
+	if !ok {
+		http.Error(w, fmt.Sprintf("no manifest found with name '%s'", mName), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Dispatch(store.SetResourceEnabledAction{ManifestName: mName, Enabled: payload.Enabled})
+}
+
 /* -- SNAPSHOT: SENDING SNAPSHOT TO SERVER -- */
 type snapshotURLJson struct {
 	Url string `json:"url"`
@@ -317,6 +317,67 @@ func TestHandleTriggerMalformedPayload(t *testing.T) {
 	assert.Contains(t, rr.Body.String(), "error parsing JSON")
 }
 
+func TestHandleEnable(t *testing.T) {
+	f := newTestFixture(t)
+
+	mt := store.ManifestTarget{
+		Manifest: model.Manifest{
+			Name: "foobar",
+		},
+	}
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(&mt)
+	f.st.UnlockMutableState()
+
+	var jsonStr = []byte(`{"enabled":false}`)
+	req, err := http.NewRequest(http.MethodPost, "/api/resource/foobar/enable", bytes.NewBuffer(jsonStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	handler := f.serv.Router()
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	a := store.WaitForAction(t, reflect.TypeOf(store.SetResourceEnabledAction{}), f.getActions)
+	action, ok := a.(store.SetResourceEnabledAction)
+	if !ok {
+		t.Fatalf("Action was not of type 'SetResourceEnabledAction': %+v", action)
+	}
+	assert.Equal(t, "foobar", action.ManifestName.String())
+	assert.False(t, action.Enabled)
+}
+
+func TestHandleEnableNoManifestWithName(t *testing.T) {
+	f := newTestFixture(t)
+
+	var jsonStr = []byte(`{"enabled":true}`)
+	req, err := http.NewRequest(http.MethodPost, "/api/resource/foobar/enable", bytes.NewBuffer(jsonStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	handler := f.serv.Router()
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusBadRequest)
+	}
+	assert.Contains(t, rr.Body.String(), "no manifest found with name 'foobar'")
+	store.AssertNoActionOfType(t, reflect.TypeOf(store.SetResourceEnabledAction{}), f.getActions)
+}
+
 func TestMaybeSendToTriggerQueue(t *testing.T) {
 	f := newTestFixture(t)
 
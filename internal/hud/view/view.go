@@ -5,6 +5,7 @@ import (
 
 	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/k8s"
 	"github.com/windmilleng/tilt/pkg/model"
 )
 
@@ -48,13 +49,23 @@ type K8sResourceInfo struct {
 	PodRestarts        int
 	PodLog             model.Log
 	YAML               string
+
+	// Only set for resources that run Jobs.
+	JobStatus         string
+	JobCompletionTime time.Time
+	JobExitCode       *int
 }
 
 var _ ResourceInfoView = K8sResourceInfo{}
 
 func (K8sResourceInfo) resourceInfoView()             {}
 func (k8sInfo K8sResourceInfo) RuntimeLog() model.Log { return k8sInfo.PodLog }
-func (k8sInfo K8sResourceInfo) Status() string        { return k8sInfo.PodStatus }
+func (k8sInfo K8sResourceInfo) Status() string {
+	if k8sInfo.JobStatus != "" {
+		return k8sInfo.JobStatus
+	}
+	return k8sInfo.PodStatus
+}
 
 type YAMLResourceInfo struct {
 	K8sResources []string
@@ -66,6 +77,34 @@ func (YAMLResourceInfo) resourceInfoView()              {}
 func (yamlInfo YAMLResourceInfo) RuntimeLog() model.Log { return model.NewLog("") }
 func (yamlInfo YAMLResourceInfo) Status() string        { return "" }
 
+type LocalResourceInfo struct {
+	// Only populated for resources with a serve_cmd.
+	ServeStatus    string
+	ServePID       int
+	ServeStartTime time.Time
+	ServeLog       model.Log
+}
+
+var _ ResourceInfoView = LocalResourceInfo{}
+
+func (LocalResourceInfo) resourceInfoView()               {}
+func (localInfo LocalResourceInfo) RuntimeLog() model.Log { return localInfo.ServeLog }
+func (localInfo LocalResourceInfo) Status() string        { return localInfo.ServeStatus }
+
+type PortForward struct {
+	LocalPort     int
+	ContainerPort int
+
+	// One of "active", "reconnecting", or "failed".
+	Status string
+
+	// Why the port-forward isn't active, if it's not.
+	Error string
+}
+
+func (pf PortForward) IsActive() bool { return pf.Status == "active" }
+func (pf PortForward) IsFailed() bool { return pf.Status == "failed" }
+
 type Resource struct {
 	Name               model.ManifestName
 	DirectoriesWatched []string
@@ -88,6 +127,27 @@ type Resource struct {
 	// for a little while.
 	CrashLog model.Log
 
+	// Dependencies that must be ready before the first build can start.
+	WaitingOn []model.ManifestName
+
+	// Disabled resources aren't built or deployed until they're re-enabled.
+	Disabled bool
+
+	// How many times in a row we redeployed the resource because it crashed,
+	// and whether we gave up.
+	CrashRestartCount int
+	GaveUpRestarting  bool
+
+	// Labels from the Tiltfile. The HUD groups resources by label.
+	Labels []string
+
+	// With --confirm-changes, changes to live k8s objects that are
+	// waiting for the user's approval.
+	PendingChanges []k8s.EntityDiff
+
+	// Port-forwards to the current pod, sorted by local port.
+	PortForwards []PortForward
+
 	IsTiltfile bool
 }
 
@@ -130,6 +190,16 @@ func (r Resource) IsYAML() bool {
 	return ok
 }
 
+func (r Resource) LocalInfo() LocalResourceInfo {
+	ret, _ := r.ResourceInfo.(LocalResourceInfo)
+	return ret
+}
+
+func (r Resource) IsLocal() bool {
+	_, ok := r.ResourceInfo.(LocalResourceInfo)
+	return ok
+}
+
 func (r Resource) LastBuild() model.BuildRecord {
 	if len(r.BuildHistory) == 0 {
 		return model.BuildRecord{}
@@ -210,6 +280,12 @@ type ViewState struct {
 	TabState              TabState
 	SelectedIndex         int
 	TiltLogState          TiltLogState
+
+	// Groups of resources (by label) that the user collapsed.
+	CollapsedGroups map[string]bool
+
+	// The resource whose pending changes we're showing for review, if any.
+	ReviewChanges model.ManifestName
 }
 
 type TabState int
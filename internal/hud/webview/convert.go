@@ -9,6 +9,7 @@ import (
 	"github.com/windmilleng/tilt/internal/dockercompose"
 	"github.com/windmilleng/tilt/internal/hud/view"
 	"github.com/windmilleng/tilt/internal/ospath"
+	"github.com/windmilleng/tilt/internal/sliceutils"
 	"github.com/windmilleng/tilt/internal/store"
 	"github.com/windmilleng/tilt/pkg/model"
 )
@@ -49,7 +50,7 @@ func StateToWebView(s store.EngineState) View {
 
 		pendingBuildEdits = ospath.FileListDisplayNames(absWatchDirs, pendingBuildEdits)
 
-		buildHistory := append([]model.BuildRecord{}, ms.BuildHistory...)
+		buildHistory := append([]model.BuildRecord{}, ms.BuildHistoryForDisplay()...)
 		for i, build := range buildHistory {
 			build.Edits = ospath.FileListDisplayNames(absWatchDirs, build.Edits)
 			buildHistory[i] = build
@@ -74,7 +75,7 @@ func StateToWebView(s store.EngineState) View {
 			Name:               name,
 			DirectoriesWatched: relWatchDirs,
 			PathsWatched:       relWatchPaths,
-			LastDeployTime:     ms.LastSuccessfulDeployTime,
+			LastDeployTime:     ms.LastDeployTimeForDisplay(),
 			BuildHistory:       ToWebViewBuildRecords(buildHistory),
 			PendingBuildEdits:  pendingBuildEdits,
 			PendingBuildSince:  pendingBuildSince,
@@ -83,18 +84,28 @@ func StateToWebView(s store.EngineState) View {
 			Endpoints:          endpoints,
 			PodID:              podID,
 			ResourceInfo:       resourceInfoView(mt),
-			ShowBuildStatus:    len(mt.Manifest.ImageTargets) > 0 || mt.Manifest.IsDC(),
+			ShowBuildStatus:    len(mt.Manifest.ImageTargets) > 0 || mt.Manifest.IsDC() || mt.Manifest.IsLocal(),
 			CombinedLog:        ms.CombinedLog,
 			CrashLog:           ms.CrashLog,
 			TriggerMode:        mt.Manifest.TriggerMode,
 			HasPendingChanges:  hasPendingChanges,
+			Disabled:           ms.Disabled,
+			CrashRestartCount:  ms.CrashRestartCount,
+			GaveUpRestarting:   ms.GaveUpRestarting,
+			Labels:             mt.Manifest.Labels,
+			PortForwards:       portForwards(ms),
 		}
 
-		r.RuntimeStatus = runtimeStatus(r.ResourceInfo)
+		if mt.Manifest.IsLocal() {
+			r.RuntimeStatus = localRuntimeStatus(mt.Manifest, ms)
+		} else {
+			r.RuntimeStatus = runtimeStatus(r.ResourceInfo)
+		}
 
 		ret.Resources = append(ret.Resources, r)
 	}
 
+	ret.Labels = allLabels(ret.Resources)
 	ret.Log = s.Log
 	ret.SailEnabled = s.SailEnabled
 	ret.SailURL = s.SailURL
@@ -106,6 +117,27 @@ func StateToWebView(s store.EngineState) View {
 	return ret
 }
 
+func portForwards(ms *store.ManifestState) []PortForward {
+	var result []PortForward
+	for _, pf := range ms.CurrentPortForwards() {
+		result = append(result, PortForward{
+			LocalPort:     pf.Forward.LocalPort,
+			ContainerPort: pf.Forward.ContainerPort,
+			Status:        string(pf.Status),
+			Error:         pf.Error,
+		})
+	}
+	return result
+}
+
+func allLabels(resources []Resource) []string {
+	var labels []string
+	for _, r := range resources {
+		labels = append(labels, r.Labels...)
+	}
+	return sliceutils.DedupedAndSorted(labels)
+}
+
 func tiltfileResourceView(s store.EngineState) Resource {
 	ltfb := s.TiltfileState.LastBuild()
 	ctfb := s.TiltfileState.CurrentBuild
@@ -139,6 +171,15 @@ func resourceInfoView(mt *store.ManifestTarget) ResourceInfoView {
 			K8sResources: mt.Manifest.K8sTarget().DisplayNames,
 		}
 	}
+	if mt.Manifest.IsLocal() {
+		lState := mt.State.LocalRuntimeState()
+		return LocalResourceInfo{
+			ServeStatus:    string(lState.Status),
+			ServePID:       lState.PID,
+			ServeStartTime: lState.StartTime,
+			ServeLog:       lState.Log(),
+		}
+	}
 	if mt.Manifest.IsDC() {
 		dc := mt.Manifest.DockerComposeTarget()
 		dcState := mt.State.DCRuntimeState()
@@ -146,14 +187,18 @@ func resourceInfoView(mt *store.ManifestTarget) ResourceInfoView {
 	} else {
 		kState := mt.State.K8sRuntimeState()
 		pod := kState.MostRecentPod()
+		job := kState.CurrentJob()
 		return K8sResourceInfo{
 			PodName:            pod.PodID.String(),
 			PodCreationTime:    pod.StartedAt,
 			PodUpdateStartTime: pod.UpdateStartTime,
-			PodStatus:          pod.Status,
+			PodStatus:          pod.DisplayStatus(),
 			PodStatusMessage:   strings.Join(pod.StatusMessages, "\n"),
 			PodRestarts:        pod.AllContainerRestarts() - pod.OldRestarts,
 			PodLog:             pod.Log(),
+			JobStatus:          string(job.Status),
+			JobCompletionTime:  job.CompletionTime,
+			JobExitCode:        kState.JobExitCode(),
 			YAML:               mt.Manifest.K8sTarget().YAML,
 		}
 	}
@@ -173,8 +218,31 @@ func runtimeStatus(res ResourceInfoView) RuntimeStatus {
 	return result
 }
 
+// Local resources without a serve_cmd have no runtime to monitor, so they're
+// healthy as soon as their command has run at least once.
+// (Build failures are surfaced separately, through the build history.)
+func localRuntimeStatus(m model.Manifest, ms *store.ManifestState) RuntimeStatus {
+	if ms.LastBuild().Empty() {
+		return RuntimeStatusPending
+	}
+
+	if m.LocalTarget().ServeCmd.Empty() {
+		return RuntimeStatusOK
+	}
+
+	switch ms.LocalRuntimeState().Status {
+	case store.LocalServeStatusRunning:
+		return RuntimeStatusOK
+	case store.LocalServeStatusCrashed, store.LocalServeStatusExited:
+		return RuntimeStatusError
+	default:
+		return RuntimeStatusPending
+	}
+}
+
 var runtimeStatusMap = map[string]RuntimeStatus{
 	"Running":                          RuntimeStatusOK,
+	store.PodStatusPendingReadiness:    RuntimeStatusPendingReadiness,
 	"ContainerCreating":                RuntimeStatusPending,
 	"Pending":                          RuntimeStatusPending,
 	"PodInitializing":                  RuntimeStatusPending,
@@ -188,6 +256,9 @@ var runtimeStatusMap = map[string]RuntimeStatus{
 	string(dockercompose.StatusUp):     RuntimeStatusOK,
 	string(dockercompose.StatusDown):   RuntimeStatusError,
 	"Completed":                        RuntimeStatusOK,
+	string(store.JobStatusActive):      RuntimeStatusPending,
+	string(store.JobStatusComplete):    RuntimeStatusOK,
+	string(store.JobStatusFailed):      RuntimeStatusError,
 
 	// If the runtime status hasn't shown up yet, we assume it's pending.
 	"": RuntimeStatusPending,
@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/windmilleng/tilt/internal/k8s"
 	"github.com/windmilleng/tilt/internal/k8s/testyaml"
@@ -67,6 +68,29 @@ func TestStateToWebViewPortForwards(t *testing.T) {
 		res.Endpoints)
 }
 
+func TestStateToWebViewPortForwardStatus(t *testing.T) {
+	m := model.Manifest{
+		Name: "foo",
+	}.WithDeployTarget(model.K8sTarget{})
+	state := newState([]model.Manifest{m})
+	ms := state.ManifestTargets[m.Name].State
+	ms.RuntimeState = store.NewK8sRuntimeState(0, store.Pod{PodID: "pod-id"})
+	ms.PortForwards = map[int]store.PortForwardState{
+		8000: {
+			Forward: model.PortForward{LocalPort: 8000, ContainerPort: 5000},
+			PodID:   "pod-id",
+			Status:  store.PortForwardStatusFailed,
+			Error:   "connection refused",
+		},
+	}
+
+	v := StateToWebView(*state)
+	res, _ := v.Resource(m.Name)
+	assert.Equal(t, []PortForward{
+		{LocalPort: 8000, ContainerPort: 5000, Status: "failed", Error: "connection refused"},
+	}, res.PortForwards)
+}
+
 func TestStateToViewUnresourcedYAMLManifest(t *testing.T) {
 	m, err := k8s.NewK8sOnlyManifestFromYAML(testyaml.SanchoYAML)
 	assert.NoError(t, err)
@@ -145,6 +169,47 @@ func TestTriggerMode(t *testing.T) {
 	assert.Equal(t, model.TriggerModeManual, newM.TriggerMode)
 }
 
+func TestLabels(t *testing.T) {
+	state := newState([]model.Manifest{
+		model.Manifest{Name: "api"}.WithLabels([]string{"payments", "backend"}),
+		model.Manifest{Name: "db"}.WithLabels([]string{"backend"}),
+		{Name: "lint"},
+	})
+
+	v := StateToWebView(*state)
+	assert.Equal(t, []string{"backend", "payments"}, v.Labels)
+
+	api, _ := v.Resource("api")
+	assert.Equal(t, []string{"payments", "backend"}, api.Labels)
+	lint, _ := v.Resource("lint")
+	assert.Empty(t, lint.Labels)
+}
+
+func TestPendingReadiness(t *testing.T) {
+	m := model.Manifest{Name: "fe"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.SanchoYAML})
+	state := newState([]model.Manifest{m})
+	pod := store.Pod{
+		PodID:      "fe-pod",
+		Phase:      v1.PodRunning,
+		Status:     "Running",
+		Containers: []store.Container{{Name: "main"}},
+	}
+	state.ManifestTargets["fe"].State.RuntimeState = store.NewK8sRuntimeState(0, pod)
+
+	v := StateToWebView(*state)
+	fe, _ := v.Resource("fe")
+	assert.Equal(t, RuntimeStatus(RuntimeStatusPendingReadiness), fe.RuntimeStatus)
+	assert.Equal(t, store.PodStatusPendingReadiness, fe.ResourceInfo.Status())
+
+	pod.Ready = true
+	pod.Containers[0].Ready = true
+	state.ManifestTargets["fe"].State.RuntimeState = store.NewK8sRuntimeState(0, pod)
+
+	v = StateToWebView(*state)
+	fe, _ = v.Resource("fe")
+	assert.Equal(t, RuntimeStatus(RuntimeStatusOK), fe.RuntimeStatus)
+}
+
 func TestFeatureFlags(t *testing.T) {
 	state := newState(nil)
 	state.Features = map[string]bool{"foo_feature": true}
@@ -48,13 +48,23 @@ type K8sResourceInfo struct {
 	PodRestarts        int
 	PodLog             model.Log
 	YAML               string
+
+	// Only set for resources that run Jobs.
+	JobStatus         string
+	JobCompletionTime time.Time
+	JobExitCode       *int
 }
 
 var _ ResourceInfoView = K8sResourceInfo{}
 
 func (K8sResourceInfo) resourceInfoView()             {}
 func (k8sInfo K8sResourceInfo) RuntimeLog() model.Log { return k8sInfo.PodLog }
-func (k8sInfo K8sResourceInfo) Status() string        { return k8sInfo.PodStatus }
+func (k8sInfo K8sResourceInfo) Status() string {
+	if k8sInfo.JobStatus != "" {
+		return k8sInfo.JobStatus
+	}
+	return k8sInfo.PodStatus
+}
 
 type YAMLResourceInfo struct {
 	K8sResources []string
@@ -66,6 +76,20 @@ func (YAMLResourceInfo) resourceInfoView()              {}
 func (yamlInfo YAMLResourceInfo) RuntimeLog() model.Log { return model.NewLog("") }
 func (yamlInfo YAMLResourceInfo) Status() string        { return "" }
 
+type LocalResourceInfo struct {
+	// Only populated for resources with a serve_cmd.
+	ServeStatus    string
+	ServePID       int
+	ServeStartTime time.Time
+	ServeLog       model.Log
+}
+
+var _ ResourceInfoView = LocalResourceInfo{}
+
+func (LocalResourceInfo) resourceInfoView()               {}
+func (localInfo LocalResourceInfo) RuntimeLog() model.Log { return localInfo.ServeLog }
+func (localInfo LocalResourceInfo) Status() string        { return localInfo.ServeStatus }
+
 type BuildRecord struct {
 	model.BuildRecord
 	IsCrashRebuild bool
@@ -111,9 +135,32 @@ type Resource struct {
 	RuntimeStatus RuntimeStatus
 
 	IsTiltfile      bool
+	Disabled        bool
 	ShowBuildStatus bool // if true, we show status & time in 'Build Status'; else, "N/A"
 	CombinedLog     model.Log
 	CrashLog        model.Log
+
+	// How many times in a row we redeployed the resource because it crashed,
+	// and whether we gave up.
+	CrashRestartCount int
+	GaveUpRestarting  bool
+
+	// Labels from the Tiltfile, for grouping resources in the sidebar.
+	Labels []string
+
+	// Port-forwards to the current pod, sorted by local port.
+	PortForwards []PortForward
+}
+
+type PortForward struct {
+	LocalPort     int `json:"LocalPort"`
+	ContainerPort int `json:"ContainerPort"`
+
+	// One of "active", "reconnecting", or "failed".
+	Status string `json:"Status"`
+
+	// Why the port-forward isn't active, if it's not.
+	Error string `json:"Error"`
 }
 
 func (r Resource) LastBuild() BuildRecord {
@@ -129,6 +176,9 @@ const (
 	RuntimeStatusOK      RuntimeStatus = "ok"
 	RuntimeStatusPending               = "pending"
 	RuntimeStatusError                 = "error"
+
+	// The pod is running, but its readiness probes aren't passing yet.
+	RuntimeStatusPendingReadiness = "pending_readiness"
 )
 
 type View struct {
@@ -142,6 +192,9 @@ type View struct {
 
 	NeedsAnalyticsNudge bool
 
+	// All the labels on resources, sorted.
+	Labels []string
+
 	RunningTiltBuild model.TiltBuild
 	LatestTiltBuild  model.TiltBuild
 }
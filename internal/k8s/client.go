@@ -12,11 +12,13 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/browser"
 	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/version"
@@ -79,6 +81,17 @@ type Client interface {
 
 	GetByReference(ref v1.ObjectReference) (K8sEntity, error)
 
+	// Lists all objects of the given kinds that match the given labels,
+	// across all namespaces.
+	//
+	// Skips kinds that the cluster doesn't know about (e.g., a CRD that
+	// was never installed).
+	ListByLabel(ctx context.Context, gvks []schema.GroupVersionKind, lps []model.LabelPair) ([]K8sEntity, error)
+
+	// Compares each of the given entities against the live object in the cluster,
+	// without changing anything.
+	Diff(ctx context.Context, entities []K8sEntity) ([]EntityDiff, error)
+
 	PodByID(ctx context.Context, podID PodID, n Namespace) (*v1.Pod, error)
 
 	// Creates a channel where all changes to the pod are brodcast.
@@ -88,19 +101,26 @@ type Client interface {
 	// Streams the container logs
 	ContainerLogs(ctx context.Context, podID PodID, cName container.Name, n Namespace, startTime time.Time) (io.ReadCloser, error)
 
-	// Opens a tunnel to the specified pod+port. Returns the tunnel's local port and a function that closes the tunnel
-	ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (localPort int, closer func(), err error)
+	// Opens a tunnel to the specified pod+port.
+	ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (PortForwardTunnel, error)
 
 	WatchPods(ctx context.Context, lps labels.Selector) (<-chan *v1.Pod, error)
 
 	WatchServices(ctx context.Context, lps []model.LabelPair) (<-chan *v1.Service, error)
 
+	WatchJobs(ctx context.Context, lps []model.LabelPair) (<-chan *batchv1.Job, error)
+
 	WatchEvents(ctx context.Context) (<-chan *v1.Event, error)
 
 	ConnectedToCluster(ctx context.Context) error
 
 	ContainerRuntime(ctx context.Context) container.Runtime
 
+	// The names of all the nodes in the cluster.
+	//
+	// On KIND and k3d, each node is a Docker container with the same name.
+	NodeNames(ctx context.Context) ([]string, error)
+
 	// Some clusters support a private image registry that we can push to.
 	PrivateRegistry(ctx context.Context) container.Registry
 
@@ -114,6 +134,7 @@ type K8sClient struct {
 	restConfig      *rest.Config
 	portForwarder   PortForwarder
 	configNamespace Namespace
+	nsOverride      NamespaceOverride
 	clientSet       kubernetes.Interface
 	dynamic         dynamic.Interface
 	runtimeAsync    *runtimeAsync
@@ -123,13 +144,14 @@ type K8sClient struct {
 
 var _ Client = K8sClient{}
 
-type PortForwarder func(ctx context.Context, restConfig *rest.Config, core apiv1.CoreV1Interface, namespace string, podID PodID, localPort int, remotePort int) (closer func(), err error)
+type PortForwarder func(ctx context.Context, restConfig *rest.Config, core apiv1.CoreV1Interface, namespace string, podID PodID, localPort int, remotePort int) (closer func(), done <-chan error, err error)
 
 func ProvideK8sClient(
 	ctx context.Context,
 	env Env,
 	pf PortForwarder,
 	configNamespace Namespace,
+	nsOverride NamespaceOverride,
 	runner kubectlRunner,
 	clientLoader clientcmd.ClientConfig) Client {
 	if env == EnvNone {
@@ -180,6 +202,7 @@ func ProvideK8sClient(
 		restConfig:      restConfig,
 		portForwarder:   pf,
 		configNamespace: configNamespace,
+		nsOverride:      nsOverride,
 		clientSet:       clientset,
 		runtimeAsync:    runtimeAsync,
 		registryAsync:   registryAsync,
@@ -243,6 +266,11 @@ func (k K8sClient) Upsert(ctx context.Context, entities []K8sEntity) ([]K8sEntit
 	span, ctx := opentracing.StartSpanFromContext(ctx, "daemon-k8sUpsert")
 	defer span.Finish()
 
+	entities, err := overrideNamespace(entities, k.nsOverride, k.drm)
+	if err != nil {
+		return nil, err
+	}
+
 	result := make([]K8sEntity, 0, len(entities))
 
 	// First apply all the entities on which something else might depend
@@ -257,7 +285,7 @@ func (k K8sClient) Upsert(ctx context.Context, entities []K8sEntity) ([]K8sEntit
 
 	immutable := ImmutableEntities(entities)
 	if len(immutable) > 0 {
-		newEntities, err := k.forceReplaceEntities(ctx, immutable)
+		newEntities, err := k.deleteAndCreateEntities(ctx, immutable)
 		if err != nil {
 			return nil, err
 		}
@@ -275,10 +303,18 @@ func (k K8sClient) Upsert(ctx context.Context, entities []K8sEntity) ([]K8sEntit
 	return result, nil
 }
 
-func (k K8sClient) forceReplaceEntities(ctx context.Context, entities []K8sEntity) ([]K8sEntity, error) {
-	stdout, stderr, err := k.actOnEntities(ctx, []string{"replace", "-o", "yaml", "--force"}, entities)
+// deleteAndCreateEntities deletes the given entities (waiting until they're gone)
+// and creates them again. We use this for entities whose spec can't be updated,
+// like Jobs, so that every deploy starts a fresh run.
+func (k K8sClient) deleteAndCreateEntities(ctx context.Context, entities []K8sEntity) ([]K8sEntity, error) {
+	_, stderr, err := k.actOnEntities(ctx, []string{"delete", "--ignore-not-found", "--wait"}, entities)
 	if err != nil {
-		return nil, errors.Wrapf(err, "kubectl replace:\nstderr: %s", stderr)
+		return nil, errors.Wrapf(err, "kubectl delete (as part of delete && create):\nstderr: %s", stderr)
+	}
+
+	stdout, stderr, err := k.actOnEntities(ctx, []string{"create", "-o", "yaml"}, entities)
+	if err != nil {
+		return nil, errors.Wrapf(err, "kubectl create (as part of delete && create):\nstderr: %s", stderr)
 	}
 	return ParseYAMLFromString(stdout)
 }
@@ -336,6 +372,11 @@ func maybeImmutableFieldStderr(stderr string) bool {
 // Currently ignores any "not found" errors, because that seems like the correct
 // behavior for our use cases.
 func (k K8sClient) Delete(ctx context.Context, entities []K8sEntity) error {
+	entities, err := overrideNamespace(entities, k.nsOverride, k.drm)
+	if err != nil {
+		return err
+	}
+
 	l := logger.Get(ctx)
 	for _, e := range entities {
 		l.Infof("Deleting via kubectl: %s/%s\n", e.GVK().Kind, e.Name())
@@ -384,6 +425,92 @@ func (k K8sClient) GetByReference(ref v1.ObjectReference) (K8sEntity, error) {
 	return NewK8sEntity(result), nil
 }
 
+func (k K8sClient) ListByLabel(ctx context.Context, gvks []schema.GroupVersionKind, lps []model.LabelPair) ([]K8sEntity, error) {
+	opts := metav1.ListOptions{LabelSelector: makeLabelSelector(lps)}
+	result := []K8sEntity{}
+	for _, gvk := range gvks {
+		rm, err := k.drm.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "error mapping %s", gvk)
+		}
+
+		// With a namespace override, we only deploy namespaced objects to one namespace,
+		// so objects with the same labels in other namespaces belong to someone else.
+		nri := k.dynamic.Resource(rm.Resource)
+		var resource dynamic.ResourceInterface = nri
+		if !k.nsOverride.Empty() && rm.Scope.Name() == meta.RESTScopeNameNamespace {
+			resource = nri.Namespace(string(k.nsOverride))
+		}
+
+		list, err := resource.List(opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing %s", rm.Resource.Resource)
+		}
+		for i := range list.Items {
+			result = append(result, NewK8sEntity(&list.Items[i]))
+		}
+	}
+	return result, nil
+}
+
+func (k K8sClient) Diff(ctx context.Context, entities []K8sEntity) ([]EntityDiff, error) {
+	entities, err := overrideNamespace(entities, k.nsOverride, k.drm)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]EntityDiff, 0, len(entities))
+	for _, e := range entities {
+		live, err := k.getLive(e)
+		if err != nil {
+			return nil, err
+		}
+
+		diff, err := DiffEntity(live, e)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, diff)
+	}
+	return result, nil
+}
+
+// Fetches the live version of the given entity, or nil if it doesn't exist.
+func (k K8sClient) getLive(e K8sEntity) (*unstructured.Unstructured, error) {
+	gvk := e.GVK()
+	rm, err := k.drm.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// The kind isn't installed yet (e.g., a CRD that's created by the same
+			// YAML), so the object can't exist yet either.
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "error mapping %s", gvk)
+	}
+
+	resource := k.dynamic.Resource(rm.Resource)
+	var ri dynamic.ResourceInterface = resource
+	if rm.Scope.Name() == meta.RESTScopeNameNamespace {
+		namespace := e.meta().GetNamespace()
+		if namespace == "" {
+			namespace = k.configNamespace.String()
+		}
+		ri = resource.Namespace(namespace)
+	}
+
+	live, err := ri.Get(e.Name(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "getting %s/%s", gvk.Kind, e.Name())
+	}
+	return live, nil
+}
+
 // Tests whether a string is a valid version for a k8s resource type.
 // from https://kubernetes.io/docs/tasks/access-kubernetes-api/custom-resources/custom-resource-definition-versioning/#version-priority
 // Versions start with a v followed by a number, an optional beta or alpha designation, and optional additional numeric
@@ -422,31 +549,31 @@ func ProvideClientSet(cfg *rest.Config) (*kubernetes.Clientset, error) {
 	return clientSet, nil
 }
 
-func ProvideClientConfig() clientcmd.ClientConfig {
+func ProvideClientConfig(nsOverride NamespaceOverride) clientcmd.ClientConfig {
 	rules := clientcmd.NewDefaultClientConfigLoadingRules()
 	rules.DefaultClientConfig = &clientcmd.DefaultClientConfig
 
 	overrides := &clientcmd.ConfigOverrides{}
+	overrides.Context.Namespace = string(nsOverride)
 	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		rules,
 		overrides)
 }
 
-// The namespace in the kubeconfig.
+// The namespace in the kubeconfig, or the namespace override if there is one.
 // Used as a default namespace in some (but not all) client commands.
 // https://godoc.org/k8s.io/client-go/tools/clientcmd/api/v1#Context
+//
+// Unlike the namespace override, this doesn't change the namespace
+// of objects that already have one in their YAML.
 func ProvideConfigNamespace(clientLoader clientcmd.ClientConfig) Namespace {
-	namespace, explicit, err := clientLoader.Namespace()
+	namespace, _, err := clientLoader.Namespace()
 	if err != nil {
 		// If we can't get a namespace from the config, just fail gracefully to the default.
 		// If this error indicates a more serious problem, it will get handled downstream.
 		return ""
 	}
 
-	// TODO(nick): Right now, tilt doesn't provide a namespace flag. If we ever did,
-	// we would need to handle explicit namespaces different than implicit ones.
-	_ = explicit
-
 	return Namespace(namespace)
 }
 
@@ -57,8 +57,8 @@ func TestUpsertOrder(t *testing.T) {
 		t.FailNow()
 	}
 
-	// three different calls: one for namespace (withDependents), one for job (immutable), one for deployment (mutable)
-	if !assert.Equal(t, 3, len(f.runner.calls)) {
+	// four different calls: one for namespace (withDependents), two for job (immutable), one for deployment (mutable)
+	if !assert.Equal(t, 4, len(f.runner.calls)) {
 		t.FailNow()
 	}
 
@@ -70,17 +70,24 @@ func TestUpsertOrder(t *testing.T) {
 	}
 
 	call1 := f.runner.calls[1]
-	assert.Equal(t, []string{"replace", "-o", "yaml", "--force", "-f", "-"}, call1.argv, "expected args for call 1")
+	assert.Equal(t, []string{"delete", "--ignore-not-found", "--wait", "-f", "-"}, call1.argv, "expected args for call 1")
 	call1Entities := mustParseYAML(t, call1.stdin)
-	if assert.Len(t, call1Entities, 1, "expect each 'apply' called on yaml for only one entity") {
-		assert.Equal(t, eJob, call1Entities[0], "expect call 1 to have applied job")
+	if assert.Len(t, call1Entities, 1, "expect each 'delete' called on yaml for only one entity") {
+		assert.Equal(t, eJob, call1Entities[0], "expect call 1 to have deleted job")
 	}
 
 	call2 := f.runner.calls[2]
-	assert.Equal(t, []string{"apply", "-o", "yaml", "-f", "-"}, call2.argv, "expected args for call 2")
+	assert.Equal(t, []string{"create", "-o", "yaml", "-f", "-"}, call2.argv, "expected args for call 2")
 	call2Entities := mustParseYAML(t, call2.stdin)
-	if assert.Len(t, call2Entities, 1, "expect each 'apply' called on yaml for only one entity") {
-		assert.Equal(t, eDeploy, call2Entities[0], "expect call 2 to have applied deployment")
+	if assert.Len(t, call2Entities, 1, "expect each 'create' called on yaml for only one entity") {
+		assert.Equal(t, eJob, call2Entities[0], "expect call 2 to have created job")
+	}
+
+	call3 := f.runner.calls[3]
+	assert.Equal(t, []string{"apply", "-o", "yaml", "-f", "-"}, call3.argv, "expected args for call 3")
+	call3Entities := mustParseYAML(t, call3.stdin)
+	if assert.Len(t, call3Entities, 1, "expect each 'apply' called on yaml for only one entity") {
+		assert.Equal(t, eDeploy, call3Entities[0], "expect call 3 to have applied deployment")
 	}
 }
 
@@ -264,8 +271,8 @@ func (c clientTestFixture) setError(err error) {
 	c.runner.err = err
 }
 
-func fakePortForwarder(ctx context.Context, restConfig *rest.Config, core apiv1.CoreV1Interface, namespace string, podID PodID, localPort int, remotePort int) (closer func(), err error) {
-	return nil, nil
+func fakePortForwarder(ctx context.Context, restConfig *rest.Config, core apiv1.CoreV1Interface, namespace string, podID PodID, localPort int, remotePort int) (closer func(), done <-chan error, err error) {
+	return nil, nil, nil
 }
 
 var _ PortForwarder = fakePortForwarder
@@ -0,0 +1,313 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// Fields that the server manages, or that Tilt changes on every deploy,
+// so they always differ from the live object.
+var ignoredDiffFields = map[string]bool{
+	"apiVersion":                 true,
+	"kind":                       true,
+	"status":                     true,
+	"metadata.creationTimestamp": true,
+	"metadata.generation":        true,
+	"metadata.managedFields":     true,
+	"metadata.resourceVersion":   true,
+	"metadata.selfLink":          true,
+	"metadata.uid":               true,
+}
+
+var ignoredDiffLabels = map[string]bool{
+	TiltDeployIDLabel: true,
+	TiltRunIDLabel:    true,
+}
+
+// A field that differs between a live object and the object we're about to apply.
+type FieldDiff struct {
+	// The path to the field, e.g., spec.template.spec.containers[name=app].image
+	Path string
+
+	// JSON-encoded values. Old is empty if the field is being added,
+	// and New is empty if it's being removed.
+	Old string
+	New string
+}
+
+func (d FieldDiff) String() string {
+	switch {
+	case d.Old == "":
+		return fmt.Sprintf("+ %s: %s", d.Path, d.New)
+	case d.New == "":
+		return fmt.Sprintf("- %s: %s", d.Path, d.Old)
+	default:
+		return fmt.Sprintf("~ %s: %s → %s", d.Path, d.Old, d.New)
+	}
+}
+
+// The differences between one live object and the object we're about to apply.
+type EntityDiff struct {
+	Kind      string
+	Name      string
+	Namespace Namespace
+
+	// The object doesn't exist in the cluster yet.
+	Create bool
+
+	// The object can't be updated in place, so applying it
+	// will delete the live object and create a new one.
+	Recreate bool
+
+	Fields []FieldDiff
+}
+
+func (d EntityDiff) Empty() bool {
+	return !d.Create && !d.Recreate && len(d.Fields) == 0
+}
+
+func (d EntityDiff) String() string {
+	return fmt.Sprintf("%s/%s (namespace: %s)", d.Kind, d.Name, d.Namespace)
+}
+
+// Compares the object we're about to apply against the live object in the cluster.
+//
+// Like `kubectl apply`, we only look at the fields that we set. A field that the
+// live object has but we don't only counts as a change if we set it last time
+// (according to the last-applied-configuration annotation), because that means
+// that the user removed it.
+//
+// A nil live object means that the object doesn't exist yet.
+func DiffEntity(live *unstructured.Unstructured, desired K8sEntity) (EntityDiff, error) {
+	result := EntityDiff{
+		Kind:      desired.GVK().Kind,
+		Name:      desired.Name(),
+		Namespace: desired.Namespace(),
+	}
+	if live == nil {
+		result.Create = true
+		return result, nil
+	}
+
+	if live.GetNamespace() != "" {
+		result.Namespace = Namespace(live.GetNamespace())
+	}
+
+	desiredObj, err := toUnstructuredMap(desired.Obj)
+	if err != nil {
+		return EntityDiff{}, errors.Wrapf(err, "diff %s", result)
+	}
+
+	var applied map[string]interface{}
+	if lastApplied, ok := live.GetAnnotations()[lastAppliedConfigAnnotation]; ok {
+		// If we can't parse it, we just won't report removed fields.
+		_ = json.Unmarshal([]byte(lastApplied), &applied)
+	}
+
+	result.Fields = diffMaps("", live.Object, desiredObj, applied)
+	result.Recreate = desired.ImmutableOnceCreated()
+	return result, nil
+}
+
+func toUnstructuredMap(obj runtime.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.Object, nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+func diffValues(path string, live, desired, applied interface{}) []FieldDiff {
+	switch desired := desired.(type) {
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			break
+		}
+		appliedMap, _ := applied.(map[string]interface{})
+		return diffMaps(path, liveMap, desired, appliedMap)
+
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok {
+			break
+		}
+		appliedList, _ := applied.([]interface{})
+		return diffLists(path, liveList, desired, appliedList)
+	}
+
+	liveJSON, desiredJSON := encodeDiffValue(live), encodeDiffValue(desired)
+	if liveJSON == desiredJSON {
+		return nil
+	}
+	return []FieldDiff{{Path: path, Old: liveJSON, New: desiredJSON}}
+}
+
+func diffMaps(path string, live, desired, applied map[string]interface{}) []FieldDiff {
+	var result []FieldDiff
+	for _, key := range sortedKeys(desired) {
+		if ignoreDiffField(path, key) {
+			continue
+		}
+
+		keyPath := joinDiffPath(path, key)
+		desiredVal := desired[key]
+		liveVal, ok := live[key]
+		if !ok {
+			if !isEmptyDiffValue(desiredVal) {
+				result = append(result, FieldDiff{Path: keyPath, New: encodeDiffValue(desiredVal)})
+			}
+			continue
+		}
+		result = append(result, diffValues(keyPath, liveVal, desiredVal, applied[key])...)
+	}
+
+	// Fields that we set last time, but aren't setting anymore.
+	for _, key := range sortedKeys(applied) {
+		if _, ok := desired[key]; ok || ignoreDiffField(path, key) {
+			continue
+		}
+		liveVal, ok := live[key]
+		if !ok {
+			continue
+		}
+		result = append(result, FieldDiff{Path: joinDiffPath(path, key), Old: encodeDiffValue(liveVal)})
+	}
+	return result
+}
+
+// Lists of named objects (like containers or env vars) are matched up by name,
+// so that removing an item from the middle doesn't show up as a change to
+// every item after it. Everything else is matched up by index.
+func diffLists(path string, live, desired, applied []interface{}) []FieldDiff {
+	if isNamedList(live) && isNamedList(desired) && isNamedList(applied) {
+		return diffNamedLists(path, live, desired, applied)
+	}
+
+	var result []FieldDiff
+	for i, desiredVal := range desired {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if i >= len(live) {
+			result = append(result, FieldDiff{Path: itemPath, New: encodeDiffValue(desiredVal)})
+			continue
+		}
+
+		var appliedVal interface{}
+		if i < len(applied) {
+			appliedVal = applied[i]
+		}
+		result = append(result, diffValues(itemPath, live[i], desiredVal, appliedVal)...)
+	}
+
+	for i := len(desired); i < len(live) && i < len(applied); i++ {
+		result = append(result, FieldDiff{Path: fmt.Sprintf("%s[%d]", path, i), Old: encodeDiffValue(live[i])})
+	}
+	return result
+}
+
+func diffNamedLists(path string, live, desired, applied []interface{}) []FieldDiff {
+	liveByName, appliedByName := byName(live), byName(applied)
+	desiredByName := byName(desired)
+
+	var result []FieldDiff
+	for _, item := range desired {
+		name := itemName(item)
+		itemPath := fmt.Sprintf("%s[name=%s]", path, name)
+		liveVal, ok := liveByName[name]
+		if !ok {
+			result = append(result, FieldDiff{Path: itemPath, New: encodeDiffValue(item)})
+			continue
+		}
+		result = append(result, diffValues(itemPath, liveVal, item, appliedByName[name])...)
+	}
+
+	for _, item := range applied {
+		name := itemName(item)
+		liveVal, ok := liveByName[name]
+		if _, stillDesired := desiredByName[name]; stillDesired || !ok {
+			continue
+		}
+		result = append(result, FieldDiff{Path: fmt.Sprintf("%s[name=%s]", path, name), Old: encodeDiffValue(liveVal)})
+	}
+	return result
+}
+
+func isNamedList(list []interface{}) bool {
+	for _, item := range list {
+		if itemName(item) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func itemName(item interface{}) string {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	name, _ := m["name"].(string)
+	return name
+}
+
+func byName(list []interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(list))
+	for _, item := range list {
+		result[itemName(item)] = item
+	}
+	return result
+}
+
+func ignoreDiffField(path, key string) bool {
+	if ignoredDiffFields[joinDiffPath(path, key)] {
+		return true
+	}
+	if strings.HasSuffix(path, "labels") && ignoredDiffLabels[key] {
+		return true
+	}
+	return path == "metadata.annotations" && key == lastAppliedConfigAnnotation
+}
+
+func joinDiffPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Typed objects have empty values for fields that were never set.
+func isEmptyDiffValue(val interface{}) bool {
+	switch val := val.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(val) == 0
+	case []interface{}:
+		return len(val) == 0
+	}
+	return false
+}
+
+func encodeDiffValue(val interface{}) string {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return string(b)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
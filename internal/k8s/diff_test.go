@@ -0,0 +1,103 @@
+package k8s
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestDiffEntityCreate(t *testing.T) {
+	desired := parseOneEntity(t, testyaml.SanchoYAML)
+
+	diff, err := DiffEntity(nil, desired)
+	require.NoError(t, err)
+	assert.True(t, diff.Create)
+	assert.Equal(t, "Deployment/sancho (namespace: sancho-ns)", diff.String())
+}
+
+func TestDiffEntityUnchanged(t *testing.T) {
+	desired := parseOneEntity(t, testyaml.SanchoYAML)
+	live := liveObject(t, desired)
+
+	// Fields that the server fills in, or that Tilt changes on every deploy.
+	live.SetResourceVersion("1234")
+	live.SetUID("some-uid")
+	require.NoError(t, unstructured.SetNestedField(live.Object, int64(600), "spec", "progressDeadlineSeconds"))
+	require.NoError(t, unstructured.SetNestedField(live.Object, "3", "metadata", "labels", TiltDeployIDLabel))
+	require.NoError(t, unstructured.SetNestedField(live.Object, int64(1), "status", "readyReplicas"))
+
+	deployed, err := InjectLabels(desired, []model.LabelPair{TiltDeployLabel(4)})
+	require.NoError(t, err)
+
+	diff, err := DiffEntity(live, deployed)
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+}
+
+func TestDiffEntityChangedFields(t *testing.T) {
+	desired := parseOneEntity(t, testyaml.SanchoYAML)
+	live := liveObject(t, desired)
+	require.NoError(t, unstructured.SetNestedField(live.Object, int64(3), "spec", "replicas"))
+	require.NoError(t, unstructured.SetNestedField(live.Object, "bar", "metadata", "labels", "foo"))
+
+	diff, err := DiffEntity(live, desired)
+	require.NoError(t, err)
+	assert.False(t, diff.Recreate)
+	assert.Equal(t, []FieldDiff{
+		{Path: "spec.replicas", Old: "3", New: "1"},
+	}, diff.Fields)
+	assert.Equal(t, "~ spec.replicas: 3 → 1", diff.Fields[0].String())
+}
+
+func TestDiffEntityRemovedEnvVar(t *testing.T) {
+	desired := parseOneEntity(t, testyaml.SanchoYAML)
+	live := liveObject(t, desired)
+
+	containers, _, err := unstructured.NestedSlice(live.Object, "spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	container := containers[0].(map[string]interface{})
+	container["env"] = append(container["env"].([]interface{}),
+		map[string]interface{}{"name": "DEBUG", "value": "1"})
+	require.NoError(t, unstructured.SetNestedSlice(live.Object, containers, "spec", "template", "spec", "containers"))
+
+	// The env var isn't a change unless we're the ones who set it.
+	diff, err := DiffEntity(live, desired)
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+
+	lastApplied, err := json.Marshal(live.Object)
+	require.NoError(t, err)
+	live.SetAnnotations(map[string]string{lastAppliedConfigAnnotation: string(lastApplied)})
+
+	diff, err = DiffEntity(live, desired)
+	require.NoError(t, err)
+	assert.Equal(t, []FieldDiff{
+		{
+			Path: "spec.template.spec.containers[name=sancho].env[name=DEBUG]",
+			Old:  `{"name":"DEBUG","value":"1"}`,
+		},
+	}, diff.Fields)
+}
+
+func TestDiffEntityImmutable(t *testing.T) {
+	desired := parseOneEntity(t, testyaml.JobYAML)
+	live := liveObject(t, desired)
+
+	diff, err := DiffEntity(live, desired)
+	require.NoError(t, err)
+	assert.True(t, diff.Recreate)
+	assert.False(t, diff.Empty())
+}
+
+func liveObject(t *testing.T, e K8sEntity) *unstructured.Unstructured {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(e.DeepCopy().Obj)
+	require.NoError(t, err)
+	return &unstructured.Unstructured{Object: obj}
+}
@@ -161,6 +161,12 @@ func (e K8sEntity) Namespace() Namespace {
 	return Namespace(n)
 }
 
+// The namespace in the object's metadata, without defaulting.
+// Empty for cluster-scoped objects, and for YAML that leaves the namespace to kubectl.
+func (e K8sEntity) MetaNamespace() Namespace {
+	return Namespace(e.meta().GetNamespace())
+}
+
 func (e K8sEntity) UID() types.UID {
 	return e.meta().GetUID()
 }
@@ -169,6 +175,10 @@ func (e K8sEntity) Labels() map[string]string {
 	return e.meta().GetLabels()
 }
 
+func (e K8sEntity) OwnerReferences() []metav1.OwnerReference {
+	return e.meta().GetOwnerReferences()
+}
+
 // Most entities can be updated once running, but a few cannot.
 func (e K8sEntity) ImmutableOnceCreated() bool {
 	return e.GVK().Kind == "Job" || e.GVK().Kind == "Pod"
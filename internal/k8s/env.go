@@ -31,6 +31,12 @@ func (e Env) UsesLocalDockerRegistry() bool {
 	return e == EnvMinikube || e == EnvDockerDesktop || e == EnvMicroK8s
 }
 
+// Clusters whose nodes are Docker containers, so we can load images
+// straight into the nodes instead of pushing them to a registry.
+func (e Env) LoadsImagesIntoNodes() bool {
+	return e == EnvKIND || e == EnvK3D
+}
+
 func (e Env) IsLocalCluster() bool {
 	return e == EnvMinikube || e == EnvDockerDesktop || e == EnvMicroK8s || e == EnvKIND || e == EnvK3D
 }
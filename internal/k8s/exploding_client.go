@@ -7,8 +7,10 @@ import (
 
 	"github.com/docker/distribution/reference"
 	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
 
 	"github.com/windmilleng/tilt/internal/container"
@@ -33,6 +35,14 @@ func (ec *explodingClient) GetByReference(ref v1.ObjectReference) (K8sEntity, er
 	return K8sEntity{}, errors.Wrap(ec.err, "could not set up k8s client")
 }
 
+func (ec *explodingClient) ListByLabel(ctx context.Context, gvks []schema.GroupVersionKind, lps []model.LabelPair) ([]K8sEntity, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) Diff(ctx context.Context, entities []K8sEntity) ([]EntityDiff, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
 func (ec *explodingClient) PodsWithImage(ctx context.Context, image reference.NamedTagged, n Namespace, lp []model.LabelPair) ([]v1.Pod, error) {
 	return nil, errors.Wrap(ec.err, "could not set up k8s client")
 }
@@ -53,8 +63,8 @@ func (ec *explodingClient) ContainerLogs(ctx context.Context, podID PodID, cName
 	return nil, errors.Wrap(ec.err, "could not set up k8s client")
 }
 
-func (ec *explodingClient) ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (localPort int, closer func(), err error) {
-	return 0, nil, errors.Wrap(ec.err, "could not set up k8s client")
+func (ec *explodingClient) ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (PortForwardTunnel, error) {
+	return PortForwardTunnel{}, errors.Wrap(ec.err, "could not set up k8s client")
 }
 
 func (ec *explodingClient) WatchPods(ctx context.Context, lps labels.Selector) (<-chan *v1.Pod, error) {
@@ -65,6 +75,10 @@ func (ec *explodingClient) WatchServices(ctx context.Context, lps []model.LabelP
 	return nil, errors.Wrap(ec.err, "could not set up k8s client")
 }
 
+func (ec *explodingClient) WatchJobs(ctx context.Context, lps []model.LabelPair) (<-chan *batchv1.Job, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
 func (ec *explodingClient) WatchEvents(ctx context.Context) (<-chan *v1.Event, error) {
 	return nil, errors.Wrap(ec.err, "could not set up k8s client")
 }
@@ -77,6 +91,10 @@ func (ec *explodingClient) ContainerRuntime(ctx context.Context) container.Runti
 	return container.RuntimeUnknown
 }
 
+func (ec *explodingClient) NodeNames(ctx context.Context) ([]string, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
 func (ec *explodingClient) PrivateRegistry(ctx context.Context) container.Registry {
 	return ""
 }
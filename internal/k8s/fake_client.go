@@ -12,8 +12,10 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
 
 	"github.com/windmilleng/tilt/internal/container"
@@ -39,14 +41,25 @@ type FakeK8sClient struct {
 	DeletedYaml string
 	DeleteError error
 
+	// Objects in the "cluster" that ListByLabel can find.
+	ListResults  []K8sEntity
+	LastListGVKs []schema.GroupVersionKind
+
+	// Returned by Diff. If nil, Diff reports that every object is new.
+	DiffResults []EntityDiff
+
 	LastPodQueryNamespace Namespace
 	LastPodQueryImage     reference.NamedTagged
 
 	PodLogsByPodAndContainer map[PodAndCName]BufferCloser
 	ContainerLogsError       error
 
+	portForwardMu             sync.Mutex
 	LastForwardPortPodID      PodID
 	LastForwardPortRemotePort int
+	ForwardPortCount          int
+	ForwardPortError          error
+	portForwardDones          []chan error
 
 	podWatcherMu sync.Mutex
 	podWatches   []fakePodWatch
@@ -54,6 +67,9 @@ type FakeK8sClient struct {
 	serviceWatcherMu sync.Mutex
 	serviceWatches   []fakeServiceWatch
 
+	jobWatcherMu sync.Mutex
+	jobWatches   []fakeJobWatch
+
 	eventsCh       chan *v1.Event
 	EventsWatchErr error
 
@@ -62,6 +78,7 @@ type FakeK8sClient struct {
 
 	Runtime  container.Runtime
 	Registry container.Registry
+	Nodes    []string
 
 	GetResources map[GetKey]K8sEntity
 
@@ -90,6 +107,11 @@ type fakeServiceWatch struct {
 	ch chan *v1.Service
 }
 
+type fakeJobWatch struct {
+	ls labels.Selector
+	ch chan *batchv1.Job
+}
+
 type fakePodWatch struct {
 	ls labels.Selector
 	ch chan *v1.Pod
@@ -128,6 +150,39 @@ func (c *FakeK8sClient) WatchServices(ctx context.Context, lps []model.LabelPair
 	return ch, nil
 }
 
+func (c *FakeK8sClient) EmitJob(ls labels.Selector, j *batchv1.Job) {
+	c.jobWatcherMu.Lock()
+	defer c.jobWatcherMu.Unlock()
+	for _, w := range c.jobWatches {
+		if SelectorEqual(ls, w.ls) {
+			w.ch <- j
+		}
+	}
+}
+
+func (c *FakeK8sClient) WatchJobs(ctx context.Context, lps []model.LabelPair) (<-chan *batchv1.Job, error) {
+	c.jobWatcherMu.Lock()
+	ch := make(chan *batchv1.Job, 20)
+	ls := LabelPairsToSelector(lps)
+	c.jobWatches = append(c.jobWatches, fakeJobWatch{ls, ch})
+	c.jobWatcherMu.Unlock()
+
+	go func() {
+		// when ctx is canceled, remove the label selector from the list of watched label selectors
+		<-ctx.Done()
+		c.jobWatcherMu.Lock()
+		var newWatches []fakeJobWatch
+		for _, e := range c.jobWatches {
+			if !SelectorEqual(e.ls, ls) {
+				newWatches = append(newWatches, e)
+			}
+		}
+		c.jobWatches = newWatches
+		c.jobWatcherMu.Unlock()
+	}()
+	return ch, nil
+}
+
 func (c *FakeK8sClient) WatchEvents(ctx context.Context) (<-chan *v1.Event, error) {
 	if c.EventsWatchErr != nil {
 		err := c.EventsWatchErr
@@ -241,6 +296,28 @@ func (c *FakeK8sClient) Delete(ctx context.Context, entities []K8sEntity) error
 	return nil
 }
 
+func (c *FakeK8sClient) ListByLabel(ctx context.Context, gvks []schema.GroupVersionKind, lps []model.LabelPair) ([]K8sEntity, error) {
+	c.LastListGVKs = gvks
+	result, _, err := FilterByMetadataLabels(c.ListResults, makeLabelSet(lps))
+	return result, err
+}
+
+func (c *FakeK8sClient) Diff(ctx context.Context, entities []K8sEntity) ([]EntityDiff, error) {
+	if c.DiffResults != nil {
+		return c.DiffResults, nil
+	}
+
+	result := make([]EntityDiff, 0, len(entities))
+	for _, e := range entities {
+		diff, err := DiffEntity(nil, e)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, diff)
+	}
+	return result, nil
+}
+
 func (c *FakeK8sClient) GetByReference(ref v1.ObjectReference) (K8sEntity, error) {
 	group := getGroup(ref)
 	kind := ref.Kind
@@ -282,8 +359,13 @@ func (c *FakeK8sClient) PodByID(ctx context.Context, pID PodID, n Namespace) (*v
 }
 
 func FakePodStatus(image reference.NamedTagged, phase string) v1.PodStatus {
+	var conditions []v1.PodCondition
+	if v1.PodPhase(phase) == v1.PodRunning {
+		conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+	}
 	return v1.PodStatus{
-		Phase: v1.PodPhase(phase),
+		Phase:      v1.PodPhase(phase),
+		Conditions: conditions,
 		ContainerStatuses: []v1.ContainerStatus{
 			{
 				Name:        "main",
@@ -326,10 +408,42 @@ func (c *FakeK8sClient) applyWasCalled() bool {
 	return c.Yaml != ""
 }
 
-func (c *FakeK8sClient) ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (int, func(), error) {
+func (c *FakeK8sClient) ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (PortForwardTunnel, error) {
+	c.portForwardMu.Lock()
+	defer c.portForwardMu.Unlock()
+
 	c.LastForwardPortPodID = podID
 	c.LastForwardPortRemotePort = remotePort
-	return optionalLocalPort, func() {}, nil
+	c.ForwardPortCount++
+	if c.ForwardPortError != nil {
+		return PortForwardTunnel{}, c.ForwardPortError
+	}
+
+	done := make(chan error, 1)
+	c.portForwardDones = append(c.portForwardDones, done)
+	return NewPortForwardTunnel(optionalLocalPort, func() {}, done), nil
+}
+
+// Breaks all the open port-forwards with the given error.
+func (c *FakeK8sClient) BreakPortForwards(err error) {
+	c.portForwardMu.Lock()
+	defer c.portForwardMu.Unlock()
+	for _, done := range c.portForwardDones {
+		done <- err
+	}
+	c.portForwardDones = nil
+}
+
+func (c *FakeK8sClient) SetForwardPortError(err error) {
+	c.portForwardMu.Lock()
+	defer c.portForwardMu.Unlock()
+	c.ForwardPortError = err
+}
+
+func (c *FakeK8sClient) GetForwardPortCount() int {
+	c.portForwardMu.Lock()
+	defer c.portForwardMu.Unlock()
+	return c.ForwardPortCount
 }
 
 func (c *FakeK8sClient) ContainerRuntime(ctx context.Context) container.Runtime {
@@ -339,6 +453,10 @@ func (c *FakeK8sClient) ContainerRuntime(ctx context.Context) container.Runtime
 	return container.RuntimeDocker
 }
 
+func (c *FakeK8sClient) NodeNames(ctx context.Context) ([]string, error) {
+	return c.Nodes, nil
+}
+
 func (c *FakeK8sClient) PrivateRegistry(ctx context.Context) container.Registry {
 	return c.Registry
 }
@@ -1,6 +1,8 @@
 package k8s
 
 import (
+	"crypto/sha1"
+	"fmt"
 	"strconv"
 
 	"github.com/google/uuid"
@@ -17,6 +19,10 @@ const ManifestNameLabel = "tilt-manifest"
 
 const TiltDeployIDLabel = "tilt-deployid"
 
+// Marks every object deployed from a particular Tiltfile, so that we can
+// find (and prune) objects that are no longer in the Tiltfile.
+const TiltOwnerIDLabel = "tilt-owner"
+
 func TiltRunLabel() model.LabelPair {
 	return model.LabelPair{
 		Key:   TiltRunIDLabel,
@@ -31,6 +37,15 @@ func TiltDeployLabel(dID model.DeployID) model.LabelPair {
 	}
 }
 
+// Label values can be at most 63 characters, so we identify
+// the Tiltfile by a hash of its absolute path.
+func TiltOwnerLabel(tiltfilePath string) model.LabelPair {
+	return model.LabelPair{
+		Key:   TiltOwnerIDLabel,
+		Value: fmt.Sprintf("%x", sha1.Sum([]byte(tiltfilePath))),
+	}
+}
+
 func TiltRunSelector() labels.Selector {
 	return labels.Set{TiltRunIDLabel: TiltRunID}.AsSelector()
 }
@@ -0,0 +1,48 @@
+package k8s
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// A namespace that replaces the namespaces in the YAML (e.g., from `tilt up --namespace`),
+// so that several people can run the same Tiltfile against a shared cluster.
+//
+// Empty if there's no override.
+type NamespaceOverride Namespace
+
+func (n NamespaceOverride) Empty() bool { return n == "" }
+
+// Moves every namespaced entity into the given namespace.
+//
+// Cluster-scoped entities (like Namespaces and ClusterRoles) stay where they are.
+// If the RESTMapper doesn't know about a kind (e.g., a CRD that's created by the
+// same YAML), we assume it's namespaced, because most custom resources are.
+func overrideNamespace(entities []K8sEntity, ns NamespaceOverride, mapper meta.RESTMapper) ([]K8sEntity, error) {
+	if ns.Empty() {
+		return entities, nil
+	}
+
+	result := make([]K8sEntity, 0, len(entities))
+	for _, e := range entities {
+		gvk := e.GVK()
+		rm, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil && !meta.IsNoMatchError(err) {
+			return nil, errors.Wrapf(err, "error mapping %s", gvk)
+		}
+
+		if rm != nil && rm.Scope.Name() != meta.RESTScopeNameNamespace {
+			result = append(result, e)
+			continue
+		}
+
+		e = e.DeepCopy()
+		accessor, err := meta.Accessor(e.Obj)
+		if err != nil {
+			return nil, errors.Wrapf(err, "setting namespace of %s/%s", gvk.Kind, e.Name())
+		}
+		accessor.SetNamespace(string(ns))
+		result = append(result, e)
+	}
+	return result, nil
+}
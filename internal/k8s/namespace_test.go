@@ -0,0 +1,53 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+)
+
+func TestOverrideNamespace(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML + "\n---\n" + testyaml.MyNamespaceYAML + "\n---\n" + testyaml.CRDYAML)
+	require.NoError(t, err)
+
+	result, err := overrideNamespace(entities, "dev-nick", testRESTMapper())
+	require.NoError(t, err)
+
+	namespaces := make(map[string]string)
+	for _, e := range result {
+		namespaces[e.GVK().Kind] = e.meta().GetNamespace()
+	}
+	assert.Equal(t, map[string]string{
+		"Deployment":               "dev-nick",
+		"Namespace":                "",
+		"CustomResourceDefinition": "",
+
+		// Not known to the RESTMapper, so we assume it's namespaced.
+		"Project": "dev-nick",
+	}, namespaces)
+
+	// The original entities are left alone.
+	assert.Equal(t, Namespace("sancho-ns"), entities[0].Namespace())
+}
+
+func TestOverrideNamespaceEmpty(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+
+	result, err := overrideNamespace(entities, "", testRESTMapper())
+	require.NoError(t, err)
+	assert.Equal(t, Namespace("sancho-ns"), result[0].Namespace())
+}
+
+func testRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1beta1", Kind: "CustomResourceDefinition"}, meta.RESTScopeRoot)
+	return mapper
+}
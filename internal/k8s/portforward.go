@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"sync"
 
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp" // registers gcp auth provider
@@ -18,8 +19,30 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (k K8sClient) ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (localPort int, closer func(), err error) {
-	localPort = optionalLocalPort
+// An open port-forward to a pod.
+type PortForwardTunnel struct {
+	LocalPort int
+
+	// Receives an error if the tunnel breaks on its own (e.g., because the pod
+	// went away, or we lost our connection to the cluster). Nothing is sent
+	// after the tunnel is closed.
+	Done <-chan error
+
+	closer func()
+}
+
+func NewPortForwardTunnel(localPort int, closer func(), done <-chan error) PortForwardTunnel {
+	return PortForwardTunnel{LocalPort: localPort, Done: done, closer: closer}
+}
+
+func (t PortForwardTunnel) Close() {
+	if t.closer != nil {
+		t.closer()
+	}
+}
+
+func (k K8sClient) ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (PortForwardTunnel, error) {
+	localPort := optionalLocalPort
 	if localPort == 0 {
 		// preferably, we'd set the localport to 0, and let the underlying function pick a port for us,
 		// to avoid the race condition potential of something else grabbing this port between
@@ -27,24 +50,25 @@ func (k K8sClient) ForwardPort(ctx context.Context, namespace Namespace, podID P
 		// the k8s client supports a local port of 0, and stores the actual local port assigned in a field,
 		// but unfortunately does not export that field, so there is no way for the caller to know which
 		// local port to talk to.
+		var err error
 		localPort, err = getAvailablePort()
 		if err != nil {
-			return 0, nil, errors.Wrap(err, "failed to find an available local port")
+			return PortForwardTunnel{}, errors.Wrap(err, "failed to find an available local port")
 		}
 	}
 
-	closer, err = k.portForwarder(ctx, k.restConfig, k.core, namespace.String(), podID, localPort, remotePort)
+	closer, done, err := k.portForwarder(ctx, k.restConfig, k.core, namespace.String(), podID, localPort, remotePort)
 	if err != nil {
-		return 0, nil, err
+		return PortForwardTunnel{}, err
 	}
 
-	return localPort, closer, nil
+	return NewPortForwardTunnel(localPort, closer, done), nil
 }
 
-func portForwarder(ctx context.Context, restConfig *rest.Config, core v1.CoreV1Interface, namespace string, podID PodID, localPort int, remotePort int) (closer func(), err error) {
+func portForwarder(ctx context.Context, restConfig *rest.Config, core v1.CoreV1Interface, namespace string, podID PodID, localPort int, remotePort int) (closer func(), done <-chan error, err error) {
 	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
 	if err != nil {
-		return nil, errors.Wrap(err, "error getting roundtripper")
+		return nil, nil, errors.Wrap(err, "error getting roundtripper")
 	}
 
 	req := core.RESTClient().Post().
@@ -54,9 +78,6 @@ func portForwarder(ctx context.Context, restConfig *rest.Config, core v1.CoreV1I
 		SubResource("portforward")
 
 	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
-	if err != nil {
-		return nil, errors.Wrap(err, "error creating dialer")
-	}
 
 	stopChan := make(chan struct{}, 1)
 	readyChan := make(chan struct{}, 1)
@@ -71,30 +92,45 @@ func portForwarder(ctx context.Context, restConfig *rest.Config, core v1.CoreV1I
 		logger.Get(ctx).Writer(logger.DebugLvl))
 
 	if err != nil {
-		return nil, errors.Wrap(err, "error forwarding port")
+		return nil, nil, errors.Wrap(err, "error forwarding port")
 	}
 
-	errChan := make(chan error)
+	errChan := make(chan error, 1)
 	go func() {
 		errChan <- pf.ForwardPorts()
-		err := <-errChan
-		pf.Close()
-		// logging isn't really sufficient, since we're in a goroutine and who knows where the caller
-		// has moved on to by this point, but other options are much more expensive (e.g., monitoring the state
-		// of the port forward from the caller and/or automatically reconnecting port forwards)
-		logger.Get(ctx).Infof("error from port forward: %v", err)
 	}()
 
 	select {
 	case err = <-errChan:
 		pf.Close()
-		return nil, errors.Wrap(err, "error forwarding port")
+		return nil, nil, errors.Wrap(err, "error forwarding port")
 	case <-pf.Ready:
-		closer = func() {
-			close(stopChan)
-		}
-		return closer, nil
 	}
+
+	var closeOnce sync.Once
+	closer = func() {
+		closeOnce.Do(func() { close(stopChan) })
+	}
+
+	// ForwardPorts returns when we close the tunnel, or when the connection
+	// to the pod drops (in which case it returns a nil error).
+	doneCh := make(chan error, 1)
+	go func() {
+		err := <-errChan
+		pf.Close()
+
+		select {
+		case <-stopChan:
+			return
+		default:
+		}
+
+		if err == nil {
+			err = fmt.Errorf("lost connection to pod %s", podID)
+		}
+		doneCh <- err
+	}()
+	return closer, doneCh, nil
 }
 
 func getAvailablePort() (int, error) {
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"sync"
 
+	"github.com/pkg/errors"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apiv1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -60,6 +61,19 @@ func (c K8sClient) ContainerRuntime(ctx context.Context) container.Runtime {
 	return c.runtimeAsync.Runtime(ctx)
 }
 
+func (c K8sClient) NodeNames(ctx context.Context) ([]string, error) {
+	nodeList, err := c.core.Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing nodes")
+	}
+
+	result := make([]string, 0, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		result = append(result, node.Name)
+	}
+	return result, nil
+}
+
 func ProvideContainerRuntime(ctx context.Context, kCli Client) container.Runtime {
 	return kCli.ContainerRuntime(ctx)
 }
@@ -24,12 +24,26 @@ func NewTarget(
 	// so that the resource type appears
 	displayNames := UniqueNames(entities, 2)
 
+	withPodTemplates, _, err := FilterByHasPodTemplateSpec(entities)
+	if err != nil {
+		return model.K8sTarget{}, err
+	}
+
+	hasJobs := false
+	for _, e := range entities {
+		if e.GVK().Kind == "Job" {
+			hasJobs = true
+		}
+	}
+
 	return model.K8sTarget{
 		Name:              name,
 		YAML:              yaml,
 		PortForwards:      portForwards,
 		ExtraPodSelectors: extraPodSelectors,
 		DisplayNames:      displayNames,
+		HasPodTemplates:   len(withPodTemplates) > 0,
+		HasJobs:           hasJobs,
 	}.WithDependencyIDs(dependencyIDs).WithRefInjectCounts(refInjectCounts), nil
 }
 
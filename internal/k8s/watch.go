@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,6 +25,22 @@ type watcher interface {
 }
 
 func (kCli K8sClient) makeWatcher(f watcherFactory, ls labels.Selector) (watch.Interface, Namespace, error) {
+	// With a namespace override, everything we deploy is in that namespace,
+	// and we may not be allowed to watch anything else.
+	if !kCli.nsOverride.Empty() {
+		ns := Namespace(kCli.nsOverride)
+		w := f(ns.String())
+		if w == nil {
+			return nil, "", nil
+		}
+
+		watcher, err := w.Watch(metav1.ListOptions{LabelSelector: ls.String()})
+		if err != nil {
+			return nil, "", err
+		}
+		return watcher, ns, nil
+	}
+
 	// passing "" gets us all namespaces
 	w := f("")
 	if w == nil {
@@ -226,3 +243,48 @@ func (kCli K8sClient) WatchServices(ctx context.Context, lps []model.LabelPair)
 
 	return ch, nil
 }
+
+func (kCli K8sClient) WatchJobs(ctx context.Context, lps []model.LabelPair) (<-chan *batchv1.Job, error) {
+	ch := make(chan *batchv1.Job)
+
+	ls := labels.Set{}
+	for _, lp := range lps {
+		ls[lp.Key] = lp.Value
+	}
+
+	watcher, _, err := kCli.makeWatcher(func(ns string) watcher {
+		return kCli.clientSet.BatchV1().Jobs(ns)
+	}, ls.AsSelector())
+	if err != nil {
+		return nil, errors.Wrap(err, "Jobs.WatchFiles")
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					close(ch)
+					return
+				}
+
+				if event.Object == nil {
+					continue
+				}
+
+				job, ok := event.Object.(*batchv1.Job)
+				if !ok {
+					continue
+				}
+
+				ch <- job
+			case <-ctx.Done():
+				watcher.Stop()
+				close(ch)
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -152,6 +152,37 @@ func TestK8sClient_WatchServicesBlockedByNamespaceRestriction(t *testing.T) {
 	}
 }
 
+func TestK8sClient_WatchPodsWithNamespaceOverride(t *testing.T) {
+	tf := newWatchTestFixture(t)
+	defer tf.TearDown()
+
+	// We only watch the override namespace, even if we're allowed to watch everything.
+	tf.nsRestriction = "dev-nick"
+	tf.kCli.nsOverride = "dev-nick"
+
+	pod1 := fakePod(PodID("pod1"), "image1")
+	pod1.Namespace = "dev-nick"
+
+	input := []runtime.Object{&pod1}
+	expected := []runtime.Object{&pod1}
+	tf.runPods(input, expected)
+}
+
+func TestK8sClient_WatchServicesWithNamespaceOverride(t *testing.T) {
+	tf := newWatchTestFixture(t)
+	defer tf.TearDown()
+
+	tf.nsRestriction = "dev-nick"
+	tf.kCli.nsOverride = "dev-nick"
+
+	svc1 := fakeService("svc1")
+	svc1.Namespace = "dev-nick"
+
+	input := []runtime.Object{&svc1}
+	expected := []runtime.Object{&svc1}
+	tf.runServices(input, expected)
+}
+
 func TestK8sClient_WatchEvents(t *testing.T) {
 	tf := newWatchTestFixture(t)
 	defer tf.TearDown()
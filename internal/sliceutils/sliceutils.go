@@ -69,3 +69,13 @@ func AppendWithoutDupes(a []string, b ...string) []string {
 
 	return ret
 }
+
+// Contains returns true if the slice contains the given elem.
+func Contains(slice []string, elem string) bool {
+	for _, s := range slice {
+		if s == elem {
+			return true
+		}
+	}
+	return false
+}
@@ -37,3 +37,9 @@ func TestAppendWithoutDupesEmptyB(t *testing.T) {
 	expected := []string{"a", "b"}
 	assert.Equal(t, expected, observed)
 }
+
+func TestContains(t *testing.T) {
+	assert.True(t, Contains([]string{"a", "b"}, "b"))
+	assert.False(t, Contains([]string{"a", "b"}, "c"))
+	assert.False(t, Contains(nil, "a"))
+}
@@ -114,3 +114,19 @@ func (AnalyticsOptAction) Action() {}
 type AnalyticsNudgeSurfacedAction struct{}
 
 func (AnalyticsNudgeSurfacedAction) Action() {}
+
+// Turns a resource on or off at runtime, without reloading the Tiltfile.
+type SetResourceEnabledAction struct {
+	ManifestName model.ManifestName
+	Enabled      bool
+}
+
+func (SetResourceEnabledAction) Action() {}
+
+// Approves the pending k8s changes of a resource (with --confirm-changes),
+// so that the next build applies them.
+type ApproveK8sChangesAction struct {
+	ManifestName model.ManifestName
+}
+
+func (ApproveK8sChangesAction) Action() {}
@@ -5,6 +5,7 @@ import (
 	"sort"
 
 	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/windmilleng/tilt/internal/container"
@@ -25,6 +26,16 @@ type BuildResult struct {
 	// The tag is derived from a content-addressable digest.
 	Image reference.NamedTagged
 
+	// A digest of the build context, Dockerfile, and build args
+	// that the image was built from.
+	//
+	// If a later build has the same digest, we can re-use the image.
+	ContextDigest digest.Digest
+
+	// True if we re-used the image from the previous build,
+	// because the contents of its build context didn't change.
+	NoOp bool
+
 	// The ID of the container that Docker Compose created.
 	//
 	// When we deploy a Docker Compose service, we wait synchronously for the
@@ -75,6 +86,13 @@ func NewK8sDeployResult(id model.TargetID, uids []types.UID) BuildResult {
 	}
 }
 
+// For local targets.
+func NewLocalBuildResult(id model.TargetID) BuildResult {
+	return BuildResult{
+		TargetID: id,
+	}
+}
+
 func (b BuildResult) IsEmpty() bool {
 	return b.TargetID.Empty()
 }
@@ -83,6 +101,11 @@ func (b BuildResult) HasImage() bool {
 	return b.Image != nil
 }
 
+func (b BuildResult) WithContextDigest(d digest.Digest) BuildResult {
+	b.ContextDigest = d
+	return b
+}
+
 func (b BuildResult) IsInPlaceUpdate() bool {
 	return len(b.LiveUpdatedContainerIDs) != 0
 }
@@ -17,6 +17,18 @@ import (
 	"github.com/windmilleng/tilt/pkg/model"
 )
 
+type EngineMode int
+
+const (
+	// Build and deploy everything, then keep watching for changes
+	// until the user exits.
+	EngineModeUp EngineMode = iota
+
+	// Build and deploy everything once, wait until it's all healthy,
+	// then exit. Exits with an error if anything fails.
+	EngineModeCI
+)
+
 type EngineState struct {
 	TiltBuildInfo model.TiltBuild
 	TiltStartTime time.Time
@@ -27,9 +39,17 @@ type EngineState struct {
 	// TODO(nick): This will eventually be a general Target index.
 	ManifestTargets map[model.ManifestName]*ManifestTarget
 
-	CurrentlyBuilding model.ManifestName
+	// The manifests that the BuildController is currently building.
+	CurrentlyBuilding map[model.ManifestName]bool
 	WatchFiles        bool
 
+	// Whether we're running interactively (`tilt up`) or
+	// running to completion (`tilt ci`).
+	EngineMode EngineMode
+
+	// In CI mode, how long to wait for everything to become healthy before giving up.
+	CITimeout time.Duration
+
 	// How many builds were queued on startup (i.e., how many manifests there were
 	// after initial Tiltfile load)
 	InitialBuildsQueued int
@@ -37,11 +57,22 @@ type EngineState struct {
 	// How many builds have been completed (pass or fail) since starting tilt
 	CompletedBuildCount int
 
-	// For synchronizing BuildController so that it's only
-	// doing one action at a time. In the future, we might
-	// want to allow it to parallelize builds better, but that
-	// would require better tools for triaging output to different streams.
-	BuildControllerActionCount int
+	// How many builds have been started since starting tilt.
+	//
+	// For synchronizing BuildController, so that it doesn't start
+	// a new build until the previous one has been recorded in CurrentlyBuilding.
+	StartedBuildCount int
+
+	// Update settings from the Tiltfile.
+	UpdateSettings model.UpdateSettings
+
+	// Set from the command-line. If non-zero, takes precedence
+	// over the Tiltfile's max_parallel_updates.
+	MaxParallelUpdatesOverride int
+
+	// Set from the command-line. If true, we show the user a diff and wait
+	// for them to approve certain changes before we apply them to the cluster.
+	ConfirmChanges bool
 
 	PermanentError error
 
@@ -59,6 +90,13 @@ type EngineState struct {
 	// InitManifests is the list of manifest names that we were told to init from the CLI.
 	InitManifests []model.ManifestName
 
+	// ConfigArgs are the args passed after `--` on the CLI, for the Tiltfile's config module.
+	ConfigArgs []string
+
+	// What we persisted at the end of the previous Tilt session. We remove
+	// each resource once we've restored it onto its ManifestState.
+	History History
+
 	TriggerQueue []model.ManifestName
 
 	LogTimestamps bool
@@ -81,6 +119,17 @@ type EngineState struct {
 	TeamName string
 }
 
+// The maximum number of manifests that the BuildController may build at once.
+func (e EngineState) MaxParallelUpdates() int {
+	if e.MaxParallelUpdatesOverride > 0 {
+		return e.MaxParallelUpdatesOverride
+	}
+	if e.UpdateSettings.MaxParallelUpdates > 0 {
+		return e.UpdateSettings.MaxParallelUpdates
+	}
+	return model.DefaultMaxParallelUpdates
+}
+
 func (e *EngineState) ManifestNamesForTargetID(id model.TargetID) []model.ManifestName {
 	result := make([]model.ManifestName, 0)
 	for mn, state := range e.ManifestTargets {
@@ -96,6 +145,9 @@ func (e *EngineState) ManifestNamesForTargetID(id model.TargetID) []model.Manife
 		if manifest.DockerComposeTarget().ID() == id {
 			result = append(result, mn)
 		}
+		if manifest.LocalTarget().ID() == id {
+			result = append(result, mn)
+		}
 	}
 	return result
 }
@@ -176,6 +228,24 @@ func (e EngineState) Targets() []*ManifestTarget {
 	return result
 }
 
+// Returns the resource_deps of this manifest that aren't ready yet.
+//
+// Dependencies that aren't loaded at all (e.g., because the user only asked
+// for a subset of resources) can never become ready, so we ignore them.
+func (e EngineState) UnreadyDependencies(m model.Manifest) []model.ManifestName {
+	var result []model.ManifestName
+	for _, dep := range m.ResourceDependencies {
+		mt, ok := e.ManifestTargets[dep]
+		if !ok {
+			continue
+		}
+		if !mt.IsReadyForDependents() {
+			result = append(result, dep)
+		}
+	}
+	return result
+}
+
 func (e EngineState) RelativeTiltfilePath() (string, error) {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -200,6 +270,14 @@ type BuildStatus struct {
 	PendingFileChanges map[string]time.Time
 
 	LastSuccessfulResult BuildResult
+
+	// When we started the build that produced LastSuccessfulResult.
+	LastSuccessfulBuildTime time.Time
+
+	// True if LastSuccessfulResult was restored from a previous Tilt session.
+	// Files might have changed while Tilt wasn't watching them, so we need to
+	// check them before we reuse the result.
+	RestoredFromHistory bool
 }
 
 func newBuildStatus() *BuildStatus {
@@ -242,6 +320,17 @@ type ManifestState struct {
 	// around for a little while so we can show it in the UX.
 	CrashLog model.Log
 
+	// How many times in a row we've redeployed the resource because it crashed,
+	// under its RestartPolicy. Reset by any build that's not a crash rebuild.
+	CrashRestartCount int
+
+	// Why the resource crashed the last time we redeployed it (or gave up).
+	CrashRestartReason string
+
+	// Set when the resource crashed more times in a row than its RestartPolicy
+	// allows, so we stopped redeploying it.
+	GaveUpRestarting bool
+
 	// The log stream for this resource
 	CombinedLog model.Log `testdiff:"ignore"`
 
@@ -249,6 +338,24 @@ type ManifestState struct {
 	ConfigFilesThatCausedChange []string
 
 	K8sWarnEvents []k8s.EventWithEntity
+
+	// The port-forwards to the resource's pods, by local port.
+	PortForwards map[int]PortForwardState
+
+	// Disabled resources aren't watched, built, or deployed. Anything
+	// we already deployed for them is deleted.
+	Disabled bool
+
+	// With --confirm-changes, the changes to live k8s objects that we're
+	// holding back until the user approves them, and the changes that
+	// the user approved.
+	PendingK8sChanges  []k8s.EntityDiff
+	ApprovedK8sChanges []k8s.EntityDiff
+
+	// Builds from the previous Tilt session, restored from disk. We show
+	// them in the UI until this session has builds of its own.
+	PreviousBuildHistory []model.BuildRecord
+	PreviousDeployTime   time.Time
 }
 
 func NewState() *EngineState {
@@ -256,6 +363,8 @@ func NewState() *EngineState {
 	ret.Log = model.Log{}
 	ret.ManifestTargets = make(map[model.ManifestName]*ManifestTarget)
 	ret.PendingConfigFileChanges = make(map[string]time.Time)
+	ret.CurrentlyBuilding = make(map[model.ManifestName]bool)
+	ret.UpdateSettings = model.DefaultUpdateSettings()
 	return ret
 }
 
@@ -267,6 +376,58 @@ func newManifestState(mn model.ManifestName) *ManifestState {
 	}
 }
 
+type PortForwardStatus string
+
+const (
+	PortForwardStatusActive       PortForwardStatus = "active"
+	PortForwardStatusReconnecting PortForwardStatus = "reconnecting"
+
+	// We haven't been able to reconnect for a while. We keep trying,
+	// but the user probably needs to look into it.
+	PortForwardStatusFailed PortForwardStatus = "failed"
+)
+
+type PortForwardState struct {
+	Forward model.PortForward
+	PodID   k8s.PodID
+	Status  PortForwardStatus
+
+	// Why the port-forward isn't active, if it's not.
+	Error string
+}
+
+// The states of the port-forwards to the most recent pod, sorted by local port.
+func (ms *ManifestState) CurrentPortForwards() []PortForwardState {
+	podID := ms.MostRecentPod().PodID
+	if podID == "" {
+		return nil
+	}
+
+	var result []PortForwardState
+	for _, pf := range ms.PortForwards {
+		if pf.PodID == podID {
+			result = append(result, pf)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Forward.LocalPort < result[j].Forward.LocalPort
+	})
+	return result
+}
+
+func (ms *ManifestState) PortForwardsView() []view.PortForward {
+	var result []view.PortForward
+	for _, pf := range ms.CurrentPortForwards() {
+		result = append(result, view.PortForward{
+			LocalPort:     pf.Forward.LocalPort,
+			ContainerPort: pf.Forward.ContainerPort,
+			Status:        string(pf.Status),
+			Error:         pf.Error,
+		})
+	}
+	return result
+}
+
 func (ms *ManifestState) TargetID() model.TargetID {
 	return model.TargetID{
 		Type: model.TargetTypeManifest,
@@ -301,6 +462,11 @@ func (ms *ManifestState) IsDC() bool {
 	return ok
 }
 
+func (ms *ManifestState) LocalRuntimeState() LocalRuntimeState {
+	ret, _ := ms.RuntimeState.(LocalRuntimeState)
+	return ret
+}
+
 func (ms *ManifestState) K8sRuntimeState() K8sRuntimeState {
 	ret, _ := ms.RuntimeState.(K8sRuntimeState)
 	return ret
@@ -338,6 +504,22 @@ func (ms *ManifestState) AddCompletedBuild(bs model.BuildRecord) {
 	}
 }
 
+// The builds to show in the UI. Until this session builds the resource,
+// that's the builds from the previous session.
+func (ms *ManifestState) BuildHistoryForDisplay() []model.BuildRecord {
+	if len(ms.BuildHistory) == 0 {
+		return ms.PreviousBuildHistory
+	}
+	return ms.BuildHistory
+}
+
+func (ms *ManifestState) LastDeployTimeForDisplay() time.Time {
+	if ms.LastSuccessfulDeployTime.IsZero() {
+		return ms.PreviousDeployTime
+	}
+	return ms.LastSuccessfulDeployTime
+}
+
 func (ms *ManifestState) StartedFirstBuild() bool {
 	return !ms.CurrentBuild.Empty() || len(ms.BuildHistory) > 0
 }
@@ -376,7 +558,18 @@ func (ms *ManifestState) NextBuildReason() model.BuildReason {
 // Used to determine if changes to synced files or config files
 // should kick off a new build.
 func (ms *ManifestState) IsPendingTime(t time.Time) bool {
-	return !t.IsZero() && t.After(ms.LastBuild().StartTime)
+	return !t.IsZero() && t.After(ms.lastUnsupersededBuild().StartTime)
+}
+
+// The most recent build that actually ran to completion.
+// Changes that came in during a superseded build are still pending.
+func (ms *ManifestState) lastUnsupersededBuild() model.BuildRecord {
+	for _, b := range ms.BuildHistory {
+		if !b.Superseded {
+			return b
+		}
+	}
+	return model.BuildRecord{}
 }
 
 // Whether changes have been made to this Manifest's synced files
@@ -522,7 +715,7 @@ func StateToView(s EngineState) view.View {
 
 		pendingBuildEdits = ospath.FileListDisplayNames(absWatchDirs, pendingBuildEdits)
 
-		buildHistory := append([]model.BuildRecord{}, ms.BuildHistory...)
+		buildHistory := append([]model.BuildRecord{}, ms.BuildHistoryForDisplay()...)
 		for i, build := range buildHistory {
 			build.Edits = ospath.FileListDisplayNames(absWatchDirs, build.Edits)
 			buildHistory[i] = build
@@ -541,11 +734,17 @@ func StateToView(s EngineState) view.View {
 		// "most interesting" pod that's crash looping, or show logs from all pods
 		// at once).
 		_, pendingBuildSince := ms.HasPendingChanges()
+
+		var waitingOn []model.ManifestName
+		if !ms.StartedFirstBuild() {
+			waitingOn = s.UnreadyDependencies(mt.Manifest)
+		}
+
 		r := view.Resource{
 			Name:               name,
 			DirectoriesWatched: relWatchDirs,
 			PathsWatched:       relWatchPaths,
-			LastDeployTime:     ms.LastSuccessfulDeployTime,
+			LastDeployTime:     ms.LastDeployTimeForDisplay(),
 			TriggerMode:        mt.Manifest.TriggerMode,
 			BuildHistory:       buildHistory,
 			PendingBuildEdits:  pendingBuildEdits,
@@ -555,6 +754,13 @@ func StateToView(s EngineState) view.View {
 			CrashLog:           ms.CrashLog,
 			Endpoints:          endpoints,
 			ResourceInfo:       resourceInfoView(mt),
+			WaitingOn:          waitingOn,
+			Disabled:           ms.Disabled,
+			CrashRestartCount:  ms.CrashRestartCount,
+			GaveUpRestarting:   ms.GaveUpRestarting,
+			Labels:             mt.Manifest.Labels,
+			PendingChanges:     ms.PendingK8sChanges,
+			PortForwards:       ms.PortForwardsView(),
 		}
 
 		ret.Resources = append(ret.Resources, r)
@@ -599,17 +805,32 @@ func resourceInfoView(mt *ManifestTarget) view.ResourceInfoView {
 		}
 	}
 
+	if mt.Manifest.IsLocal() {
+		lState := mt.State.LocalRuntimeState()
+		return view.LocalResourceInfo{
+			ServeStatus:    string(lState.Status),
+			ServePID:       lState.PID,
+			ServeStartTime: lState.StartTime,
+			ServeLog:       lState.Log(),
+		}
+	}
+
 	if dcState, ok := mt.State.RuntimeState.(dockercompose.State); ok {
 		return view.NewDCResourceInfo(mt.Manifest.DockerComposeTarget().ConfigPaths, dcState.Status, dcState.ContainerID, dcState.Log(), dcState.StartTime)
 	} else {
-		pod := mt.State.MostRecentPod()
+		kState := mt.State.K8sRuntimeState()
+		pod := kState.MostRecentPod()
+		job := kState.CurrentJob()
 		return view.K8sResourceInfo{
 			PodName:            pod.PodID.String(),
 			PodCreationTime:    pod.StartedAt,
 			PodUpdateStartTime: pod.UpdateStartTime,
-			PodStatus:          pod.Status,
+			PodStatus:          pod.DisplayStatus(),
 			PodRestarts:        pod.AllContainerRestarts() - pod.OldRestarts,
 			PodLog:             pod.CurrentLog,
+			JobStatus:          string(job.Status),
+			JobCompletionTime:  job.CompletionTime,
+			JobExitCode:        kState.JobExitCode(),
 			YAML:               mt.Manifest.K8sTarget().YAML,
 		}
 	}
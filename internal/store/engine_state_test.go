@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/windmilleng/tilt/internal/hud/view"
 	"github.com/windmilleng/tilt/internal/k8s/testyaml"
@@ -89,6 +90,27 @@ func TestMostRecentPod(t *testing.T) {
 	assert.Equal(t, "pod-b", podSet.MostRecentPod().PodID.String())
 }
 
+func TestPodPendingReadiness(t *testing.T) {
+	pod := Pod{
+		PodID:      "pod-a",
+		Phase:      v1.PodRunning,
+		Status:     "Running",
+		Containers: []Container{{Name: "main", Ready: false}},
+	}
+	assert.False(t, pod.IsReady())
+	assert.Equal(t, PodStatusPendingReadiness, pod.DisplayStatus())
+
+	pod.Ready = true
+	pod.Containers[0].Ready = true
+	assert.True(t, pod.IsReady())
+	assert.Equal(t, "Running", pod.DisplayStatus())
+
+	// Errors take precedence over readiness.
+	pod.Ready = false
+	pod.Status = "CrashLoopBackOff"
+	assert.Equal(t, "CrashLoopBackOff", pod.DisplayStatus())
+}
+
 func TestRelativeTiltfilePath(t *testing.T) {
 	es := newState([]model.Manifest{})
 	wd, err := os.Getwd()
@@ -113,3 +135,15 @@ func newState(manifests []model.Manifest) *EngineState {
 
 	return ret
 }
+
+func TestIsPendingTimeIgnoresSupersededBuilds(t *testing.T) {
+	ms := newManifestState("fe")
+	start := time.Now()
+	ms.AddCompletedBuild(model.BuildRecord{StartTime: start, FinishTime: start.Add(time.Second)})
+	ms.AddCompletedBuild(model.BuildRecord{StartTime: start.Add(2 * time.Second), Superseded: true})
+
+	// A change that came in before the superseded build started
+	// still counts as pending, because that build never finished.
+	assert.True(t, ms.IsPendingTime(start.Add(time.Second)))
+	assert.False(t, ms.IsPendingTime(start.Add(-time.Second)))
+}
@@ -0,0 +1,221 @@
+package store
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// How many lines of each log we keep between Tilt sessions.
+const HistoryLogLines = 500
+
+// The parts of the EngineState that we persist between Tilt sessions,
+// so that after a restart we still know what's deployed and why the last
+// build failed.
+type History struct {
+	Log       string                                 `json:"log"`
+	Resources map[model.ManifestName]ResourceHistory `json:"resources"`
+}
+
+type ResourceHistory struct {
+	DeployID       model.DeployID       `json:"deployID"`
+	LastDeployTime time.Time            `json:"lastDeployTime"`
+	BuildHistory   []BuildRecordHistory `json:"buildHistory"`
+	Images         []ImageHistory       `json:"images"`
+	Pods           []PodHistory         `json:"pods"`
+	Log            string               `json:"log"`
+}
+
+// A model.BuildRecord that can be serialized.
+type BuildRecordHistory struct {
+	Edits      []string          `json:"edits"`
+	Error      string            `json:"error"`
+	Warnings   []string          `json:"warnings"`
+	StartTime  time.Time         `json:"startTime"`
+	FinishTime time.Time         `json:"finishTime"`
+	Reason     model.BuildReason `json:"reason"`
+	Log        string            `json:"log"`
+}
+
+// An image that we built and deployed.
+type ImageHistory struct {
+	TargetID string `json:"targetID"`
+	Ref      string `json:"ref"`
+
+	// A digest of the ImageTarget that we built the image from. If the
+	// Tiltfile changes the target, we can't reuse the image.
+	SpecDigest string `json:"specDigest"`
+
+	// When we started building the image. If any of its files changed
+	// after this, we can't reuse the image.
+	BuildTime time.Time `json:"buildTime"`
+}
+
+type PodHistory struct {
+	PodID     k8s.PodID     `json:"podID"`
+	Namespace k8s.Namespace `json:"namespace"`
+	StartedAt time.Time     `json:"startedAt"`
+}
+
+var specDigestConfig = spew.ConfigState{
+	Indent:                  " ",
+	DisablePointerAddresses: true,
+	DisableCapacities:       true,
+	SortKeys:                true,
+}
+
+// A digest of everything that goes into building an image target,
+// so that we can tell if the Tiltfile changed it between sessions.
+func ImageSpecDigest(iTarget model.ImageTarget) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(specDigestConfig.Sdump(iTarget))))
+}
+
+func NewHistory(state EngineState) History {
+	h := History{
+		Log:       state.Log.Tail(HistoryLogLines).String(),
+		Resources: make(map[model.ManifestName]ResourceHistory),
+	}
+
+	for _, mt := range state.Targets() {
+		h.Resources[mt.Manifest.Name] = newResourceHistory(mt)
+	}
+
+	// Hang on to resources that we haven't loaded yet in this session,
+	// (e.g., because the Tiltfile is broken), so that we don't forget them.
+	for mn, rh := range state.History.Resources {
+		if _, ok := h.Resources[mn]; !ok {
+			h.Resources[mn] = rh
+		}
+	}
+	return h
+}
+
+func newResourceHistory(mt *ManifestTarget) ResourceHistory {
+	ms := mt.State
+	rh := ResourceHistory{
+		DeployID:       ms.DeployID,
+		LastDeployTime: ms.LastDeployTimeForDisplay(),
+		Log:            ms.CombinedLog.Tail(HistoryLogLines).String(),
+	}
+
+	for _, b := range ms.BuildHistoryForDisplay() {
+		rh.BuildHistory = append(rh.BuildHistory, newBuildRecordHistory(b))
+	}
+
+	for _, iTarget := range mt.Manifest.ImageTargets {
+		status := ms.BuildStatus(iTarget.ID())
+		result := status.LastSuccessfulResult
+		if !result.HasImage() || result.IsInPlaceUpdate() {
+			continue
+		}
+		rh.Images = append(rh.Images, ImageHistory{
+			TargetID:   iTarget.ID().String(),
+			Ref:        result.Image.String(),
+			SpecDigest: ImageSpecDigest(iTarget),
+			BuildTime:  status.LastSuccessfulBuildTime,
+		})
+	}
+
+	if mt.Manifest.IsK8s() {
+		pods := ms.K8sRuntimeState().PodList()
+		sort.Slice(pods, func(i, j int) bool { return pods[i].PodID < pods[j].PodID })
+		for _, pod := range pods {
+			rh.Pods = append(rh.Pods, PodHistory{
+				PodID:     pod.PodID,
+				Namespace: pod.Namespace,
+				StartedAt: pod.StartedAt,
+			})
+		}
+	}
+	return rh
+}
+
+func newBuildRecordHistory(b model.BuildRecord) BuildRecordHistory {
+	result := BuildRecordHistory{
+		Edits:      b.Edits,
+		Warnings:   b.Warnings,
+		StartTime:  b.StartTime,
+		FinishTime: b.FinishTime,
+		Reason:     b.Reason,
+		Log:        b.Log.Tail(HistoryLogLines).String(),
+	}
+	if b.Error != nil {
+		result.Error = b.Error.Error()
+	}
+	return result
+}
+
+func (b BuildRecordHistory) BuildRecord() model.BuildRecord {
+	result := model.BuildRecord{
+		Edits:      b.Edits,
+		Warnings:   b.Warnings,
+		StartTime:  b.StartTime,
+		FinishTime: b.FinishTime,
+		Reason:     b.Reason,
+		Log:        model.NewLog(b.Log),
+	}
+	if b.Error != "" {
+		result.Error = errors.New(b.Error)
+	}
+	return result
+}
+
+// Restore what we knew about a resource at the end of the previous session.
+//
+// Builds and logs are only for display. Images are restored as build results,
+// so that we don't have to rebuild them if nothing changed.
+func (h ResourceHistory) Restore(ms *ManifestState, m model.Manifest) {
+	ms.PreviousBuildHistory = nil
+	for _, b := range h.BuildHistory {
+		ms.PreviousBuildHistory = append(ms.PreviousBuildHistory, b.BuildRecord())
+	}
+	ms.PreviousDeployTime = h.LastDeployTime
+
+	if ms.CombinedLog.Empty() {
+		ms.CombinedLog = model.NewLog(h.Log)
+	}
+
+	if m.IsK8s() && h.DeployID != 0 {
+		pods := make([]Pod, 0, len(h.Pods))
+		for _, p := range h.Pods {
+			pods = append(pods, Pod{
+				PodID:     p.PodID,
+				Namespace: p.Namespace,
+				StartedAt: p.StartedAt,
+			})
+		}
+		ms.DeployID = h.DeployID
+		ms.RuntimeState = NewK8sRuntimeState(h.DeployID, pods...)
+	}
+
+	images := make(map[string]ImageHistory, len(h.Images))
+	for _, img := range h.Images {
+		images[img.TargetID] = img
+	}
+
+	for _, iTarget := range m.ImageTargets {
+		id := iTarget.ID()
+		img, ok := images[id.String()]
+		if !ok || img.SpecDigest != ImageSpecDigest(iTarget) {
+			continue
+		}
+
+		ref, err := container.ParseNamedTagged(img.Ref)
+		if err != nil {
+			continue
+		}
+
+		status := ms.MutableBuildStatus(id)
+		status.LastSuccessfulResult = NewImageBuildResult(id, ref)
+		status.LastSuccessfulBuildTime = img.BuildTime
+		status.RestoredFromHistory = true
+	}
+}
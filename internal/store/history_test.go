@@ -0,0 +1,110 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestHistoryRoundTrip(t *testing.T) {
+	m := historyManifest("fe", "Dockerfile")
+	iTarget := m.ImageTargets[0]
+	state := newState([]model.Manifest{m})
+	ms := state.ManifestTargets[m.Name].State
+
+	start := time.Now().Add(-time.Minute)
+	ref := container.MustParseNamedTagged("gcr.io/windmill/fe:tilt-12345")
+	ms.BuildHistory = []model.BuildRecord{
+		{StartTime: start, FinishTime: start.Add(time.Second), Error: fmt.Errorf("oh no")},
+	}
+	ms.DeployID = model.DeployID(1234)
+	ms.CombinedLog = model.NewLog("hello\n")
+	ms.RuntimeState = NewK8sRuntimeState(ms.DeployID, Pod{PodID: "pod-a", Namespace: "default"})
+	status := ms.MutableBuildStatus(iTarget.ID())
+	status.LastSuccessfulResult = NewImageBuildResult(iTarget.ID(), ref)
+	status.LastSuccessfulBuildTime = start
+
+	h := roundTripHistory(t, NewHistory(*state))
+
+	restored := newState([]model.Manifest{m}).ManifestTargets[m.Name].State
+	h.Resources[m.Name].Restore(restored, m)
+
+	if assert.Equal(t, 1, len(restored.PreviousBuildHistory)) {
+		assert.Equal(t, "oh no", restored.PreviousBuildHistory[0].Error.Error())
+	}
+	assert.Equal(t, 0, len(restored.BuildHistory))
+	assert.Equal(t, "hello\n", restored.CombinedLog.String())
+	assert.Equal(t, model.DeployID(1234), restored.DeployID)
+	assert.Equal(t, []k8s.PodID{"pod-a"}, podIDs(restored))
+
+	rStatus := restored.BuildStatus(iTarget.ID())
+	assert.True(t, rStatus.RestoredFromHistory)
+	assert.Equal(t, ref.String(), rStatus.LastSuccessfulResult.Image.String())
+	assert.True(t, start.Equal(rStatus.LastSuccessfulBuildTime))
+}
+
+func TestHistoryImageSpecChanged(t *testing.T) {
+	m := historyManifest("fe", "Dockerfile")
+	iTarget := m.ImageTargets[0]
+	state := newState([]model.Manifest{m})
+	ms := state.ManifestTargets[m.Name].State
+	ref := container.MustParseNamedTagged("gcr.io/windmill/fe:tilt-12345")
+	ms.MutableBuildStatus(iTarget.ID()).LastSuccessfulResult = NewImageBuildResult(iTarget.ID(), ref)
+
+	h := roundTripHistory(t, NewHistory(*state))
+
+	// The Tiltfile now builds the image differently, so we can't reuse it.
+	m2 := historyManifest("fe", "Dockerfile.prod")
+	restored := newState([]model.Manifest{m2}).ManifestTargets[m2.Name].State
+	h.Resources[m2.Name].Restore(restored, m2)
+
+	rStatus := restored.BuildStatus(iTarget.ID())
+	assert.False(t, rStatus.RestoredFromHistory)
+	assert.True(t, rStatus.LastSuccessfulResult.IsEmpty())
+}
+
+func TestHistoryKeepsUnloadedResources(t *testing.T) {
+	state := newState(nil)
+	state.History = History{
+		Resources: map[model.ManifestName]ResourceHistory{
+			"be": {DeployID: model.DeployID(1)},
+		},
+	}
+
+	h := NewHistory(*state)
+	assert.Equal(t, model.DeployID(1), h.Resources["be"].DeployID)
+}
+
+func historyManifest(name model.ManifestName, dockerfile string) model.Manifest {
+	iTarget := model.NewImageTarget(container.MustParseSelector(fmt.Sprintf("gcr.io/windmill/%s", name))).
+		WithBuildDetails(model.DockerBuild{Dockerfile: dockerfile, BuildPath: "/src"})
+	return model.Manifest{Name: name}.
+		WithImageTarget(iTarget).
+		WithDeployTarget(model.K8sTarget{YAML: testyaml.SanchoYAML})
+}
+
+func roundTripHistory(t *testing.T, h History) History {
+	bs, err := json.Marshal(h)
+	require.NoError(t, err)
+
+	var result History
+	require.NoError(t, json.Unmarshal(bs, &result))
+	return result
+}
+
+func podIDs(ms *ManifestState) []k8s.PodID {
+	var result []k8s.PodID
+	for _, pod := range ms.K8sRuntimeState().PodList() {
+		result = append(result, pod.PodID)
+	}
+	return result
+}
@@ -1,6 +1,9 @@
 package store
 
-import "github.com/windmilleng/tilt/pkg/model"
+import (
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/pkg/model"
+)
 
 type ManifestTarget struct {
 	Manifest model.Manifest
@@ -22,4 +25,34 @@ func (t ManifestTarget) Status() model.TargetStatus {
 	return t.State
 }
 
+// Whether this manifest has been deployed and is up and healthy,
+// i.e., whether resources that depend on it can start.
+func (t ManifestTarget) IsReadyForDependents() bool {
+	if t.State.LastSuccessfulDeployTime.IsZero() {
+		return false
+	}
+
+	m := t.Manifest
+	switch {
+	case m.IsK8s():
+		if m.K8sTarget().HasJobs {
+			job := t.State.K8sRuntimeState().CurrentJob()
+			return job.Status == JobStatusComplete
+		}
+		if !m.K8sTarget().HasPodTemplates {
+			return true
+		}
+		pod := t.State.MostRecentPod()
+		return pod.IsReady()
+	case m.IsDC():
+		return t.State.DCRuntimeState().Status == dockercompose.StatusUp
+	case m.IsLocal():
+		if m.LocalTarget().ServeCmd.Empty() {
+			return true
+		}
+		return t.State.LocalRuntimeState().Status == LocalServeStatusRunning
+	}
+	return true
+}
+
 var _ model.Target = &ManifestTarget{}
@@ -22,6 +22,9 @@ type K8sRuntimeState struct {
 	Pods           map[k8s.PodID]*Pod
 	LBs            map[k8s.ServiceName]*url.URL
 	DeployedUIDSet UIDSet
+
+	// Jobs from the most recent deploy, keyed by name.
+	Jobs map[string]*Job
 }
 
 func (K8sRuntimeState) RuntimeState() {}
@@ -36,6 +39,7 @@ func NewK8sRuntimeState(deployID model.DeployID, pods ...Pod) K8sRuntimeState {
 		PodDeployID: deployID,
 		Pods:        podMap,
 		LBs:         make(map[k8s.ServiceName]*url.URL),
+		Jobs:        make(map[string]*Job),
 	}
 }
 
@@ -74,6 +78,94 @@ func (s K8sRuntimeState) MostRecentPod() Pod {
 	return bestPod
 }
 
+// The Job to show in the UI. If a manifest has several Jobs, a failed Job takes
+// precedence over an active one, and an active Job over a complete one.
+// Returns an empty Job if there aren't any.
+func (s K8sRuntimeState) CurrentJob() Job {
+	best := Job{}
+	for _, job := range s.Jobs {
+		if best.Name == "" || jobStatusPriority[job.Status] > jobStatusPriority[best.Status] ||
+			(job.Status == best.Status && job.Name < best.Name) {
+			best = *job
+		}
+	}
+	return best
+}
+
+// The exit code of the most recent pod, once the current Job has finished.
+// Returns nil if there's no finished Job, or if its pod is gone.
+func (s K8sRuntimeState) JobExitCode() *int {
+	if !s.CurrentJob().Finished() {
+		return nil
+	}
+	code, ok := s.MostRecentPod().ExitCode()
+	if !ok {
+		return nil
+	}
+	return &code
+}
+
+type JobStatus string
+
+const (
+	JobStatusActive   JobStatus = "Active"
+	JobStatusComplete JobStatus = "Complete"
+	JobStatusFailed   JobStatus = "Failed"
+)
+
+var jobStatusPriority = map[JobStatus]int{
+	JobStatusComplete: 1,
+	JobStatusActive:   2,
+	JobStatusFailed:   3,
+}
+
+// The state of a Kubernetes Job, from its status conditions.
+type Job struct {
+	Name      string
+	Namespace k8s.Namespace
+	DeployID  model.DeployID
+	Status    JobStatus
+
+	// Why the Job failed, if it failed.
+	Message string
+
+	StartTime      time.Time
+	CompletionTime time.Time
+}
+
+func (j Job) Empty() bool {
+	return j.Name == ""
+}
+
+func (j Job) Finished() bool {
+	return j.Status == JobStatusComplete || j.Status == JobStatusFailed
+}
+
+type LocalServeStatus string
+
+const (
+	LocalServeStatusUnknown LocalServeStatus = ""
+	LocalServeStatusRunning LocalServeStatus = "Running"
+	LocalServeStatusCrashed LocalServeStatus = "Crashed"
+	LocalServeStatusExited  LocalServeStatus = "Exited"
+)
+
+// The state of the serve_cmd process of a local resource.
+type LocalRuntimeState struct {
+	Status    LocalServeStatus
+	PID       int
+	StartTime time.Time
+
+	// The log for the currently running process, if any
+	CurrentLog model.Log `testdiff:"ignore"`
+}
+
+func (LocalRuntimeState) RuntimeState() {}
+
+func (s LocalRuntimeState) Log() model.Log {
+	return s.CurrentLog
+}
+
 type Pod struct {
 	PodID     k8s.PodID
 	Namespace k8s.Namespace
@@ -81,6 +173,10 @@ type Pod struct {
 	Status    string
 	Phase     v1.PodPhase
 
+	// Whether the pod's Ready condition is true, i.e., whether all its
+	// containers pass their readiness probes.
+	Ready bool
+
 	// Error messages from the pod state if it's in an error state.
 	StatusMessages []string
 
@@ -103,6 +199,8 @@ type Pod struct {
 	OldRestarts int // # times the pod restarted when it was running old code
 }
 
+const PodStatusPendingReadiness = "PendingReadiness"
+
 type Container struct {
 	Name     container.Name
 	ID       container.ID
@@ -110,6 +208,10 @@ type Container struct {
 	Ready    bool
 	ImageRef reference.Named
 	Restarts int
+
+	// Set once the container has exited.
+	Terminated bool
+	ExitCode   int
 }
 
 func (c Container) Empty() bool {
@@ -134,6 +236,40 @@ func (p Pod) Log() model.Log {
 	return p.CurrentLog
 }
 
+// Whether the pod is running and passing its readiness probes.
+func (p Pod) IsReady() bool {
+	return p.Phase == v1.PodRunning && p.Ready && len(p.Containers) > 0 && p.AllContainersReady()
+}
+
+// The status to show in the UI. A pod that's running but still failing its
+// readiness probes gets its own status, so that it doesn't look healthy.
+func (p Pod) DisplayStatus() string {
+	if p.Phase == v1.PodRunning && p.Status == string(v1.PodRunning) && !p.IsReady() {
+		return PodStatusPendingReadiness
+	}
+	return p.Status
+}
+
+// The exit code of the pod, once all its containers have exited.
+// If any container failed, returns the first non-zero exit code.
+func (p Pod) ExitCode() (int, bool) {
+	if len(p.Containers) == 0 {
+		return 0, false
+	}
+
+	for _, c := range p.Containers {
+		if !c.Terminated {
+			return 0, false
+		}
+	}
+	for _, c := range p.Containers {
+		if c.ExitCode != 0 {
+			return c.ExitCode, true
+		}
+	}
+	return 0, true
+}
+
 func (p Pod) AllContainerPorts() []int32 {
 	result := make([]int32, 0)
 	for _, c := range p.Containers {
@@ -181,7 +181,9 @@ func (s *Store) maybeFinished() (bool, error) {
 		return false, nil
 	}
 
+	// In CI mode, the CIController decides when we're finished.
 	finished := !state.WatchFiles &&
+		state.EngineMode != EngineModeCI &&
 		state.CompletedBuildCount == state.InitialBuildsQueued
 	return finished, nil
 }
@@ -18,7 +18,7 @@ type TestingStore struct {
 }
 
 func NewTestingStore() *TestingStore {
-	return &TestingStore{}
+	return &TestingStore{state: NewState()}
 }
 
 func (s *TestingStore) SetState(state EngineState) {
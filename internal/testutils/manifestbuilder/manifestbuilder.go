@@ -22,6 +22,8 @@ type ManifestBuilder struct {
 
 	k8sYAML       string
 	dcConfigPaths []string
+	localCmd      string
+	localDeps     []string
 
 	iTargets []model.ImageTarget
 }
@@ -43,6 +45,12 @@ func (b ManifestBuilder) WithDockerCompose() ManifestBuilder {
 	return b
 }
 
+func (b ManifestBuilder) WithLocalResource(cmd string, deps []string) ManifestBuilder {
+	b.localCmd = cmd
+	b.localDeps = deps
+	return b
+}
+
 func (b ManifestBuilder) WithImageTarget(iTarg model.ImageTarget) ManifestBuilder {
 	b.iTargets = append(b.iTargets, iTarg)
 	return b
@@ -94,6 +102,11 @@ func (b ManifestBuilder) Build() model.Manifest {
 			b.iTargets...)
 	}
 
+	if b.localCmd != "" {
+		lt := model.NewLocalTarget(model.TargetName(b.name), model.ToShellCmd(b.localCmd), b.f.Path(), b.localDeps)
+		return model.Manifest{Name: b.name}.WithDeployTarget(lt)
+	}
+
 	b.f.T().Fatalf("No deploy target specified: %s", b.name)
 	return model.Manifest{}
 }
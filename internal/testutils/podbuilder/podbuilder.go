@@ -56,6 +56,7 @@ type PodBuilder struct {
 
 	podID        string
 	phase        string
+	notReady     bool
 	creationTime time.Time
 	deployID     model.DeployID
 
@@ -88,6 +89,12 @@ func (b PodBuilder) WithPhase(phase string) PodBuilder {
 	return b
 }
 
+// Simulates a pod whose containers are failing their readiness probes.
+func (b PodBuilder) WithNotReady() PodBuilder {
+	b.notReady = true
+	return b
+}
+
 func (b PodBuilder) WithImage(image string) PodBuilder {
 	return b.WithImageAtIndex(image, 0)
 }
@@ -186,13 +193,25 @@ func (b PodBuilder) buildContainerStatuses(spec v1.PodSpec) []v1.ContainerStatus
 		result[i] = v1.ContainerStatus{
 			Name:        cSpec.Name,
 			Image:       b.buildImage(cSpec.Image, i),
-			Ready:       true,
+			Ready:       !b.notReady,
 			ContainerID: b.buildContainerID(i),
 		}
 	}
 	return result
 }
 
+func (b PodBuilder) buildConditions() []v1.PodCondition {
+	if b.buildPhase() != v1.PodRunning {
+		return nil
+	}
+
+	status := v1.ConditionTrue
+	if b.notReady {
+		status = v1.ConditionFalse
+	}
+	return []v1.PodCondition{{Type: v1.PodReady, Status: status}}
+}
+
 func (b PodBuilder) validateImageRefs(numContainers int) {
 	for index, img := range b.imageRefs {
 		if index >= numContainers {
@@ -244,6 +263,7 @@ func (b PodBuilder) Build() *v1.Pod {
 		Spec: spec,
 		Status: v1.PodStatus{
 			Phase:             b.buildPhase(),
+			Conditions:        b.buildConditions(),
 			ContainerStatuses: b.buildContainerStatuses(spec),
 		},
 	}
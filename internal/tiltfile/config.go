@@ -1 +1,342 @@
 package tiltfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"go.starlark.net/starlark"
+)
+
+// The file next to the Tiltfile where users can store their own config settings.
+const UserConfigFileName = "tilt_config.json"
+
+type configSettingType int
+
+const (
+	configTypeString configSettingType = iota
+	configTypeStringList
+	configTypeBool
+)
+
+func (t configSettingType) String() string {
+	switch t {
+	case configTypeString:
+		return "string"
+	case configTypeStringList:
+		return "string_list"
+	case configTypeBool:
+		return "bool"
+	default:
+		return fmt.Sprintf("unknown config setting type %d", t)
+	}
+}
+
+// A setting declared in the Tiltfile with config.define_*
+type configSetting struct {
+	name  string
+	typ   configSettingType
+	usage string
+
+	// If true, positional args on the command line are stored in this setting.
+	args bool
+}
+
+type configState struct {
+	// Everything after `--` on the command line.
+	args []string
+
+	// Path to tilt_config.json.
+	userConfigPath string
+
+	settings []configSetting
+	parsed   bool
+}
+
+func newConfigState(tiltfilePath string, args []string) configState {
+	return configState{
+		args:           args,
+		userConfigPath: filepath.Join(filepath.Dir(tiltfilePath), UserConfigFileName),
+	}
+}
+
+func (c configState) setting(name string) (configSetting, bool) {
+	for _, setting := range c.settings {
+		if setting.name == name {
+			return setting, true
+		}
+	}
+	return configSetting{}, false
+}
+
+func (c configState) argsSetting() (configSetting, bool) {
+	for _, setting := range c.settings {
+		if setting.args {
+			return setting, true
+		}
+	}
+	return configSetting{}, false
+}
+
+// A Starlark value that groups related builtins under a common prefix, e.g., `config.parse()`.
+type builtinModule struct {
+	name    string
+	members starlark.StringDict
+}
+
+var _ starlark.HasAttrs = builtinModule{}
+
+func (m builtinModule) String() string {
+	return fmt.Sprintf("<module %q>", m.name)
+}
+
+func (m builtinModule) Type() string {
+	return "module"
+}
+
+func (m builtinModule) Freeze() {
+	m.members.Freeze()
+}
+
+func (m builtinModule) Truth() starlark.Bool {
+	return starlark.True
+}
+
+func (m builtinModule) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: %s", m.Type())
+}
+
+func (m builtinModule) Attr(name string) (starlark.Value, error) {
+	return m.members[name], nil
+}
+
+func (m builtinModule) AttrNames() []string {
+	return m.members.Keys()
+}
+
+func (s *tiltfileState) configDefineString(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return s.configDefine(fn, args, kwargs, configTypeString)
+}
+
+func (s *tiltfileState) configDefineStringList(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return s.configDefine(fn, args, kwargs, configTypeStringList)
+}
+
+func (s *tiltfileState) configDefineBool(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name, usage string
+	err := s.unpackArgs(fn.Name(), args, kwargs,
+		"name", &name,
+		"usage?", &usage,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return starlark.None, s.addConfigSetting(fn, configSetting{name: name, typ: configTypeBool, usage: usage})
+}
+
+func (s *tiltfileState) configDefine(fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple, typ configSettingType) (starlark.Value, error) {
+	var name, usage string
+	var isArgs bool
+	err := s.unpackArgs(fn.Name(), args, kwargs,
+		"name", &name,
+		"args?", &isArgs,
+		"usage?", &usage,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return starlark.None, s.addConfigSetting(fn, configSetting{name: name, typ: typ, usage: usage, args: isArgs})
+}
+
+func (s *tiltfileState) addConfigSetting(fn *starlark.Builtin, setting configSetting) error {
+	if setting.name == "" {
+		return fmt.Errorf("%s: `name` must not be empty", fn.Name())
+	}
+
+	if _, ok := s.config.setting(setting.name); ok {
+		return fmt.Errorf("%s: config setting %q already defined", fn.Name(), setting.name)
+	}
+
+	if setting.args {
+		if existing, ok := s.config.argsSetting(); ok {
+			return fmt.Errorf("%s: only one config setting may have args=True (already defined on %q)", fn.Name(), existing.name)
+		}
+	}
+
+	s.config.settings = append(s.config.settings, setting)
+	return nil
+}
+
+// Reads the values of all the defined settings from tilt_config.json, then
+// overrides them with any args passed on the command line.
+//
+// Returns a dict with an entry for each setting that has a value.
+func (s *tiltfileState) configParse(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	err := s.unpackArgs(fn.Name(), args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+
+	s.config.parsed = true
+
+	values, err := s.readUserConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	argValues, err := s.parseConfigArgs()
+	if err != nil {
+		return nil, fmt.Errorf("%s: error parsing args %q: %v", fn.Name(), s.config.args, err)
+	}
+
+	for k, v := range argValues {
+		values[k] = v
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := starlark.NewDict(len(values))
+	for _, name := range names {
+		err := result.SetKey(starlark.String(name), values[name])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (s *tiltfileState) readUserConfigFile() (map[string]starlark.Value, error) {
+	values := make(map[string]starlark.Value)
+
+	// Watch the file even if it doesn't exist yet, so that we pick it up when it's created.
+	path := s.config.userConfigPath
+	s.recordConfigFile(path)
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return values, nil
+		}
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	err = json.Unmarshal(contents, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	for name, v := range raw {
+		setting, ok := s.config.setting(name)
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown config setting %q", path, name)
+		}
+
+		val, err := setting.valueFromJSON(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		values[name] = val
+	}
+
+	return values, nil
+}
+
+func (setting configSetting) valueFromJSON(v interface{}) (starlark.Value, error) {
+	switch setting.typ {
+	case configTypeString:
+		if s, ok := v.(string); ok {
+			return starlark.String(s), nil
+		}
+	case configTypeBool:
+		if b, ok := v.(bool); ok {
+			return starlark.Bool(b), nil
+		}
+	case configTypeStringList:
+		if list, ok := v.([]interface{}); ok {
+			var strs []string
+			for _, elem := range list {
+				s, ok := elem.(string)
+				if !ok {
+					return nil, fmt.Errorf("setting %q: expected a list of strings, but found element %v", setting.name, elem)
+				}
+				strs = append(strs, s)
+			}
+			return newStringList(strs), nil
+		}
+	}
+	return nil, fmt.Errorf("setting %q: expected value of type %s, got %T", setting.name, setting.typ, v)
+}
+
+func (s *tiltfileState) parseConfigArgs() (map[string]starlark.Value, error) {
+	fs := pflag.NewFlagSet("config", pflag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	strs := make(map[string]*string)
+	lists := make(map[string]*[]string)
+	bools := make(map[string]*bool)
+	for _, setting := range s.config.settings {
+		switch setting.typ {
+		case configTypeString:
+			strs[setting.name] = fs.String(setting.name, "", setting.usage)
+		case configTypeStringList:
+			lists[setting.name] = fs.StringArray(setting.name, nil, setting.usage)
+		case configTypeBool:
+			bools[setting.name] = fs.Bool(setting.name, false, setting.usage)
+		}
+	}
+
+	err := fs.Parse(s.config.args)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]starlark.Value)
+	fs.Visit(func(f *pflag.Flag) {
+		if v, ok := strs[f.Name]; ok {
+			values[f.Name] = starlark.String(*v)
+		} else if v, ok := lists[f.Name]; ok {
+			values[f.Name] = newStringList(*v)
+		} else if v, ok := bools[f.Name]; ok {
+			values[f.Name] = starlark.Bool(*v)
+		}
+	})
+
+	positional := fs.Args()
+	if len(positional) > 0 {
+		setting, ok := s.config.argsSetting()
+		if !ok {
+			return nil, fmt.Errorf("positional args were specified, but no config setting was defined with args=True")
+		}
+
+		switch setting.typ {
+		case configTypeString:
+			if len(positional) > 1 {
+				return nil, fmt.Errorf("setting %q takes a single positional arg, got %d", setting.name, len(positional))
+			}
+			values[setting.name] = starlark.String(positional[0])
+		case configTypeStringList:
+			values[setting.name] = newStringList(positional)
+		}
+	}
+
+	return values, nil
+}
+
+func newStringList(strs []string) *starlark.List {
+	var elems []starlark.Value
+	for _, s := range strs {
+		elems = append(elems, starlark.String(s))
+	}
+	return starlark.NewList(elems)
+}
@@ -0,0 +1,149 @@
+package tiltfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const configTiltfile = `
+config.define_string_list('to-run', args=True)
+config.define_string('env', usage='which environment to deploy to')
+config.define_bool('debug')
+cfg = config.parse()
+
+local_resource('env', 'echo %s' % cfg.get('env', 'dev'))
+local_resource('debug', 'echo %s' % cfg.get('debug', False))
+for name in cfg.get('to-run', []):
+  local_resource(name, 'echo hi')
+`
+
+func TestConfigDefaults(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", configTiltfile)
+
+	f.load()
+	f.assertLocalCmd("env", "echo dev")
+	f.assertLocalCmd("debug", "echo False")
+	f.assertNoMoreManifests()
+	f.assertConfigFiles("Tiltfile", ".tiltignore", UserConfigFileName)
+}
+
+func TestConfigArgs(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", configTiltfile)
+	f.configArgs = []string{"--env", "staging", "--debug", "frontend", "backend"}
+
+	f.load()
+	f.assertLocalCmd("env", "echo staging")
+	f.assertLocalCmd("debug", "echo True")
+	f.assertNextManifest("frontend")
+	f.assertNextManifest("backend")
+	f.assertNoMoreManifests()
+}
+
+func TestConfigUserConfigFile(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", configTiltfile)
+	f.file(UserConfigFileName, `{"env": "prod", "debug": true, "to-run": ["frontend"]}`)
+
+	f.load()
+	f.assertLocalCmd("env", "echo prod")
+	f.assertLocalCmd("debug", "echo True")
+	f.assertNextManifest("frontend")
+	f.assertNoMoreManifests()
+}
+
+func TestConfigArgsOverrideUserConfigFile(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", configTiltfile)
+	f.file(UserConfigFileName, `{"env": "prod", "to-run": ["frontend"]}`)
+	f.configArgs = []string{"--env=staging", "backend"}
+
+	f.load()
+	f.assertLocalCmd("env", "echo staging")
+	f.assertLocalCmd("debug", "echo False")
+	f.assertNextManifest("backend")
+	f.assertNoMoreManifests()
+}
+
+func TestConfigUserConfigFileUnknownSetting(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", configTiltfile)
+	f.file(UserConfigFileName, `{"region": "us-east1"}`)
+
+	f.loadErrString(UserConfigFileName, `unknown config setting "region"`)
+}
+
+func TestConfigUserConfigFileWrongType(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", configTiltfile)
+	f.file(UserConfigFileName, `{"to-run": "frontend"}`)
+
+	f.loadErrString(`setting "to-run": expected value of type string_list`)
+}
+
+func TestConfigUnknownFlag(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", configTiltfile)
+	f.configArgs = []string{"--region=us-east1"}
+
+	f.loadErrString("config.parse: error parsing args", "unknown flag: --region")
+}
+
+func TestConfigPositionalArgsWithoutArgsSetting(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+config.define_string('env')
+config.parse()
+`)
+	f.configArgs = []string{"frontend"}
+
+	f.loadErrString("no config setting was defined with args=True")
+}
+
+func TestConfigDuplicateSetting(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+config.define_string('env')
+config.define_bool('env')
+`)
+
+	f.loadErrString(`config.define_bool: config setting "env" already defined`)
+}
+
+func TestConfigArgsWithoutParse(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('foo', 'echo hi')
+`)
+	f.configArgs = []string{"--env=staging"}
+
+	f.loadAllowWarnings()
+	f.assertWarnings(`Tiltfile args ["--env=staging"] were passed, but the Tiltfile never called config.parse()`)
+}
+
+func (f *fixture) assertLocalCmd(name string, cmd string) {
+	m := f.assertNextManifest(name)
+	assert.Equal(f.t, cmd, m.LocalTarget().Cmd.String())
+}
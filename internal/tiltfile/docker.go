@@ -10,6 +10,7 @@ import (
 	"go.starlark.net/starlark"
 
 	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/docker"
 	"github.com/windmilleng/tilt/internal/dockerfile"
 	"github.com/windmilleng/tilt/internal/ospath"
 	"github.com/windmilleng/tilt/pkg/model"
@@ -42,6 +43,11 @@ type dockerImage struct {
 	dbDockerfile     dockerfile.Dockerfile
 	dbBuildPath      string
 	dbBuildArgs      model.DockerBuildArgs
+	dbTarget         string
+	dbSSHSpecs       []string
+	dbSecretSpecs    []string
+	dbCacheFrom      []reference.Named
+	dbCacheTo        reference.Named
 	customCommand    string
 	customDeps       []string
 	customTag        string
@@ -53,6 +59,15 @@ type dockerImage struct {
 	disablePush   bool
 
 	liveUpdate model.LiveUpdate
+
+	// The cache refs of a docker_build, moved into the default registry.
+	deploymentCacheFrom []string
+	deploymentCacheTo   string
+
+	// pack_build properties
+	packBuildPath string
+	packBuilder   string
+	packEnv       map[string]string
 }
 
 func (d *dockerImage) ID() model.TargetID {
@@ -66,6 +81,7 @@ const (
 	DockerBuild
 	FastBuild
 	CustomBuild
+	PackBuild
 )
 
 func (d *dockerImage) Type() dockerImageBuildType {
@@ -81,12 +97,17 @@ func (d *dockerImage) Type() dockerImageBuildType {
 		return CustomBuild
 	}
 
+	if d.packBuildPath != "" {
+		return PackBuild
+	}
+
 	return UnknownBuild
 }
 
 func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var dockerRef, entrypoint string
-	var contextVal, dockerfilePathVal, buildArgs, dockerfileContentsVal, cacheVal, liveUpdateVal, ignoreVal, onlyVal starlark.Value
+	var dockerRef, entrypoint, target string
+	var contextVal, dockerfilePathVal, buildArgs, dockerfileContentsVal, cacheVal, liveUpdateVal, ignoreVal, onlyVal, sshVal, secretVal, cacheFromVal starlark.Value
+	var cacheTo string
 	var matchInEnvVars bool
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"ref", &dockerRef,
@@ -100,6 +121,11 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		"ignore?", &ignoreVal,
 		"only?", &onlyVal,
 		"entrypoint?", &entrypoint,
+		"target?", &target,
+		"ssh?", &sshVal,
+		"secret?", &secretVal,
+		"cache_from?", &cacheFromVal,
+		"cache_to?", &cacheTo,
 	); err != nil {
 		return nil, err
 	}
@@ -188,6 +214,49 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		entrypointCmd = model.ToShellCmd(entrypoint)
 	}
 
+	sshSpecs, err := parseValuesToStrings(sshVal, "ssh")
+	if err != nil {
+		return nil, err
+	}
+	for i, spec := range sshSpecs {
+		sshSpecs[i], err = s.absSSHSpec(thread, spec)
+		if err != nil {
+			return nil, fmt.Errorf("Argument ssh: %v", err)
+		}
+	}
+
+	secretSpecs, err := parseValuesToStrings(secretVal, "secret")
+	if err != nil {
+		return nil, err
+	}
+	for i, spec := range secretSpecs {
+		secretSpecs[i], err = s.absSecretSpec(thread, spec)
+		if err != nil {
+			return nil, fmt.Errorf("Argument secret: %v", err)
+		}
+	}
+
+	cacheFromStrs, err := parseValuesToStrings(cacheFromVal, "cache_from")
+	if err != nil {
+		return nil, err
+	}
+	var cacheFrom []reference.Named
+	for _, str := range cacheFromStrs {
+		ref, err := container.ParseNamed(str)
+		if err != nil {
+			return nil, fmt.Errorf("Argument cache_from: can't parse %q: %v", str, err)
+		}
+		cacheFrom = append(cacheFrom, ref)
+	}
+
+	var cacheToRef reference.Named
+	if cacheTo != "" {
+		cacheToRef, err = container.ParseNamed(cacheTo)
+		if err != nil {
+			return nil, fmt.Errorf("Argument cache_to: can't parse %q: %v", cacheTo, err)
+		}
+	}
+
 	r := &dockerImage{
 		tiltfilePath:     s.currentTiltfilePath(thread),
 		dbDockerfilePath: dockerfilePath,
@@ -195,6 +264,11 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		dbBuildPath:      context,
 		configurationRef: container.NewRefSelector(ref),
 		dbBuildArgs:      sba,
+		dbTarget:         target,
+		dbSSHSpecs:       sshSpecs,
+		dbSecretSpecs:    secretSpecs,
+		dbCacheFrom:      cacheFrom,
+		dbCacheTo:        cacheToRef,
 		cachePaths:       cachePaths,
 		liveUpdate:       liveUpdate,
 		matchInEnvVars:   matchInEnvVars,
@@ -214,6 +288,40 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 	return fb, nil
 }
 
+// Resolves the key and socket paths in an ssh spec relative to the Tiltfile.
+func (s *tiltfileState) absSSHSpec(t *starlark.Thread, spec string) (string, error) {
+	cfg, err := docker.ParseSSHSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	if len(cfg.Paths) == 0 {
+		return spec, nil
+	}
+
+	paths := make([]string, len(cfg.Paths))
+	for i, p := range cfg.Paths {
+		paths[i] = s.absPath(t, p)
+	}
+	return fmt.Sprintf("%s=%s", cfg.ID, strings.Join(paths, ",")), nil
+}
+
+// Resolves the source path in a secret spec relative to the Tiltfile.
+//
+// Like `docker build`, we read a secret without a source from the path
+// named by its id.
+func (s *tiltfileState) absSecretSpec(t *starlark.Thread, spec string) (string, error) {
+	fs, err := docker.ParseSecretSpec(spec)
+	if err != nil {
+		return "", err
+	}
+
+	path := fs.FilePath
+	if path == "" {
+		path = fs.ID
+	}
+	return fmt.Sprintf("id=%s,src=%s", fs.ID, s.absPath(t, path)), nil
+}
+
 func (s *tiltfileState) parseOnly(val starlark.Value) ([]string, error) {
 	paths, err := parseValuesToStrings(val, "only")
 	if err != nil {
@@ -624,6 +732,7 @@ func (s *tiltfileState) reposForImage(image *dockerImage) []model.LocalGitRepo {
 		image.baseDockerfilePath,
 		image.dbDockerfilePath,
 		image.dbBuildPath,
+		image.packBuildPath,
 		image.tiltfilePath)
 
 	return reposForPaths(paths)
@@ -721,6 +830,8 @@ func (s *tiltfileState) dockerignoresForImage(image *dockerImage) []model.Docker
 		paths = append(paths, image.dbBuildPath)
 	case CustomBuild:
 		paths = append(paths, image.customDeps...)
+	case PackBuild:
+		paths = append(paths, image.packBuildPath)
 	}
 	return s.dockerignoresFromPathsAndContextFilters(paths, image.ignores, image.onlys)
 }
@@ -73,11 +73,19 @@ func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin
 	var name string
 	var imageVal starlark.Value
 	var triggerMode triggerMode
+	var resourceDepsVal starlark.Value
+	var restartPolicyStr string
+	maxRestarts := -1
+	var labelsVal starlark.Value
 
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"name", &name,
 		"image", &imageVal, // in future this will be optional
 		"trigger_mode?", &triggerMode,
+		"resource_deps?", &resourceDepsVal,
+		"restart_policy?", &restartPolicyStr,
+		"max_restarts?", &maxRestarts,
+		"labels?", &labelsVal,
 	); err != nil {
 		return nil, err
 	}
@@ -105,6 +113,24 @@ func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin
 
 	svc.TriggerMode = triggerMode
 
+	resourceDeps, err := parseValuesToStrings(resourceDepsVal, "resource_deps")
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q", fn.Name(), name)
+	}
+	svc.ResourceDeps = resourceDeps
+
+	restartPolicy, err := restartPolicyFromArgs(restartPolicyStr, maxRestarts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q", fn.Name(), name)
+	}
+	svc.RestartPolicy = restartPolicy
+
+	labels, err := labelsFromStarlarkValue(labelsVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q", fn.Name(), name)
+	}
+	svc.Labels = labels
+
 	normalized, err := container.ParseNamed(imageRefAsStr)
 	if err != nil {
 		return nil, err
@@ -281,6 +307,13 @@ type dcService struct {
 	PublishedPorts []int
 
 	TriggerMode triggerMode
+
+	RestartPolicy model.RestartPolicy
+
+	// Names of resources that must be ready before this one is deployed.
+	ResourceDeps []string
+
+	Labels []string
 }
 
 func (c DcConfig) GetService(name string) (dcService, error) {
@@ -430,9 +463,12 @@ func (s *tiltfileState) dcServiceToManifest(service *dcService, dcSet dcResource
 		return model.Manifest{}, nil, err
 	}
 	m := model.Manifest{
-		Name:        model.ManifestName(service.Name),
-		TriggerMode: um,
-	}.WithDeployTarget(dcInfo)
+		Name:          model.ManifestName(service.Name),
+		TriggerMode:   um,
+		RestartPolicy: service.RestartPolicy,
+	}.WithDeployTarget(dcInfo).
+		WithResourceDependencies(model.ToManifestNames(service.ResourceDeps)).
+		WithLabels(service.Labels)
 
 	if service.DfPath == "" {
 		// DC service may not have Dockerfile -- e.g. may be just an image that we pull and run.
@@ -0,0 +1,222 @@
+package tiltfile
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/windmilleng/wmclient/pkg/dirs"
+	"go.starlark.net/starlark"
+)
+
+// Tiltfiles can load() shared extensions with `load('ext://name', 'symbol')`.
+//
+// The extension `name` lives at `name/Tiltfile` in the extension repo, which is
+// either a local directory or a git repo. Git repos are cloned into the Windmill
+// directory (~/.windmill by default) and only re-fetched once the clone is older
+// than extensionRepoMaxAge, so that we don't hit the network on every Tiltfile load.
+const extensionPrefix = "ext://"
+
+const DefaultExtensionRepo = "https://github.com/windmilleng/tilt-extensions"
+
+// Overrides the repo we load extensions from.
+// A Tiltfile can override it in turn with extension_repo().
+const extensionRepoEnv = "TILT_EXTENSIONS_REPO"
+
+// How long we use a clone of a git repo before we fetch it again.
+const extensionRepoMaxAge = 24 * time.Hour
+
+type extensionRepo struct {
+	// A local directory or a git URL.
+	location string
+
+	// Where we cache clones of git repos.
+	cacheDir string
+}
+
+func newExtensionRepo(dir *dirs.WindmillDir) extensionRepo {
+	location := os.Getenv(extensionRepoEnv)
+	if location == "" {
+		location = DefaultExtensionRepo
+	}
+
+	return extensionRepo{
+		location: location,
+		cacheDir: filepath.Join(dir.Root(), "extensions"),
+	}
+}
+
+func (r extensionRepo) isGit() bool {
+	return strings.Contains(r.location, "://") ||
+		strings.HasPrefix(r.location, "git@") ||
+		strings.HasSuffix(r.location, ".git")
+}
+
+// The directory we clone a git repo into, unique to the repo's URL.
+func (r extensionRepo) cloneDir() string {
+	hash := sha256.Sum256([]byte(r.location))
+	name := strings.TrimSuffix(filepath.Base(r.location), ".git")
+	return filepath.Join(r.cacheDir, fmt.Sprintf("%s-%x", name, hash[:6]))
+}
+
+func validateExtensionName(name string) error {
+	if name == "" {
+		return fmt.Errorf("missing extension name")
+	}
+	if filepath.IsAbs(name) {
+		return fmt.Errorf("extension name must not be an absolute path")
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid extension name %q", name)
+		}
+	}
+	return nil
+}
+
+// Returns the path to the Tiltfile of extension `name`, fetching the repo if needed.
+func (s *tiltfileState) resolveExtension(name string) (string, error) {
+	err := validateExtensionName(name)
+	if err != nil {
+		return "", err
+	}
+
+	repo := s.extensionRepo
+	root := repo.location
+	if repo.isGit() {
+		root, err = s.fetchExtensionRepo(repo)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		root, err = filepath.Abs(root)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	p := filepath.Join(root, filepath.FromSlash(name), FileName)
+	_, err = os.Stat(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("extension %q not found in %s (expected %s)", name, repo.location, p)
+		}
+		return "", err
+	}
+	return p, nil
+}
+
+func (s *tiltfileState) fetchExtensionRepo(repo extensionRepo) (string, error) {
+	dir := repo.cloneDir()
+	info, err := os.Stat(dir)
+	if err == nil {
+		if time.Since(info.ModTime()) > extensionRepoMaxAge {
+			s.updateExtensionRepo(repo, dir)
+		}
+		return dir, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	err = os.MkdirAll(repo.cacheDir, os.FileMode(0755))
+	if err != nil {
+		return "", err
+	}
+
+	// Clone into a temp dir first, so that an interrupted clone doesn't leave
+	// a broken cache behind.
+	tmpDir, err := ioutil.TempDir(repo.cacheDir, ".fetch-")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	s.logger.Infof("Fetching Tiltfile extensions from %s", repo.location)
+	cmd := exec.CommandContext(s.ctx, "git", "clone", "--depth", "1", repo.location, tmpDir)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error fetching extension repo %s: %v\n%s", repo.location, err, out)
+	}
+
+	err = os.Rename(tmpDir, dir)
+	if err != nil {
+		return "", err
+	}
+	return dir, markFetched(dir)
+}
+
+// Pulls the latest commit into a stale clone.
+//
+// If we can't (e.g., because we're offline), we keep using the clone we have,
+// and don't try again until it's stale again.
+func (s *tiltfileState) updateExtensionRepo(repo extensionRepo, dir string) {
+	err := markFetched(dir)
+	if err != nil {
+		s.warnings = append(s.warnings, fmt.Sprintf("Error updating extension repo %s: %v", repo.location, err))
+		return
+	}
+
+	s.logger.Infof("Updating Tiltfile extensions from %s", repo.location)
+	for _, args := range [][]string{
+		{"fetch", "--depth", "1", "origin"},
+		{"reset", "--hard", "FETCH_HEAD"},
+	} {
+		cmd := exec.CommandContext(s.ctx, "git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			s.warnings = append(s.warnings, fmt.Sprintf("Error updating extension repo %s, using the copy in %s: %v\n%s",
+				repo.location, dir, err, out))
+			return
+		}
+	}
+}
+
+// We track when we last fetched a clone with the modification time of its directory.
+func markFetched(dir string) error {
+	now := time.Now()
+	return os.Chtimes(dir, now, now)
+}
+
+func (s *tiltfileState) setExtensionRepo(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var location string
+	err := s.unpackArgs(fn.Name(), args, kwargs, "location", &location)
+	if err != nil {
+		return nil, err
+	}
+
+	if location == "" {
+		return nil, fmt.Errorf("%s: location cannot be empty", fn.Name())
+	}
+
+	repo := extensionRepo{location: location, cacheDir: s.extensionRepo.cacheDir}
+	if !repo.isGit() {
+		repo.location = s.absPath(thread, location)
+	}
+	s.extensionRepo = repo
+	return starlark.None, nil
+}
+
+func (s *tiltfileState) loadExtension(name string) (starlark.StringDict, error) {
+	p, err := s.resolveExtension(name)
+	if err != nil {
+		return nil, err
+	}
+
+	exports, err := s.exec(p)
+	if err != nil {
+		// starlark only reports the message of errors in loaded modules,
+		// so include the backtrace to point at the extension's file and line.
+		if err, ok := err.(*starlark.EvalError); ok {
+			return nil, errors.New(err.Backtrace())
+		}
+		return nil, err
+	}
+	return exports, nil
+}
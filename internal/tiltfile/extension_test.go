@@ -0,0 +1,193 @@
+package tiltfile
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const helpersExtension = `
+def go_service(name):
+  local_resource(name, 'go build ./%s' % name)
+`
+
+func TestLoadExtensionFromDir(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	defer f.setEnv(extensionRepoEnv, f.JoinPath("extensions"))()
+
+	f.file("extensions/our_helpers/Tiltfile", helpersExtension)
+	f.file("Tiltfile", `
+load('ext://our_helpers', 'go_service')
+go_service('api')
+`)
+
+	f.load()
+	f.assertLocalCmd("api", "go build ./api")
+	f.assertConfigFiles("Tiltfile", ".tiltignore",
+		"extensions/our_helpers/Tiltfile", "extensions/our_helpers/.tiltignore")
+}
+
+func TestLoadExtensionNotFound(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	defer f.setEnv(extensionRepoEnv, f.JoinPath("extensions"))()
+
+	f.file("extensions/our_helpers/Tiltfile", helpersExtension)
+	f.file("Tiltfile", `
+load('ext://their_helpers', 'go_service')
+`)
+
+	f.loadErrString("cannot load ext://their_helpers", `extension "their_helpers" not found`)
+}
+
+func TestLoadExtensionInvalidName(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	defer f.setEnv(extensionRepoEnv, f.JoinPath("extensions"))()
+
+	f.file("Tiltfile", `
+load('ext://../secrets', 'password')
+`)
+
+	f.loadErrString(`invalid extension name "../secrets"`)
+}
+
+func TestLoadExtensionErrorReportsExtensionPosition(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	defer f.setEnv(extensionRepoEnv, f.JoinPath("extensions"))()
+
+	f.file("extensions/broken/Tiltfile", `
+def go_service(name):
+  pass
+
+fail('oh no')
+`)
+	f.file("Tiltfile", `
+load('ext://broken', 'go_service')
+`)
+
+	f.loadErrString(f.JoinPath("extensions/broken/Tiltfile")+":5:5", "oh no")
+}
+
+func TestLoadExtensionFromGitRepo(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("repo/our_helpers/Tiltfile", helpersExtension)
+	f.gitCommitAll("repo")
+
+	defer f.setEnv(extensionRepoEnv, "file://"+f.JoinPath("repo"))()
+
+	f.file("Tiltfile", `
+load('ext://our_helpers', 'go_service')
+go_service('api')
+`)
+
+	f.load()
+	f.assertLocalCmd("api", "go build ./api")
+
+	matches, err := filepath.Glob(f.JoinPath(".windmill", "extensions", "repo-*", "our_helpers", "Tiltfile"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(matches))
+
+	// Subsequent loads should use the cached clone.
+	require.NoError(t, os.RemoveAll(f.JoinPath("repo")))
+	f.load()
+	f.assertLocalCmd("api", "go build ./api")
+}
+
+func TestLoadExtensionRefetchesStaleRepo(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("repo/our_helpers/Tiltfile", helpersExtension)
+	f.gitCommitAll("repo")
+
+	defer f.setEnv(extensionRepoEnv, "file://"+f.JoinPath("repo"))()
+
+	f.file("Tiltfile", `
+load('ext://our_helpers', 'go_service')
+go_service('api')
+`)
+
+	f.load()
+	f.assertLocalCmd("api", "go build ./api")
+
+	f.file("repo/our_helpers/Tiltfile", `
+def go_service(name):
+  local_resource(name, 'go build -v ./%s' % name)
+`)
+	f.gitCommitAll("repo")
+
+	// The clone is still fresh, so we don't fetch the new commit.
+	f.load()
+	f.assertLocalCmd("api", "go build ./api")
+
+	matches, err := filepath.Glob(f.JoinPath(".windmill", "extensions", "repo-*"))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(matches))
+	stale := time.Now().Add(-extensionRepoMaxAge - time.Hour)
+	require.NoError(t, os.Chtimes(matches[0], stale, stale))
+
+	f.load()
+	f.assertLocalCmd("api", "go build -v ./api")
+}
+
+func TestExtensionRepoInTiltfile(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	defer f.setEnv(extensionRepoEnv, f.JoinPath("other-extensions"))()
+
+	f.file("extensions/our_helpers/Tiltfile", helpersExtension)
+	f.file("Tiltfile", `
+extension_repo('extensions')
+load('ext://our_helpers', 'go_service')
+go_service('api')
+`)
+
+	f.load()
+	f.assertLocalCmd("api", "go build ./api")
+}
+
+func TestExtensionRepoEmpty(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+extension_repo('')
+`)
+
+	f.loadErrString("extension_repo: location cannot be empty")
+}
+
+func (f *fixture) setEnv(key, value string) (restore func()) {
+	old, ok := os.LookupEnv(key)
+	require.NoError(f.t, os.Setenv(key, value))
+	return func() {
+		if ok {
+			_ = os.Setenv(key, old)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	}
+}
+
+func (f *fixture) gitCommitAll(dir string) {
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=tilt", "-c", "user.email=tilt@example.com", "commit", "-q", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = f.JoinPath(dir)
+		out, err := cmd.CombinedOutput()
+		require.NoError(f.t, err, string(out))
+	}
+}
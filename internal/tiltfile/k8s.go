@@ -47,6 +47,13 @@ type k8sResource struct {
 	dependencyIDs []model.TargetID
 
 	triggerMode triggerMode
+
+	restartPolicy model.RestartPolicy
+
+	// Names of resources that must be ready before this one is deployed.
+	resourceDeps []string
+
+	labels []string
 }
 
 const deprecatedResourceAssemblyV1Warning = "This Tiltfile is using k8s resource assembly version 1, which has been " +
@@ -59,6 +66,9 @@ type k8sResourceOptions struct {
 	portForwards      []portForward
 	extraPodSelectors []labels.Selector
 	triggerMode       triggerMode
+	restartPolicy     model.RestartPolicy
+	resourceDeps      []string
+	labels            []string
 	tiltfilePosition  syntax.Position
 	consumed          bool
 }
@@ -329,6 +339,10 @@ func (s *tiltfileState) k8sResourceV2(thread *starlark.Thread, fn *starlark.Buil
 	var portForwardsVal starlark.Value
 	var extraPodSelectorsVal starlark.Value
 	var triggerMode triggerMode
+	var resourceDepsVal starlark.Value
+	var restartPolicyStr string
+	maxRestarts := -1
+	var labelsVal starlark.Value
 
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"workload", &workload,
@@ -336,6 +350,10 @@ func (s *tiltfileState) k8sResourceV2(thread *starlark.Thread, fn *starlark.Buil
 		"port_forwards?", &portForwardsVal,
 		"extra_pod_selectors?", &extraPodSelectorsVal,
 		"trigger_mode?", &triggerMode,
+		"resource_deps?", &resourceDepsVal,
+		"restart_policy?", &restartPolicyStr,
+		"max_restarts?", &maxRestarts,
+		"labels?", &labelsVal,
 	); err != nil {
 		return nil, err
 	}
@@ -354,6 +372,21 @@ func (s *tiltfileState) k8sResourceV2(thread *starlark.Thread, fn *starlark.Buil
 		return nil, err
 	}
 
+	resourceDeps, err := parseValuesToStrings(resourceDepsVal, "resource_deps")
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q", fn.Name(), workload)
+	}
+
+	restartPolicy, err := restartPolicyFromArgs(restartPolicyStr, maxRestarts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q", fn.Name(), workload)
+	}
+
+	labels, err := labelsFromStarlarkValue(labelsVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q", fn.Name(), workload)
+	}
+
 	if opts, ok := s.k8sResourceOptions[workload]; ok {
 		return nil, fmt.Errorf("%s already called for %s, at %s", fn.Name(), workload, opts.tiltfilePosition.String())
 	}
@@ -364,6 +397,9 @@ func (s *tiltfileState) k8sResourceV2(thread *starlark.Thread, fn *starlark.Buil
 		extraPodSelectors: extraPodSelectors,
 		tiltfilePosition:  thread.CallFrame(1).Pos,
 		triggerMode:       triggerMode,
+		restartPolicy:     restartPolicy,
+		resourceDeps:      resourceDeps,
+		labels:            labels,
 	}
 
 	return starlark.None, nil
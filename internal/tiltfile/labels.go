@@ -0,0 +1,40 @@
+package tiltfile
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.starlark.net/starlark"
+)
+
+const maxLabelLength = 63
+
+var labelRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([-_.a-zA-Z0-9]*[a-zA-Z0-9])?$`)
+
+// Converts the `labels` arg of k8s_resource, dc_resource, and local_resource.
+//
+// Labels are short names for grouping resources in the UI,
+// so we use the same rules as Kubernetes label values.
+func labelsFromStarlarkValue(v starlark.Value) ([]string, error) {
+	values, err := parseValuesToStrings(v, "labels")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	seen := make(map[string]bool, len(values))
+	for _, l := range values {
+		if len(l) > maxLabelLength {
+			return nil, fmt.Errorf("labels must be %d characters or less; got %q", maxLabelLength, l)
+		}
+		if !labelRegexp.MatchString(l) {
+			return nil, fmt.Errorf("labels must be alphanumeric, and may contain '-', '_', or '.' in the middle; got %q", l)
+		}
+		if seen[l] {
+			continue
+		}
+		seen[l] = true
+		result = append(result, l)
+	}
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package tiltfile
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// A command that runs on the host machine, re-run whenever its deps change.
+// If serveCmd is set, Tilt also keeps it running after every successful update.
+type localResource struct {
+	name        string
+	cmd         model.Cmd
+	serveCmd    model.Cmd
+	workdir     string
+	deps        []string
+	triggerMode triggerMode
+
+	// Names of resources that must be ready before this one runs.
+	resourceDeps []string
+
+	labels []string
+}
+
+func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name, cmd, serveCmd string
+	var depsVal, resourceDepsVal, labelsVal starlark.Value
+	var triggerMode triggerMode
+
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"name", &name,
+		"cmd?", &cmd,
+		"deps?", &depsVal,
+		"trigger_mode?", &triggerMode,
+		"serve_cmd?", &serveCmd,
+		"resource_deps?", &resourceDepsVal,
+		"labels?", &labelsVal,
+	); err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("%s: `name` must not be empty", fn.Name())
+	}
+
+	if cmd == "" && serveCmd == "" {
+		return nil, fmt.Errorf("%s: one of `cmd` or `serve_cmd` must not be empty", fn.Name())
+	}
+
+	for _, lr := range s.localResources {
+		if lr.name == name {
+			return nil, fmt.Errorf("%s named %q already exists", fn.Name(), name)
+		}
+	}
+
+	var deps []string
+	for _, v := range starlarkValueOrSequenceToSlice(depsVal) {
+		p, err := s.absPathFromStarlarkValue(thread, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: deps: %v", fn.Name(), err)
+		}
+		deps = append(deps, p)
+	}
+
+	resourceDeps, err := parseValuesToStrings(resourceDepsVal, "resource_deps")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	labels, err := labelsFromStarlarkValue(labelsVal)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	s.localResources = append(s.localResources, localResource{
+		name:         name,
+		cmd:          model.ToShellCmd(cmd),
+		serveCmd:     model.ToShellCmd(serveCmd),
+		workdir:      s.absWorkingDir(thread),
+		deps:         deps,
+		triggerMode:  triggerMode,
+		resourceDeps: resourceDeps,
+		labels:       labels,
+	})
+
+	return starlark.None, nil
+}
+
+func (s *tiltfileState) translateLocal(existing []model.Manifest) ([]model.Manifest, error) {
+	taken := make(map[model.ManifestName]bool, len(existing))
+	for _, m := range existing {
+		taken[m.Name] = true
+	}
+
+	var result []model.Manifest
+	for _, r := range s.localResources {
+		mn := model.ManifestName(r.name)
+		if taken[mn] {
+			return nil, fmt.Errorf("%s %q conflicts with an existing resource of the same name", localResourceN, r.name)
+		}
+
+		tm, err := starlarkTriggerModeToModel(s.triggerModeForResource(r.triggerMode))
+		if err != nil {
+			return nil, err
+		}
+
+		lt := model.NewLocalTarget(mn.TargetName(), r.cmd, r.workdir, r.deps).
+			WithServeCmd(r.serveCmd).
+			WithRepos(reposForPaths(r.deps))
+
+		m := model.Manifest{
+			Name:        mn,
+			TriggerMode: tm,
+		}.WithDeployTarget(lt).
+			WithResourceDependencies(model.ToManifestNames(r.resourceDeps)).
+			WithLabels(r.labels)
+
+		result = append(result, m)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,193 @@
+package tiltfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+func TestLocalResource(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('proto', 'make protos', deps=['api', 'Makefile'])
+`)
+
+	f.load()
+	m := f.assertNextManifest("proto")
+	assert.True(t, m.IsLocal())
+
+	lt := m.LocalTarget()
+	assert.Equal(t, model.ToShellCmd("make protos"), lt.Cmd)
+	assert.Equal(t, f.Path(), lt.Workdir)
+	assert.Equal(t, []string{f.JoinPath("Makefile"), f.JoinPath("api")}, lt.Dependencies())
+	assert.Equal(t, model.TriggerModeAuto, m.TriggerMode)
+	f.assertNoMoreManifests()
+}
+
+func TestLocalResourceManualTriggerMode(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('unit-tests', 'go test ./...', trigger_mode=TRIGGER_MODE_MANUAL)
+`)
+
+	f.load()
+	m := f.assertNextManifest("unit-tests")
+	assert.Equal(t, model.TriggerModeManual, m.TriggerMode)
+	assert.Empty(t, m.LocalTarget().Dependencies())
+}
+
+func TestLocalResourceAlongsideK8s(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+local_resource('codegen', 'make gen', deps='foo')
+`)
+
+	f.load()
+	f.assertNextManifest("foo",
+		db(image("gcr.io/foo")),
+		deployment("foo"))
+	m := f.assertNextManifest("codegen")
+	assert.Equal(t, []string{f.JoinPath("foo")}, m.LocalTarget().Dependencies())
+}
+
+func TestLocalResourceDuplicateName(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('codegen', 'make gen')
+local_resource('codegen', 'make gen2')
+`)
+
+	f.loadErrString(`local_resource named "codegen" already exists`)
+}
+
+func TestLocalResourceConflictsWithK8sResource(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+local_resource('foo', 'make gen')
+`)
+
+	f.loadErrString(`local_resource "foo" conflicts with an existing resource`)
+}
+
+func TestLocalResourceEmptyCmd(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('codegen', '')
+`)
+
+	f.loadErrString("one of `cmd` or `serve_cmd` must not be empty")
+}
+
+func TestLocalResourceServeCmd(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('web', 'yarn install', deps=['package.json'], serve_cmd='yarn run start')
+`)
+
+	f.load()
+	lt := f.assertNextManifest("web").LocalTarget()
+	assert.Equal(t, model.ToShellCmd("yarn install"), lt.Cmd)
+	assert.Equal(t, model.ToShellCmd("yarn run start"), lt.ServeCmd)
+}
+
+func TestLocalResourceServeCmdOnly(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('mock-api', serve_cmd='./mock-api --port=8080', deps=['mock-api'])
+`)
+
+	f.load()
+	lt := f.assertNextManifest("mock-api").LocalTarget()
+	assert.True(t, lt.Cmd.Empty())
+	assert.Equal(t, model.ToShellCmd("./mock-api --port=8080"), lt.ServeCmd)
+}
+
+func TestLocalResourceResourceDeps(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('db', serve_cmd='./run-db')
+local_resource('migrate', './migrate', resource_deps=['db'])
+`)
+
+	f.load()
+	assert.Empty(t, f.assertNextManifest("db").ResourceDependencies)
+	m := f.assertNextManifest("migrate")
+	assert.Equal(t, []model.ManifestName{"db"}, m.ResourceDependencies)
+}
+
+func TestLocalResourceResourceDepsUnknown(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('migrate', './migrate', resource_deps=['db'])
+`)
+
+	f.loadErrString(`resource "migrate" specified unknown resource in resource_deps: "db"`)
+}
+
+func TestLocalResourceResourceDepsCycle(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('a', './a', resource_deps=['b'])
+local_resource('b', './b', resource_deps=['c'])
+local_resource('c', './c', resource_deps=['a'])
+`)
+
+	f.loadErrString("resource_deps form a cycle: a -> b -> c -> a")
+}
+
+func TestLocalResourceLabels(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('db', serve_cmd='./run-db', labels=['backend', 'payments', 'backend'])
+local_resource('migrate', './migrate', labels='backend')
+local_resource('lint', './lint')
+`)
+
+	f.load()
+	assert.Equal(t, []string{"backend", "payments"}, f.assertNextManifest("db").Labels)
+	assert.Equal(t, []string{"backend"}, f.assertNextManifest("migrate").Labels)
+	assert.Empty(t, f.assertNextManifest("lint").Labels)
+}
+
+func TestLocalResourceInvalidLabel(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('db', serve_cmd='./run-db', labels=['back end'])
+`)
+
+	f.loadErrString(`labels must be alphanumeric, and may contain '-', '_', or '.' in the middle; got "back end"`)
+}
@@ -0,0 +1,98 @@
+package tiltfile
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+// pack_build builds an image from source with Cloud Native Buildpacks,
+// for projects that don't have a Dockerfile.
+func (s *tiltfileState) packBuild(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var dockerRef, builder, entrypoint string
+	var pathVal, envVal, ignoreVal, onlyVal starlark.Value
+	var matchInEnvVars bool
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"ref", &dockerRef,
+		"path", &pathVal,
+		"builder", &builder,
+		"env?", &envVal,
+		"match_in_env_vars?", &matchInEnvVars,
+		"ignore?", &ignoreVal,
+		"only?", &onlyVal,
+		"entrypoint?", &entrypoint,
+	); err != nil {
+		return nil, err
+	}
+
+	ref, err := container.ParseNamed(dockerRef)
+	if err != nil {
+		return nil, fmt.Errorf("Argument 1 (ref): can't parse %q: %v", dockerRef, err)
+	}
+
+	if pathVal == nil {
+		return nil, fmt.Errorf("Argument 2 (path): empty but is required")
+	}
+	path, err := s.absPathFromStarlarkValue(thread, pathVal)
+	if err != nil {
+		return nil, err
+	}
+
+	if builder == "" {
+		return nil, fmt.Errorf("Argument 3 (builder): empty but is required")
+	}
+	_, err = container.ParseNamed(builder)
+	if err != nil {
+		return nil, fmt.Errorf("Argument 3 (builder): can't parse %q: %v", builder, err)
+	}
+
+	var env map[string]string
+	if envVal != nil {
+		d, ok := envVal.(*starlark.Dict)
+		if !ok {
+			return nil, fmt.Errorf("Argument env: expected dict, got %T", envVal)
+		}
+
+		env, err = skylarkStringDictToGoMap(d)
+		if err != nil {
+			return nil, fmt.Errorf("Argument env: %v", err)
+		}
+	}
+
+	ignores, err := parseValuesToStrings(ignoreVal, "ignore")
+	if err != nil {
+		return nil, err
+	}
+
+	onlys, err := s.parseOnly(onlyVal)
+	if err != nil {
+		return nil, err
+	}
+
+	var entrypointCmd model.Cmd
+	if entrypoint != "" {
+		entrypointCmd = model.ToShellCmd(entrypoint)
+	}
+
+	img := &dockerImage{
+		tiltfilePath:     s.currentTiltfilePath(thread),
+		configurationRef: container.NewRefSelector(ref),
+		matchInEnvVars:   matchInEnvVars,
+		ignores:          ignores,
+		onlys:            onlys,
+		entrypoint:       entrypointCmd,
+		packBuildPath:    path,
+		packBuilder:      builder,
+		packEnv:          env,
+	}
+
+	err = s.buildIndex.addImage(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return starlark.None, nil
+}
@@ -0,0 +1,32 @@
+package tiltfile
+
+import (
+	"fmt"
+
+	"github.com/windmilleng/tilt/pkg/model"
+)
+
+const (
+	restartPolicyNeverN     = "never"
+	restartPolicyOnFailureN = "on_failure"
+)
+
+// Converts the `restart_policy` and `max_restarts` args of k8s_resource and dc_resource.
+// A negative maxRestarts means that it wasn't specified.
+func restartPolicyFromArgs(policy string, maxRestarts int) (model.RestartPolicy, error) {
+	switch policy {
+	case "", restartPolicyNeverN:
+		if maxRestarts >= 0 {
+			return model.RestartPolicy{}, fmt.Errorf("max_restarts only applies to restart_policy=%q", restartPolicyOnFailureN)
+		}
+		return model.RestartPolicy{}, nil
+	case restartPolicyOnFailureN:
+		if maxRestarts < 0 {
+			maxRestarts = model.DefaultMaxRestarts
+		}
+		return model.OnFailureRestartPolicy(maxRestarts), nil
+	default:
+		return model.RestartPolicy{}, fmt.Errorf("restart_policy must be one of %q or %q; got %q",
+			restartPolicyNeverN, restartPolicyOnFailureN, policy)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 
 	"github.com/pkg/errors"
+	"github.com/windmilleng/wmclient/pkg/dirs"
 	"go.starlark.net/resolve"
 	"go.starlark.net/starlark"
 
@@ -35,6 +36,7 @@ type TiltfileLoadResult struct {
 	TiltIgnoreContents string
 	FeatureFlags       map[string]bool
 	TeamName           string
+	UpdateSettings     model.UpdateSettings
 }
 
 func (r TiltfileLoadResult) Orchestrator() model.Orchestrator {
@@ -49,7 +51,8 @@ func (r TiltfileLoadResult) Orchestrator() model.Orchestrator {
 }
 
 type TiltfileLoader interface {
-	Load(ctx context.Context, filename string, matching map[string]bool) (TiltfileLoadResult, error)
+	// Load the Tiltfile. configArgs are passed to the Tiltfile's config module.
+	Load(ctx context.Context, filename string, matching map[string]bool, configArgs []string) (TiltfileLoadResult, error)
 }
 
 type FakeTiltfileLoader struct {
@@ -63,7 +66,7 @@ func NewFakeTiltfileLoader() *FakeTiltfileLoader {
 	return &FakeTiltfileLoader{}
 }
 
-func (tfl *FakeTiltfileLoader) Load(ctx context.Context, filename string, matching map[string]bool) (TiltfileLoadResult, error) {
+func (tfl *FakeTiltfileLoader) Load(ctx context.Context, filename string, matching map[string]bool, configArgs []string) (TiltfileLoadResult, error) {
 	return tfl.Result, tfl.Err
 }
 
@@ -73,7 +76,8 @@ func ProvideTiltfileLoader(
 	dcCli dockercompose.DockerComposeClient,
 	kubeContext k8s.KubeContext,
 	kubeEnv k8s.Env,
-	fDefaults feature.Defaults) TiltfileLoader {
+	fDefaults feature.Defaults,
+	dir *dirs.WindmillDir) TiltfileLoader {
 	return tiltfileLoader{
 		analytics:   analytics,
 		kCli:        kCli,
@@ -81,6 +85,7 @@ func ProvideTiltfileLoader(
 		kubeContext: kubeContext,
 		kubeEnv:     kubeEnv,
 		fDefaults:   fDefaults,
+		extRepo:     newExtensionRepo(dir),
 	}
 }
 
@@ -91,6 +96,7 @@ type tiltfileLoader struct {
 	kubeContext k8s.KubeContext
 	kubeEnv     k8s.Env
 	fDefaults   feature.Defaults
+	extRepo     extensionRepo
 }
 
 var _ TiltfileLoader = &tiltfileLoader{}
@@ -102,7 +108,7 @@ func printWarnings(s *tiltfileState) {
 }
 
 // Load loads the Tiltfile in `filename`, and returns the manifests matching `matching`.
-func (tfl tiltfileLoader) Load(ctx context.Context, filename string, matching map[string]bool) (tlr TiltfileLoadResult, err error) {
+func (tfl tiltfileLoader) Load(ctx context.Context, filename string, matching map[string]bool, configArgs []string) (tlr TiltfileLoadResult, err error) {
 	absFilename, err := ospath.RealAbs(filename)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -114,6 +120,8 @@ func (tfl tiltfileLoader) Load(ctx context.Context, filename string, matching ma
 
 	privateRegistry := tfl.kCli.PrivateRegistry(ctx)
 	s := newTiltfileState(ctx, tfl.dcCli, tfl.kubeContext, tfl.kubeEnv, privateRegistry, feature.FromDefaults(tfl.fDefaults))
+	s.config = newConfigState(absFilename, configArgs)
+	s.extensionRepo = tfl.extRepo
 	printedWarnings := false
 	defer func() {
 		tlr.ConfigFiles = s.configFiles
@@ -132,6 +140,10 @@ func (tfl tiltfileLoader) Load(ctx context.Context, filename string, matching ma
 		return TiltfileLoadResult{}, err
 	}
 
+	if len(configArgs) > 0 && !s.config.parsed {
+		s.warnings = append(s.warnings, fmt.Sprintf("Tiltfile args %q were passed, but the Tiltfile never called %s.%s()", configArgs, configN, configParseN))
+	}
+
 	resources, unresourced, err := s.assemble()
 	if err != nil {
 		return TiltfileLoadResult{}, err
@@ -156,6 +168,17 @@ func (tfl tiltfileLoader) Load(ctx context.Context, filename string, matching ma
 		}
 	}
 
+	localManifests, err := s.translateLocal(manifests)
+	if err != nil {
+		return TiltfileLoadResult{}, err
+	}
+	manifests = append(manifests, localManifests...)
+
+	err = validateResourceDependencies(manifests)
+	if err != nil {
+		return TiltfileLoadResult{}, err
+	}
+
 	err = s.checkForUnconsumedLiveUpdateSteps()
 	if err != nil {
 		return TiltfileLoadResult{}, err
@@ -199,6 +222,7 @@ func (tfl tiltfileLoader) Load(ctx context.Context, filename string, matching ma
 		TiltIgnoreContents: string(tiltIgnoreContents),
 		FeatureFlags:       s.features.ToEnabled(),
 		TeamName:           s.teamName,
+		UpdateSettings:     s.updateSettings,
 	}, err
 }
 
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"go.starlark.net/syntax"
 
@@ -46,6 +47,7 @@ type tiltfileState struct {
 	k8sUnresourced     []k8s.K8sEntity
 	dc                 dcResourceSet // currently only support one d-c.yml
 	k8sResourceOptions map[string]k8sResourceOptions
+	localResources     []localResource
 
 	// ensure that any pushed images are pushed instead to this registry, rewriting names if needed
 	defaultRegistryHost container.Registry
@@ -85,6 +87,13 @@ type tiltfileState struct {
 
 	teamName string
 
+	updateSettings model.UpdateSettings
+
+	config configState
+
+	// where load('ext://...') finds extensions
+	extensionRepo extensionRepo
+
 	logger   logger.Logger
 	warnings []string
 }
@@ -125,6 +134,7 @@ func newTiltfileState(
 		triggerMode:                TriggerModeAuto,
 		features:                   features,
 		loadCache:                  make(map[string]loadCacheEntry),
+		updateSettings:             model.DefaultUpdateSettings(),
 	}
 }
 
@@ -144,6 +154,9 @@ func (s *tiltfileState) print(_ *starlark.Thread, msg string) {
 
 // load() for fulfilling the starlark thread callback
 func (s *tiltfileState) load(thread *starlark.Thread, f string) (starlark.StringDict, error) {
+	if strings.HasPrefix(f, extensionPrefix) {
+		return s.loadExtension(strings.TrimPrefix(f, extensionPrefix))
+	}
 	return s.exec(s.absPath(thread, f))
 }
 
@@ -161,6 +174,7 @@ const (
 	dockerBuildN     = "docker_build"
 	fastBuildN       = "fast_build"
 	customBuildN     = "custom_build"
+	packBuildN       = "pack_build"
 	defaultRegistryN = "default_registry"
 
 	// docker compose functions
@@ -179,6 +193,9 @@ const (
 	k8sContextN                 = "k8s_context"
 	allowK8SContexts            = "allow_k8s_contexts"
 
+	// local resource functions
+	localResourceN = "local_resource"
+
 	// file functions
 	localGitRepoN = "local_git_repo"
 	localN        = "local"
@@ -208,9 +225,18 @@ const (
 	disableFeatureN = "disable_feature"
 
 	// other functions
-	failN    = "fail"
-	blobN    = "blob"
-	setTeamN = "set_team"
+	failN           = "fail"
+	blobN           = "blob"
+	setTeamN        = "set_team"
+	updateSettingsN = "update_settings"
+	extensionRepoN  = "extension_repo"
+
+	// config module
+	configN                 = "config"
+	configDefineStringN     = "define_string"
+	configDefineStringListN = "define_string_list"
+	configDefineBoolN       = "define_bool"
+	configParseN            = "parse"
 )
 
 type triggerMode int
@@ -324,6 +350,7 @@ func (s *tiltfileState) predeclared() starlark.StringDict {
 	addBuiltin(r, dockerBuildN, s.dockerBuild)
 	addBuiltin(r, fastBuildN, s.fastBuild)
 	addBuiltin(r, customBuildN, s.customBuild)
+	addBuiltin(r, packBuildN, s.packBuild)
 	addBuiltin(r, defaultRegistryN, s.defaultRegistry)
 	addBuiltin(r, dockerComposeN, s.dockerCompose)
 	addBuiltin(r, dcResourceN, s.dcResource)
@@ -337,6 +364,7 @@ func (s *tiltfileState) predeclared() starlark.StringDict {
 	addBuiltin(r, workloadToResourceFunctionN, s.workloadToResourceFunctionFn)
 	addBuiltin(r, k8sContextN, s.k8sContext)
 	addBuiltin(r, allowK8SContexts, s.allowK8SContexts)
+	addBuiltin(r, localResourceN, s.localResource)
 	addBuiltin(r, localGitRepoN, s.localGitRepo)
 	addBuiltin(r, kustomizeN, s.kustomize)
 	addBuiltin(r, helmN, s.helm)
@@ -361,6 +389,19 @@ func (s *tiltfileState) predeclared() starlark.StringDict {
 	addBuiltin(r, disableFeatureN, s.disableFeature)
 
 	addBuiltin(r, setTeamN, s.setTeam)
+	addBuiltin(r, updateSettingsN, s.setUpdateSettings)
+	addBuiltin(r, extensionRepoN, s.setExtensionRepo)
+
+	config := builtinModule{name: configN, members: make(starlark.StringDict)}
+	addModuleBuiltin := func(m builtinModule, name string, fn func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error)) {
+		fullName := m.name + "." + name
+		m.members[name] = starlark.NewBuiltin(fullName, s.makeBuiltinReporting(fullName, fn))
+	}
+	addModuleBuiltin(config, configDefineStringN, s.configDefineString)
+	addModuleBuiltin(config, configDefineStringListN, s.configDefineStringList)
+	addModuleBuiltin(config, configDefineBoolN, s.configDefineBool)
+	addModuleBuiltin(config, configParseN, s.configParse)
+	r[configN] = config
 
 	s.predeclaredMap = r
 
@@ -431,6 +472,22 @@ func (s *tiltfileState) assembleImages() error {
 			return err
 		}
 
+		imageBuilder.deploymentCacheFrom = nil
+		for _, ref := range imageBuilder.dbCacheFrom {
+			cacheRef, err := replaceCacheRegistry(registry, ref)
+			if err != nil {
+				return err
+			}
+			imageBuilder.deploymentCacheFrom = append(imageBuilder.deploymentCacheFrom, cacheRef)
+		}
+
+		if imageBuilder.dbCacheTo != nil {
+			imageBuilder.deploymentCacheTo, err = replaceCacheRegistry(registry, imageBuilder.dbCacheTo)
+			if err != nil {
+				return err
+			}
+		}
+
 		var depImages []reference.Named
 		if imageBuilder.dbDockerfile != "" {
 			depImages, err = imageBuilder.dbDockerfile.FindImages()
@@ -452,6 +509,24 @@ func (s *tiltfileState) assembleImages() error {
 	return nil
 }
 
+// Moves a cache ref into the given registry, the same way we move image refs,
+// but keeps its tag (e.g., `myapp:buildcache`), because the tag is what
+// tells it apart from the image.
+func replaceCacheRegistry(registry container.Registry, ref reference.Named) (string, error) {
+	newRef, err := container.ReplaceRegistry(registry, container.NewRefSelector(ref))
+	if err != nil {
+		return "", err
+	}
+
+	if tagged, ok := ref.(reference.NamedTagged); ok {
+		newRef, err = reference.WithTag(newRef, tagged.Tag())
+		if err != nil {
+			return "", err
+		}
+	}
+	return newRef.String(), nil
+}
+
 func (s *tiltfileState) assembleDC() error {
 	if len(s.dc.services) > 0 && s.defaultRegistryHost != "" {
 		return errors.New("default_registry is not supported with docker compose")
@@ -504,6 +579,9 @@ func (s *tiltfileState) assembleK8sV2() error {
 			r.extraPodSelectors = opts.extraPodSelectors
 			r.portForwards = opts.portForwards
 			r.triggerMode = opts.triggerMode
+			r.restartPolicy = opts.restartPolicy
+			r.resourceDeps = opts.resourceDeps
+			r.labels = opts.labels
 			if opts.newName != "" && opts.newName != r.name {
 				if _, ok := s.k8sByName[opts.newName]; ok {
 					return fmt.Errorf("k8s_resource at %s specified to rename '%s' to '%s', but there is already a resource with that name", opts.tiltfilePosition.String(), r.name, opts.newName)
@@ -821,9 +899,11 @@ func (s *tiltfileState) translateK8s(resources []*k8sResource) ([]model.Manifest
 			return nil, err
 		}
 		m := model.Manifest{
-			Name:        mn,
-			TriggerMode: tm,
-		}
+			Name:          mn,
+			TriggerMode:   tm,
+			RestartPolicy: r.restartPolicy,
+		}.WithResourceDependencies(model.ToManifestNames(r.resourceDeps)).
+			WithLabels(r.labels)
 
 		k8sTarget, err := k8s.NewTarget(mn.TargetName(), r.entities, s.portForwardsToDomain(r), r.extraPodSelectors, r.dependencyIDs, r.imageRefMap)
 		if err != nil {
@@ -852,6 +932,61 @@ func (s *tiltfileState) translateK8s(resources []*k8sResource) ([]model.Manifest
 	return result, nil
 }
 
+// validateResourceDependencies checks that every resource_deps entry names
+// a resource in this Tiltfile, and that the dependencies don't form a cycle.
+func validateResourceDependencies(manifests []model.Manifest) error {
+	byName := make(map[model.ManifestName]model.Manifest, len(manifests))
+	for _, m := range manifests {
+		byName[m.Name] = m
+	}
+
+	for _, m := range manifests {
+		for _, dep := range m.ResourceDependencies {
+			if _, ok := byName[dep]; !ok {
+				return fmt.Errorf("resource %q specified unknown resource in resource_deps: %q", m.Name, dep)
+			}
+		}
+	}
+
+	// Depth-first search for cycles, in a stable order.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[model.ManifestName]int, len(manifests))
+	var visit func(mn model.ManifestName, path []model.ManifestName) error
+	visit = func(mn model.ManifestName, path []model.ManifestName) error {
+		path = append(path, mn)
+		switch marks[mn] {
+		case visiting:
+			names := make([]string, len(path))
+			for i, n := range path {
+				names[i] = n.String()
+			}
+			return fmt.Errorf("resource_deps form a cycle: %s", strings.Join(names, " -> "))
+		case visited:
+			return nil
+		}
+
+		marks[mn] = visiting
+		for _, dep := range byName[mn].ResourceDependencies {
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+		marks[mn] = visited
+		return nil
+	}
+
+	for _, m := range manifests {
+		if err := visit(m.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // checkForImpossibleLiveUpdates logs a warning if the group of image targets contains
 // any impossible LiveUpdates (or FastBuilds).
 //
@@ -972,11 +1107,16 @@ func (s *tiltfileState) imgTargetsForDependencyIDsHelper(ids []model.TargetID, c
 		switch image.Type() {
 		case DockerBuild:
 			iTarget = iTarget.WithBuildDetails(model.DockerBuild{
-				Dockerfile: image.dbDockerfile.String(),
-				BuildPath:  image.dbBuildPath,
-				BuildArgs:  image.dbBuildArgs,
-				FastBuild:  s.fastBuildForImage(image),
-				LiveUpdate: lu,
+				Dockerfile:  image.dbDockerfile.String(),
+				BuildPath:   image.dbBuildPath,
+				BuildArgs:   image.dbBuildArgs,
+				FastBuild:   s.fastBuildForImage(image),
+				LiveUpdate:  lu,
+				TargetStage: image.dbTarget,
+				SSHSpecs:    image.dbSSHSpecs,
+				SecretSpecs: image.dbSecretSpecs,
+				CacheFrom:   image.deploymentCacheFrom,
+				CacheTo:     image.deploymentCacheTo,
 			})
 		case FastBuild:
 			iTarget = iTarget.WithBuildDetails(s.fastBuildForImage(image))
@@ -997,6 +1137,12 @@ func (s *tiltfileState) imgTargetsForDependencyIDsHelper(ids []model.TargetID, c
 			}
 			iTarget = iTarget.WithBuildDetails(r)
 			// TODO(dbentley): validate that syncs is a subset of deps
+		case PackBuild:
+			iTarget = iTarget.WithBuildDetails(model.PackBuild{
+				BuildPath: image.packBuildPath,
+				Builder:   image.packBuilder,
+				Env:       image.packEnv,
+			})
 		case UnknownBuild:
 			return nil, fmt.Errorf("no build info for image %s", image.configurationRef)
 		}
@@ -1143,6 +1289,47 @@ func (s *tiltfileState) triggerModeFn(thread *starlark.Thread, fn *starlark.Buil
 	return starlark.None, nil
 }
 
+func (s *tiltfileState) setUpdateSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	maxParallelUpdates := s.updateSettings.MaxParallelUpdates
+	maxRetries := s.updateSettings.MaxRetries
+	retryBackoffSecs := int(s.updateSettings.RetryBackoff / time.Second)
+	k8sPrune := s.updateSettings.K8sPrune
+	buildkitAddr := s.updateSettings.BuildKitAddr
+	err := s.unpackArgs(fn.Name(), args, kwargs,
+		"max_parallel_updates?", &maxParallelUpdates,
+		"max_retries?", &maxRetries,
+		"retry_backoff?", &retryBackoffSecs,
+		"k8s_prune?", &k8sPrune,
+		"buildkit_addr?", &buildkitAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxParallelUpdates < 1 {
+		return nil, fmt.Errorf("max_parallel_updates must be >= 1, got %d", maxParallelUpdates)
+	}
+
+	if maxRetries < 0 {
+		return nil, fmt.Errorf("max_retries must be >= 0, got %d", maxRetries)
+	}
+
+	if retryBackoffSecs < 1 {
+		return nil, fmt.Errorf("retry_backoff must be >= 1 second, got %d", retryBackoffSecs)
+	}
+
+	if buildkitAddr != "" && !strings.HasPrefix(buildkitAddr, "unix://") && !strings.HasPrefix(buildkitAddr, "tcp://") {
+		return nil, fmt.Errorf("buildkit_addr must start with unix:// or tcp://, got %q", buildkitAddr)
+	}
+
+	s.updateSettings.MaxParallelUpdates = maxParallelUpdates
+	s.updateSettings.MaxRetries = maxRetries
+	s.updateSettings.RetryBackoff = time.Duration(retryBackoffSecs) * time.Second
+	s.updateSettings.K8sPrune = k8sPrune
+	s.updateSettings.BuildKitAddr = buildkitAddr
+
+	return starlark.None, nil
+}
+
 func (s *tiltfileState) setTeam(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var teamName string
 	err := s.unpackArgs(fn.Name(), args, kwargs, "team_name", &teamName)
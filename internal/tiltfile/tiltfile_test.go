@@ -10,10 +10,12 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/windmilleng/wmclient/pkg/analytics"
+	"github.com/windmilleng/wmclient/pkg/dirs"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -936,7 +938,7 @@ docker_build('gcr.io/bar', 'bar')
 k8s_yaml('bar.yaml')
 `)
 
-	_, err := f.newTiltfileLoader().Load(f.ctx, f.JoinPath("Tiltfile"), matchMap("baz"))
+	_, err := f.newTiltfileLoader().Load(f.ctx, f.JoinPath("Tiltfile"), matchMap("baz"), nil)
 	if assert.Error(t, err) {
 		assert.Equal(t, `You specified some resources that could not be found: "baz"
 Is this a typo? Existing resources in Tiltfile: "foo", "bar"`, err.Error())
@@ -2961,6 +2963,23 @@ k8s_resource('foo', new_name='bar')
 	f.assertNextManifest("bar", deployment("foo"))
 }
 
+func TestK8sResourceResourceDeps(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_resource_assembly_version(2)
+k8s_yaml('foo.yaml')
+local_resource('codegen', 'make gen')
+k8s_resource('foo', resource_deps=['codegen'])
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo", deployment("foo"))
+	assert.Equal(t, []model.ManifestName{"codegen"}, m.ResourceDependencies)
+}
+
 func TestK8sResourceNewNameConflict(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -3330,6 +3349,111 @@ docker_compose('docker-compose.yml')
 	}
 }
 
+func TestRestartPolicyK8s(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', restart_policy='on_failure', max_restarts=3)
+`)
+
+	f.load()
+	f.assertNextManifest("foo", model.OnFailureRestartPolicy(3))
+}
+
+func TestK8sResourceLabels(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', labels=['backend', 'payments'])
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo")
+	assert.Equal(t, []string{"backend", "payments"}, m.Labels)
+}
+
+func TestDCResourceLabels(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.dockerfile("foo/Dockerfile")
+	f.file("docker-compose.yml", simpleConfig)
+	f.file("Tiltfile", `
+docker_compose('docker-compose.yml')
+dc_resource('foo', 'gcr.io/foo', labels='backend')
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo")
+	assert.Equal(t, []string{"backend"}, m.Labels)
+}
+
+func TestRestartPolicyDefaultMaxRestarts(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.dockerfile("foo/Dockerfile")
+	f.file("docker-compose.yml", simpleConfig)
+	f.file("Tiltfile", `
+docker_compose('docker-compose.yml')
+dc_resource('foo', 'gcr.io/foo', restart_policy='on_failure')
+`)
+
+	f.load()
+	f.assertNextManifest("foo", model.OnFailureRestartPolicy(model.DefaultMaxRestarts))
+}
+
+func TestRestartPolicyNever(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', restart_policy='never')
+`)
+
+	f.load()
+	f.assertNextManifest("foo", model.RestartPolicy{})
+}
+
+func TestRestartPolicyInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', restart_policy='always')
+`)
+
+	f.loadErrString(`restart_policy must be one of "never" or "on_failure"; got "always"`)
+}
+
+func TestMaxRestartsWithoutRestartPolicy(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', max_restarts=3)
+`)
+
+	f.loadErrString(`max_restarts only applies to restart_policy="on_failure"`)
+}
+
 func TestTriggerModeInt(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -3738,6 +3862,143 @@ k8s_yaml('foo.yaml')
 	f.assertNextManifest("foo", db(image("gcr.io/foo"), entrypoint("/bin/the_app")))
 }
 
+func TestDockerBuildTargetSSHAndSecret(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.dockerfile("Dockerfile")
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', '.', target='dev',
+  ssh=['default', 'github=keys/id_rsa'], secret=['id=npmrc,src=.npmrc', 'src=creds/gcp.json', 'id=token'])
+k8s_yaml('foo.yaml')
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	dbInfo := m.ImageTargetAt(0).DockerBuildInfo()
+	assert.Equal(t, "dev", dbInfo.TargetStage)
+	assert.Equal(t, []string{"default", "github=" + f.JoinPath("keys", "id_rsa")}, dbInfo.SSHSpecs)
+	assert.Equal(t, []string{
+		"id=npmrc,src=" + f.JoinPath(".npmrc"),
+		"id=gcp.json,src=" + f.JoinPath("creds", "gcp.json"),
+		"id=token,src=" + f.JoinPath("token"),
+	}, dbInfo.SecretSpecs)
+}
+
+func TestDockerBuildCacheFromAndTo(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.dockerfile("Dockerfile")
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', '.', cache_from=['gcr.io/foo-cache:ci', 'gcr.io/foo'], cache_to='gcr.io/foo-cache:ci')
+k8s_yaml('foo.yaml')
+default_registry('example.com')
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	dbInfo := m.ImageTargetAt(0).DockerBuildInfo()
+	assert.Equal(t, []string{"example.com/gcr.io_foo-cache:ci", "example.com/gcr.io_foo"}, dbInfo.CacheFrom)
+	assert.Equal(t, "example.com/gcr.io_foo-cache:ci", dbInfo.CacheTo)
+}
+
+func TestDockerBuildInvalidCacheTo(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.dockerfile("Dockerfile")
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', '.', cache_to='gcr.io/Foo')
+`)
+
+	f.loadErrString("Argument cache_to: can't parse")
+}
+
+func TestDockerBuildInvalidSecret(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.dockerfile("Dockerfile")
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', '.', secret='npmrc')
+k8s_yaml('foo.yaml')
+`)
+
+	f.loadErrString("Argument secret", "invalid secret spec \"npmrc\"")
+}
+
+func TestPackBuild(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("foo/main.go", "package main")
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+pack_build('gcr.io/foo', 'foo', builder='gcr.io/buildpacks/builder:v1', env={'GOOGLE_BUILDABLE': './cmd/foo'})
+k8s_yaml('foo.yaml')
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo")
+	iTarget := m.ImageTargetAt(0)
+	if assert.True(t, iTarget.IsPackBuild()) {
+		pbInfo := iTarget.PackBuildInfo()
+		assert.Equal(t, f.JoinPath("foo"), pbInfo.BuildPath)
+		assert.Equal(t, "gcr.io/buildpacks/builder:v1", pbInfo.Builder)
+		assert.Equal(t, map[string]string{"GOOGLE_BUILDABLE": "./cmd/foo"}, pbInfo.Env)
+	}
+	assert.Equal(t, []string{f.JoinPath("foo")}, iTarget.LocalPaths())
+}
+
+func TestPackBuildDockerignore(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.gitInit("")
+	f.file("foo/.dockerignore", "*.txt")
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+pack_build('gcr.io/foo', 'foo', builder='gcr.io/buildpacks/builder:v1', ignore='a.md')
+k8s_yaml('foo.yaml')
+`)
+
+	f.load()
+	f.assertNextManifest("foo",
+		buildFilters("foo/a.txt"),
+		buildFilters("foo/a.md"),
+		fileChangeFilters("foo/a.txt"),
+		fileChangeFilters("foo/a.md"),
+		buildMatches("foo/txt.a"),
+		fileChangeMatches("foo/txt.a"),
+	)
+}
+
+func TestPackBuildMissingBuilder(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+pack_build('gcr.io/foo', 'foo')
+`)
+
+	f.loadErrString("pack_build: missing argument for builder")
+}
+
+func TestPackBuildInvalidEnv(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+pack_build('gcr.io/foo', 'foo', builder='gcr.io/buildpacks/builder:v1', env=['GOOGLE_BUILDABLE'])
+`)
+
+	f.loadErrString("Argument env: expected dict")
+}
+
 func TestCustomBuildEntrypoint(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -3812,6 +4073,97 @@ set_team('jets')
 	f.loadErrString("team_name set multiple times", "'sharks'", "'jets'")
 }
 
+func TestUpdateSettingsDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", "")
+	f.load()
+
+	assert.Equal(t, model.DefaultUpdateSettings(), f.loadResult.UpdateSettings)
+}
+
+func TestUpdateSettingsMaxParallelUpdates(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", "update_settings(max_parallel_updates=4)")
+	f.load()
+
+	assert.Equal(t, 4, f.loadResult.UpdateSettings.MaxParallelUpdates)
+}
+
+func TestUpdateSettingsMaxParallelUpdatesInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", "update_settings(max_parallel_updates=0)")
+	f.loadErrString("max_parallel_updates must be >= 1, got 0")
+}
+
+func TestUpdateSettingsRetries(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", "update_settings(max_retries=5, retry_backoff=10)")
+	f.load()
+
+	assert.Equal(t, model.DefaultMaxParallelUpdates, f.loadResult.UpdateSettings.MaxParallelUpdates)
+	assert.Equal(t, 5, f.loadResult.UpdateSettings.MaxRetries)
+	assert.Equal(t, 10*time.Second, f.loadResult.UpdateSettings.RetryBackoff)
+}
+
+func TestUpdateSettingsDisableRetries(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", "update_settings(max_retries=0)")
+	f.load()
+
+	assert.Equal(t, 0, f.loadResult.UpdateSettings.MaxRetries)
+	assert.Equal(t, model.DefaultRetryBackoff, f.loadResult.UpdateSettings.RetryBackoff)
+}
+
+func TestUpdateSettingsK8sPrune(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", "update_settings(k8s_prune=False)")
+	f.load()
+
+	assert.False(t, f.loadResult.UpdateSettings.K8sPrune)
+	assert.Equal(t, model.DefaultMaxRetries, f.loadResult.UpdateSettings.MaxRetries)
+}
+
+func TestUpdateSettingsBuildKitAddr(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", "update_settings(buildkit_addr='unix:///run/buildkit/buildkitd.sock')")
+	f.load()
+
+	assert.Equal(t, "unix:///run/buildkit/buildkitd.sock", f.loadResult.UpdateSettings.BuildKitAddr)
+}
+
+func TestUpdateSettingsBuildKitAddrInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", "update_settings(buildkit_addr='localhost:1234')")
+	f.loadErrString(`buildkit_addr must start with unix:// or tcp://, got "localhost:1234"`)
+}
+
+func TestUpdateSettingsRetriesInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", "update_settings(max_retries=-1)")
+	f.loadErrString("max_retries must be >= 0, got -1")
+
+	f.file("Tiltfile", "update_settings(retry_backoff=0)")
+	f.loadErrString("retry_backoff must be >= 1 second, got 0")
+}
+
 func TestK8SContextAcceptance(t *testing.T) {
 	for _, test := range []struct {
 		name                    string
@@ -3860,6 +4212,9 @@ type fixture struct {
 	an *analytics.MemoryAnalytics
 
 	loadResult TiltfileLoadResult
+
+	// Args passed to the Tiltfile's config module on load.
+	configArgs []string
 }
 
 func (f *fixture) newTiltfileLoader() TiltfileLoader {
@@ -3870,7 +4225,8 @@ func (f *fixture) newTiltfileLoader() TiltfileLoader {
 		"obsoleteflag":                   feature.Value{Status: feature.Obsolete, Enabled: true},
 		feature.MultipleContainersPerPod: feature.Value{Enabled: false},
 	}
-	return ProvideTiltfileLoader(f.ta, f.kCli, dcc, f.k8sContext, f.k8sEnv, features)
+	dir := dirs.NewWindmillDirAt(f.JoinPath(".windmill"))
+	return ProvideTiltfileLoader(f.ta, f.kCli, dcc, f.k8sContext, f.k8sEnv, features, dir)
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -4005,7 +4361,7 @@ func (f *fixture) load(names ...string) {
 }
 
 func (f *fixture) loadResourceAssemblyV1(names ...string) {
-	tlr, err := f.newTiltfileLoader().Load(f.ctx, f.JoinPath("Tiltfile"), matchMap(names...))
+	tlr, err := f.newTiltfileLoader().Load(f.ctx, f.JoinPath("Tiltfile"), matchMap(names...), f.configArgs)
 	if err != nil {
 		f.t.Fatal(err)
 	}
@@ -4016,7 +4372,7 @@ func (f *fixture) loadResourceAssemblyV1(names ...string) {
 // Load the manifests, expecting warnings.
 // Warnings should be asserted later with assertWarnings
 func (f *fixture) loadAllowWarnings(names ...string) {
-	tlr, err := f.newTiltfileLoader().Load(f.ctx, f.JoinPath("Tiltfile"), matchMap(names...))
+	tlr, err := f.newTiltfileLoader().Load(f.ctx, f.JoinPath("Tiltfile"), matchMap(names...), f.configArgs)
 	if err != nil {
 		f.t.Fatal(err)
 	}
@@ -4041,7 +4397,7 @@ func (f *fixture) loadAssertWarnings(warnings ...string) {
 }
 
 func (f *fixture) loadErrString(msgs ...string) {
-	tlr, err := f.newTiltfileLoader().Load(f.ctx, f.JoinPath("Tiltfile"), nil)
+	tlr, err := f.newTiltfileLoader().Load(f.ctx, f.JoinPath("Tiltfile"), nil, f.configArgs)
 	if err == nil {
 		f.t.Fatalf("expected error but got nil")
 	}
@@ -4318,6 +4674,8 @@ func (f *fixture) assertNextManifest(name string, opts ...interface{}) model.Man
 			assert.Equal(f.t, opt, m.K8sTarget().PortForwards)
 		case model.TriggerMode:
 			assert.Equal(f.t, opt, m.TriggerMode)
+		case model.RestartPolicy:
+			assert.Equal(f.t, opt, m.RestartPolicy)
 		case funcOpt:
 			assert.True(f.t, opt(f.t, m))
 		default:
@@ -14,6 +14,14 @@ type BuildRecord struct {
 	FinishTime time.Time // IsZero() == true for in-progress builds
 	Reason     BuildReason
 	Log        Log `testdiff:"ignore"`
+
+	// True if we canceled this build because newer changes came in
+	// while it was running. A superseded build is neither a success nor a failure.
+	Superseded bool
+
+	// True if none of the images needed rebuilding, because the contents
+	// of their build contexts were unchanged since the images we deployed.
+	NoOp bool
 }
 
 func (bs BuildRecord) Empty() bool {
@@ -88,6 +88,13 @@ func (i ImageTarget) Validate() error {
 				"[Validate] CustomBuild command must not be empty",
 			)
 		}
+	case PackBuild:
+		if bd.BuildPath == "" {
+			return fmt.Errorf("[Validate] Image %q missing build path", i.ConfigurationRef)
+		}
+		if bd.Builder == "" {
+			return fmt.Errorf("[Validate] Image %q missing buildpacks builder", i.ConfigurationRef)
+		}
 	default:
 		return fmt.Errorf("[Validate] Image %q has neither DockerBuildInfo nor FastBuildInfo", i.ConfigurationRef)
 	}
@@ -156,6 +163,16 @@ func (i ImageTarget) IsCustomBuild() bool {
 	return ok
 }
 
+func (i ImageTarget) PackBuildInfo() PackBuild {
+	ret, _ := i.BuildDetails.(PackBuild)
+	return ret
+}
+
+func (i ImageTarget) IsPackBuild() bool {
+	_, ok := i.BuildDetails.(PackBuild)
+	return ok
+}
+
 func (i ImageTarget) WithBuildDetails(details BuildDetails) ImageTarget {
 	i.BuildDetails = details
 	return i
@@ -202,6 +219,8 @@ func (i ImageTarget) LocalPaths() []string {
 		return result
 	case CustomBuild:
 		return append([]string(nil), bd.Deps...)
+	case PackBuild:
+		return []string{bd.BuildPath}
 	}
 	return nil
 }
@@ -243,6 +262,19 @@ type DockerBuild struct {
 	BuildArgs  DockerBuildArgs
 	FastBuild  FastBuild  // Optionally, can use FastBuild to update this build in place.
 	LiveUpdate LiveUpdate // Optionally, can use LiveUpdate to update this build in place.
+
+	// The stage of a multi-stage Dockerfile to stop at.
+	TargetStage string
+
+	// SSH agents and secrets that the build can mount, in the same format
+	// as `docker build --ssh` and `docker build --secret`.
+	SSHSpecs    []string
+	SecretSpecs []string
+
+	// Registry refs to import BuildKit's build cache from, and to export
+	// it to, so that builds on other machines can reuse each other's layers.
+	CacheFrom []string
+	CacheTo   string
 }
 
 func (DockerBuild) buildDetails() {}
@@ -284,4 +316,18 @@ func (cb CustomBuild) WithTag(t string) CustomBuild {
 	return cb
 }
 
+// Builds an image from source with Cloud Native Buildpacks, without a Dockerfile.
+type PackBuild struct {
+	BuildPath string // the absolute path to the files
+
+	// The builder image that has the buildpacks and the lifecycle
+	// (e.g., gcr.io/buildpacks/builder).
+	Builder string
+
+	// Environment variables to pass to the buildpacks.
+	Env map[string]string
+}
+
+func (PackBuild) buildDetails() {}
+
 var _ TargetSpec = ImageTarget{}
@@ -16,6 +16,14 @@ type K8sTarget struct {
 	// labels for pods that we should watch and associate with this resource
 	ExtraPodSelectors []labels.Selector
 
+	// Whether any of the entities create pods (e.g., a Deployment).
+	// If not, there's no runtime to wait on after the YAML is applied.
+	HasPodTemplates bool
+
+	// Whether any of the entities is a Job. Jobs run to completion, so we
+	// report whether they succeeded rather than whether their pods are up.
+	HasJobs bool
+
 	// Each K8s entity should have a display name for user interfaces
 	// that balances brevity and uniqueness
 	DisplayNames []string
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/windmilleng/tilt/internal/sliceutils"
+)
+
+// A LocalTarget is a command that Tilt runs on the host machine
+// (not in a container), e.g., codegen, protobuf compilation, or unit tests.
+type LocalTarget struct {
+	Name TargetName
+
+	// Runs to completion on every update. May be empty if the target only serves.
+	Cmd Cmd
+
+	// A long-running process that Tilt starts after Cmd succeeds,
+	// and restarts after every subsequent update.
+	ServeCmd Cmd
+
+	// The directory the command runs in. Usually the directory of the Tiltfile.
+	Workdir string
+
+	// Changes to these files (or the files in these directories)
+	// trigger a re-run of the command.
+	deps []string
+
+	repos []LocalGitRepo
+}
+
+func NewLocalTarget(name TargetName, cmd Cmd, workdir string, deps []string) LocalTarget {
+	return LocalTarget{
+		Name:    name,
+		Cmd:     cmd,
+		Workdir: workdir,
+		deps:    sliceutils.DedupedAndSorted(deps),
+	}
+}
+
+func (lt LocalTarget) WithServeCmd(c Cmd) LocalTarget {
+	lt.ServeCmd = c
+	return lt
+}
+
+func (lt LocalTarget) Empty() bool { return lt.ID().Empty() }
+
+func (lt LocalTarget) ID() TargetID {
+	return TargetID{
+		Type: TargetTypeLocal,
+		Name: lt.Name,
+	}
+}
+
+func (lt LocalTarget) DependencyIDs() []TargetID {
+	return nil
+}
+
+func (lt LocalTarget) WithRepos(repos []LocalGitRepo) LocalTarget {
+	lt.repos = append(append([]LocalGitRepo{}, lt.repos...), repos...)
+	return lt
+}
+
+func (lt LocalTarget) Dependencies() []string {
+	return append([]string{}, lt.deps...)
+}
+
+func (lt LocalTarget) LocalRepos() []LocalGitRepo {
+	return lt.repos
+}
+
+func (lt LocalTarget) Dockerignores() []Dockerignore {
+	return nil
+}
+
+func (lt LocalTarget) IgnoredLocalDirectories() []string {
+	return nil
+}
+
+func (lt LocalTarget) Validate() error {
+	if lt.ID().Empty() {
+		return fmt.Errorf("[Validate] LocalTarget missing name")
+	}
+
+	if lt.Cmd.Empty() && lt.ServeCmd.Empty() {
+		return fmt.Errorf("[Validate] LocalTarget %q missing command", lt.Name)
+	}
+
+	if lt.Workdir == "" {
+		return fmt.Errorf("[Validate] LocalTarget %q missing workdir", lt.Name)
+	}
+
+	return nil
+}
+
+var _ TargetSpec = LocalTarget{}
@@ -21,6 +21,17 @@ type ManifestName string
 func (m ManifestName) String() string         { return string(m) }
 func (m ManifestName) TargetName() TargetName { return TargetName(m) }
 
+func ToManifestNames(names []string) []ManifestName {
+	if len(names) == 0 {
+		return nil
+	}
+	result := make([]ManifestName, len(names))
+	for i, name := range names {
+		result[i] = ManifestName(name)
+	}
+	return result
+}
+
 // NOTE: If you modify Manifest, make sure to modify `Manifest.Equal` appropriately
 type Manifest struct {
 	// Properties for all manifests.
@@ -36,6 +47,16 @@ type Manifest struct {
 	// - automatically, when we detect a change
 	// - manually, when the user tells us to
 	TriggerMode TriggerMode
+
+	// What to do when the deployed resource crashes.
+	RestartPolicy RestartPolicy
+
+	// Other manifests that must be deployed (and ready) before
+	// this one is first built.
+	ResourceDependencies []ManifestName
+
+	// Labels for grouping manifests in the UI.
+	Labels []string
 }
 
 func (m Manifest) ID() TargetID {
@@ -56,6 +77,11 @@ func (m Manifest) DependencyIDs() []TargetID {
 	return result
 }
 
+func (m Manifest) WithResourceDependencies(deps []ManifestName) Manifest {
+	m.ResourceDependencies = append([]ManifestName{}, deps...)
+	return m
+}
+
 func (m Manifest) WithImageTarget(iTarget ImageTarget) Manifest {
 	m.ImageTargets = []ImageTarget{iTarget}
 	return m
@@ -95,6 +121,16 @@ func (m Manifest) IsK8s() bool {
 	return ok
 }
 
+func (m Manifest) LocalTarget() LocalTarget {
+	ret, _ := m.deployTarget.(LocalTarget)
+	return ret
+}
+
+func (m Manifest) IsLocal() bool {
+	_, ok := m.deployTarget.(LocalTarget)
+	return ok
+}
+
 func (m Manifest) IsUnresourcedYAMLManifest() bool {
 	return m.Name == UnresourcedYAMLManifestName
 }
@@ -111,6 +147,9 @@ func (m Manifest) WithDeployTarget(t TargetSpec) Manifest {
 	case DockerComposeTarget:
 		typedTarget.Name = m.Name.TargetName()
 		t = typedTarget
+	case LocalTarget:
+		typedTarget.Name = m.Name.TargetName()
+		t = typedTarget
 	}
 	m.deployTarget = t
 	return m
@@ -121,6 +160,16 @@ func (m Manifest) WithTriggerMode(mode TriggerMode) Manifest {
 	return m
 }
 
+func (m Manifest) WithLabels(labels []string) Manifest {
+	m.Labels = append([]string{}, labels...)
+	return m
+}
+
+func (m Manifest) WithRestartPolicy(policy RestartPolicy) Manifest {
+	m.RestartPolicy = policy
+	return m
+}
+
 func (m Manifest) TargetSpecs() []TargetSpec {
 	result := []TargetSpec{}
 	for _, t := range m.ImageTargets {
@@ -145,6 +194,8 @@ func (m Manifest) LocalPaths() []string {
 	switch di := m.deployTarget.(type) {
 	case DockerComposeTarget:
 		return di.LocalPaths()
+	case LocalTarget:
+		return di.Dependencies()
 	default:
 		paths := []string{}
 		for _, iTarget := range m.ImageTargets {
@@ -177,23 +228,26 @@ func (m Manifest) Validate() error {
 }
 
 func (m1 Manifest) Equal(m2 Manifest) bool {
-	primitivesEq, dockerEq, k8sEq, dcEq := m1.fieldGroupsEqual(m2)
-	return primitivesEq && dockerEq && k8sEq && dcEq
+	primitivesEq, dockerEq, k8sEq, dcEq, localEq := m1.fieldGroupsEqual(m2)
+	return primitivesEq && dockerEq && k8sEq && dcEq && localEq
 }
 
 // ChangesInvalidateBuild checks whether the changes from old => new manifest
 // invalidate our build of the old one; i.e. if we're replacing `old` with `new`,
 // should we perform a full rebuild?
 func ChangesInvalidateBuild(old, new Manifest) bool {
-	_, dockerEq, k8sEq, dcEq := old.fieldGroupsEqual(new)
+	_, dockerEq, k8sEq, dcEq, localEq := old.fieldGroupsEqual(new)
 
 	// We only need to update for this manifest if any of the field-groups
 	// affecting build+deploy have changed (i.e. a change in primitives doesn't matter)
-	return !dockerEq || !k8sEq || !dcEq
+	return !dockerEq || !k8sEq || !dcEq || !localEq
 
 }
-func (m1 Manifest) fieldGroupsEqual(m2 Manifest) (primitivesEq, dockerEq, k8sEq, dcEq bool) {
-	primitivesMatch := m1.Name == m2.Name && m1.TriggerMode == m2.TriggerMode
+func (m1 Manifest) fieldGroupsEqual(m2 Manifest) (primitivesEq, dockerEq, k8sEq, dcEq, localEq bool) {
+	primitivesMatch := m1.Name == m2.Name && m1.TriggerMode == m2.TriggerMode &&
+		m1.RestartPolicy == m2.RestartPolicy &&
+		DeepEqual(m1.ResourceDependencies, m2.ResourceDependencies) &&
+		DeepEqual(m1.Labels, m2.Labels)
 	dockerEqual := DeepEqual(m1.ImageTargets, m2.ImageTargets)
 
 	dc1 := m1.DockerComposeTarget()
@@ -204,7 +258,11 @@ func (m1 Manifest) fieldGroupsEqual(m2 Manifest) (primitivesEq, dockerEq, k8sEq,
 	k8s2 := m2.K8sTarget()
 	k8sEqual := DeepEqual(k8s1, k8s2)
 
-	return primitivesMatch, dockerEqual, dockerComposeEqual, k8sEqual
+	local1 := m1.LocalTarget()
+	local2 := m2.LocalTarget()
+	localEqual := DeepEqual(local1, local2)
+
+	return primitivesMatch, dockerEqual, dockerComposeEqual, k8sEqual, localEqual
 }
 
 func (m Manifest) ManifestName() ManifestName {
@@ -354,6 +412,7 @@ var imageTargetAllowUnexported = cmp.AllowUnexported(ImageTarget{})
 var dcTargetAllowUnexported = cmp.AllowUnexported(DockerComposeTarget{})
 var labelRequirementAllowUnexported = cmp.AllowUnexported(labels.Requirement{})
 var k8sTargetAllowUnexported = cmp.AllowUnexported(K8sTarget{})
+var localTargetAllowUnexported = cmp.AllowUnexported(LocalTarget{})
 var selectorAllowUnexported = cmp.AllowUnexported(container.RefSelector{})
 
 var dockerRefEqual = cmp.Comparer(func(a, b reference.Named) bool {
@@ -377,6 +436,7 @@ func DeepEqual(x, y interface{}) bool {
 		dcTargetAllowUnexported,
 		labelRequirementAllowUnexported,
 		k8sTargetAllowUnexported,
+		localTargetAllowUnexported,
 		selectorAllowUnexported,
 		dockerRefEqual)
 }
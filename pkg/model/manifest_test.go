@@ -407,6 +407,27 @@ var equalitytests = []struct {
 		false,
 		false,
 	},
+	{
+		"RestartPolicy unequal",
+		Manifest{}.WithRestartPolicy(OnFailureRestartPolicy(3)),
+		Manifest{}.WithRestartPolicy(OnFailureRestartPolicy(5)),
+		false,
+		false,
+	},
+	{
+		"Labels unequal",
+		Manifest{}.WithLabels([]string{"backend"}),
+		Manifest{}.WithLabels([]string{"backend", "payments"}),
+		false,
+		false,
+	},
+	{
+		"ResourceDependencies unequal",
+		Manifest{}.WithResourceDependencies([]ManifestName{"db"}),
+		Manifest{}.WithResourceDependencies([]ManifestName{"db", "redis"}),
+		false,
+		false,
+	},
 	{
 		"Name equal",
 		Manifest{Name: "foo"},
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+type RestartMode int
+
+// What to do when a deployed resource crashes:
+const (
+	// Nothing (i.e., leave it to the orchestrator), or
+	RestartModeNever RestartMode = iota
+	// Redeploy it, with exponential backoff, up to a limit
+	RestartModeOnFailure
+)
+
+const (
+	DefaultMaxRestarts           = 5
+	DefaultRestartInitialBackoff = 5 * time.Second
+	DefaultRestartMaxBackoff     = 5 * time.Minute
+)
+
+type RestartPolicy struct {
+	Mode RestartMode
+
+	// How many times in a row we redeploy a crashing resource before giving up.
+	MaxRestarts int
+
+	// How long we wait before the first restart. Doubles with each
+	// restart after that, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func OnFailureRestartPolicy(maxRestarts int) RestartPolicy {
+	return RestartPolicy{
+		Mode:           RestartModeOnFailure,
+		MaxRestarts:    maxRestarts,
+		InitialBackoff: DefaultRestartInitialBackoff,
+		MaxBackoff:     DefaultRestartMaxBackoff,
+	}
+}
+
+// How long to wait before the given restart (counting from 0).
+func (p RestartPolicy) Backoff(restart int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 0; i < restart && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
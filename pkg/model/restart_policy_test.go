@@ -0,0 +1,17 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestartPolicyBackoff(t *testing.T) {
+	p := OnFailureRestartPolicy(10)
+	assert.Equal(t, 5*time.Second, p.Backoff(0))
+	assert.Equal(t, 10*time.Second, p.Backoff(1))
+	assert.Equal(t, 40*time.Second, p.Backoff(3))
+	assert.Equal(t, 5*time.Minute, p.Backoff(7))
+	assert.Equal(t, 5*time.Minute, p.Backoff(100))
+}
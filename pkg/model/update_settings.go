@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// The default number of manifests that Tilt will update at once.
+const DefaultMaxParallelUpdates = 1
+
+// The default number of times that Tilt retries a push or a deploy
+// that failed with a transient error.
+const DefaultMaxRetries = 3
+
+// How long Tilt waits before retrying a failed push or deploy the first time.
+// The wait doubles on each retry, up to MaxRetryBackoff.
+const DefaultRetryBackoff = 2 * time.Second
+const MaxRetryBackoff = time.Minute
+
+// Global settings for how Tilt updates manifests.
+type UpdateSettings struct {
+	// The maximum number of manifests to build and deploy at the same time.
+	// Zero means "use the default".
+	MaxParallelUpdates int
+
+	// How many times to retry pushing an image or deploying to the cluster
+	// when it fails with a transient error (like a network blip, or a registry
+	// returning a 5xx). Zero means "don't retry".
+	MaxRetries int
+
+	// How long to wait before the first retry.
+	RetryBackoff time.Duration
+
+	// Whether to delete k8s objects that we deployed from this Tiltfile
+	// but that are no longer in any manifest.
+	K8sPrune bool
+
+	// The address of a BuildKit daemon to build images with, instead of
+	// the Docker daemon. Empty means "use Docker".
+	BuildKitAddr string
+}
+
+func DefaultUpdateSettings() UpdateSettings {
+	return UpdateSettings{
+		MaxParallelUpdates: DefaultMaxParallelUpdates,
+		MaxRetries:         DefaultMaxRetries,
+		RetryBackoff:       DefaultRetryBackoff,
+		K8sPrune:           true,
+	}
+}
+
+// How long to wait before the given retry (zero-indexed).
+func (s UpdateSettings) RetryDelay(retry int) time.Duration {
+	delay := s.RetryBackoff
+	for i := 0; i < retry && delay < MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > MaxRetryBackoff {
+		delay = MaxRetryBackoff
+	}
+	return delay
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryDelay(t *testing.T) {
+	s := DefaultUpdateSettings()
+	assert.Equal(t, 2*time.Second, s.RetryDelay(0))
+	assert.Equal(t, 4*time.Second, s.RetryDelay(1))
+	assert.Equal(t, 32*time.Second, s.RetryDelay(4))
+	assert.Equal(t, time.Minute, s.RetryDelay(5))
+	assert.Equal(t, time.Minute, s.RetryDelay(100))
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package procutil
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func SetOptNewProcessGroup(attrs *syscall.SysProcAttr) {
+	attrs.Setpgid = true
+}
+
+func KillProcessGroup(cmd *exec.Cmd) {
+	if cmd != nil && cmd.Process != nil {
+		// Kill the entire process group.
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build windows
+// +build windows
+
+package procutil
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+const createNewProcessGroupFlag = 0x00000200
+
+// https://docs.microsoft.com/en-us/windows/win32/procthread/process-creation-flags
+func SetOptNewProcessGroup(attrs *syscall.SysProcAttr) {
+	attrs.CreationFlags = createNewProcessGroupFlag
+}
+
+func KillProcessGroup(cmd *exec.Cmd) {
+	if cmd != nil && cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
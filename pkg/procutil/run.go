@@ -0,0 +1,38 @@
+package procutil
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// Run starts the command in its own process group and waits for it to exit.
+//
+// Unlike exec.CommandContext, which only kills the immediate process,
+// Run kills the whole process group when the context is canceled, so that
+// the children of a shell command (e.g., a `docker build` run by
+// `sh -c`) don't outlive it.
+func Run(ctx context.Context, cmd *exec.Cmd) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	SetOptNewProcessGroup(cmd.SysProcAttr)
+
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			KillProcessGroup(cmd)
+		case <-done:
+		}
+	}()
+
+	err = cmd.Wait()
+	close(done)
+	return err
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+// +build !windows
+
+package procutil
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunKillsChildrenOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Wait() doesn't return until everyone holding stdout has exited,
+	// so this only finishes quickly if the backgrounded sleep gets killed too.
+	cmd := exec.Command("sh", "-c", "sleep 60 & echo started; wait")
+	out := &bytes.Buffer{}
+	cmd.Stdout = out
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	err := Run(ctx, cmd)
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 10*time.Second, "Run took too long: %s", time.Since(start))
+	assert.Equal(t, "started\n", out.String())
+}
+
+func TestRunSuccess(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "echo hi")
+	out := &bytes.Buffer{}
+	cmd.Stdout = out
+
+	err := Run(context.Background(), cmd)
+	assert.NoError(t, err)
+	assert.Equal(t, "hi\n", out.String())
+}
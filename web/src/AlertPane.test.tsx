@@ -185,6 +185,11 @@ function fillResourceFields(): Resource {
     Endpoints: [],
     PodID: "",
     IsTiltfile: false,
+    Disabled: false,
+    CrashRestartCount: 0,
+    GaveUpRestarting: false,
+    Labels: null,
+    PortForwards: null,
     LastDeployTime: "",
     PathsWatched: [],
     PendingBuildEdits: [],
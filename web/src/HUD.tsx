@@ -30,6 +30,7 @@ type HudState = {
   Message: string
   View: {
     Resources: Array<Resource>
+    Labels: Array<string> | null
     Log: string
     LogTimestamps: boolean
     SailEnabled: boolean
@@ -80,6 +81,7 @@ class HUD extends Component<HudProps, HudState> {
       Message: "",
       View: {
         Resources: [],
+        Labels: [],
         Log: "",
         LogTimestamps: false,
         SailEnabled: false,
@@ -209,8 +211,9 @@ class HUD extends Component<HudProps, HudState> {
           ? props.match.params.name
           : ""
       let numAlerts = 0
+      let selectedResource: Resource | undefined = undefined
       if (name !== "") {
-        let selectedResource = resources.find(r => r.Name === name)
+        selectedResource = resources.find(r => r.Name === name)
         if (selectedResource === undefined) {
           return (
             <TopBar
@@ -249,6 +252,10 @@ class HUD extends Component<HudProps, HudState> {
           handleSendSnapshot={this.sendSnapshot.bind(this)}
           snapshotURL={this.state.SnapshotLink}
           snapshotsIsEnabled={features.isEnabled("snapshots")}
+          resourceName={
+            selectedResource && !selectedResource.IsTiltfile ? name : undefined
+          }
+          resourceDisabled={selectedResource && selectedResource.Disabled}
         />
       )
     }
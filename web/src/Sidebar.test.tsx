@@ -1,7 +1,7 @@
 import React from "react"
 import renderer from "react-test-renderer"
 import { MemoryRouter } from "react-router"
-import Sidebar, { SidebarItem } from "./Sidebar"
+import Sidebar, { SidebarItem, groupItems } from "./Sidebar"
 import {
   oneResource,
   oneResourceView,
@@ -159,4 +159,85 @@ describe("sidebar", () => {
 
     expect(tree).toMatchSnapshot()
   })
+
+  it("groups resources by label", () => {
+    let items = labeledItems()
+    let groups = groupItems(items)
+    expect(groups.map(g => g.label)).toEqual(["backend", "web", "unlabeled"])
+    expect(groups[0].items.map(i => i.name)).toEqual(["api", "db"])
+    expect(groups[1].items.map(i => i.name)).toEqual(["api"])
+    expect(groups[2].items.map(i => i.name)).toEqual(["docs"])
+
+
+    // Without labels, the sidebar isn't grouped.
+    expect(groupItems([new SidebarItem(oneResource())])).toEqual([])
+  })
+
+  it("collapses a group when its header is clicked", () => {
+    const root = mountSidebar(labeledItems())
+    expect(root.find(".Sidebar-group")).toHaveLength(3)
+    expect(root.find(".Sidebar-group .resLink")).toHaveLength(4)
+
+    root
+      .find(".Sidebar-groupHeader")
+      .first()
+      .simulate("click")
+    let backend = root.find(".Sidebar-group").first()
+    expect(backend.hasClass("is-collapsed")).toBe(true)
+    expect(backend.find(".resLink")).toHaveLength(0)
+    expect(root.find(".Sidebar-group .resLink")).toHaveLength(2)
+  })
+
+  it("filters groups by label", () => {
+    const root = mountSidebar(labeledItems())
+    root
+      .find(".Sidebar-filter")
+      .filterWhere(b => b.text() === "web")
+      .simulate("click")
+
+    expect(root.find(".Sidebar-filter.is-active").text()).toEqual("web")
+    expect(root.find(".Sidebar-groupName").map(n => n.text())).toEqual([
+      "web",
+    ])
+
+    // The Tiltfile is always shown.
+    expect(root.find(".resLink-name").map(n => n.text())).toEqual([
+      "(Tiltfile)",
+      "api",
+    ])
+  })
 })
+
+function labeledItems(): SidebarItem[] {
+  let tiltfile = oneResource()
+  tiltfile.Name = "(Tiltfile)"
+  tiltfile.IsTiltfile = true
+
+  let api = oneResource()
+  api.Name = "api"
+  api.Labels = ["backend", "web"]
+
+  let db = oneResource()
+  db.Name = "db"
+  db.Labels = ["backend"]
+
+  let docs = oneResource()
+  docs.Name = "docs"
+
+  return [tiltfile, api, db, docs].map(res => new SidebarItem(res))
+}
+
+function mountSidebar(items: SidebarItem[]) {
+  return mount(
+    <MemoryRouter initialEntries={["/"]}>
+      <Sidebar
+        isClosed={false}
+        items={items}
+        selected=""
+        toggleSidebar={null}
+        resourceView={ResourceView.Log}
+        pathBuilder={pathBuilder}
+      />
+    </MemoryRouter>
+  )
+}
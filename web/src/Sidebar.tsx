@@ -30,6 +30,9 @@ class SidebarItem {
   alertCount: number
   triggerMode: TriggerMode
   hasPendingChanges: boolean
+  disabled: boolean
+  isTiltfile: boolean
+  labels: Array<string>
   lastBuild: Build | null = null
 
   /**
@@ -46,6 +49,9 @@ class SidebarItem {
     this.alertCount = numberOfAlerts(res)
     this.triggerMode = res.TriggerMode
     this.hasPendingChanges = res.HasPendingChanges
+    this.disabled = !!res.Disabled
+    this.isTiltfile = !!res.IsTiltfile
+    this.labels = res.Labels || []
     let buildHistory = res.BuildHistory || []
     if (buildHistory.length > 0) {
       this.lastBuild = buildHistory[0]
@@ -53,6 +59,43 @@ class SidebarItem {
   }
 }
 
+// The group for resources without labels, when other resources have them.
+const unlabeledGroup = "unlabeled"
+
+type SidebarGroup = {
+  label: string
+  items: SidebarItem[]
+}
+
+// Groups the items by label. An item with several labels is in each of
+// their groups. Returns an empty list if no items have labels.
+function groupItems(items: SidebarItem[]): SidebarGroup[] {
+  let byLabel: { [label: string]: SidebarItem[] } = {}
+  let unlabeled: SidebarItem[] = []
+  items.forEach(item => {
+    if (item.isTiltfile) {
+      return
+    }
+    if (item.labels.length === 0) {
+      unlabeled.push(item)
+    }
+    item.labels.forEach(label => {
+      byLabel[label] = (byLabel[label] || []).concat([item])
+    })
+  })
+
+  let labels = Object.keys(byLabel).sort()
+  if (labels.length === 0) {
+    return []
+  }
+
+  let groups = labels.map(label => ({ label: label, items: byLabel[label] }))
+  if (unlabeled.length > 0) {
+    groups.push({ label: unlabeledGroup, items: unlabeled })
+  }
+  return groups
+}
+
 type SidebarProps = {
   isClosed: boolean
   items: SidebarItem[]
@@ -62,7 +105,171 @@ type SidebarProps = {
   pathBuilder: PathBuilder
 }
 
-class Sidebar extends PureComponent<SidebarProps> {
+type SidebarState = {
+  // If non-empty, only show the groups for these labels.
+  labelFilters: Array<string>
+  collapsedGroups: { [label: string]: boolean }
+}
+
+class Sidebar extends PureComponent<SidebarProps, SidebarState> {
+  constructor(props: SidebarProps) {
+    super(props)
+    this.state = {
+      labelFilters: [],
+      collapsedGroups: {},
+    }
+  }
+
+  toggleFilter(label: string) {
+    this.setState(prevState => {
+      let filters = prevState.labelFilters
+      if (filters.includes(label)) {
+        filters = filters.filter(l => l !== label)
+      } else {
+        filters = filters.concat([label])
+      }
+      return { labelFilters: filters }
+    })
+  }
+
+  toggleGroup(label: string) {
+    this.setState(prevState => {
+      let collapsedGroups = Object.assign({}, prevState.collapsedGroups)
+      collapsedGroups[label] = !collapsedGroups[label]
+      return { collapsedGroups: collapsedGroups }
+    })
+  }
+
+  renderItem(item: SidebarItem, key: string) {
+    let pb = this.props.pathBuilder
+    let link = `/r/${item.name}`
+    if (this.props.resourceView === ResourceView.Preview) {
+      link += "/preview"
+    } else if (this.props.resourceView === ResourceView.Alerts) {
+      link += "/alerts"
+    }
+
+    let formatter = timeAgoFormatter
+    let hasBuilt = !isZeroTime(item.lastDeployTime)
+    let building = !isZeroTime(item.currentBuildStartTime)
+    let timeAgo = <TimeAgo date={item.lastDeployTime} formatter={formatter} />
+    let isSelected = this.props.selected === item.name
+    let isManualTriggerMode = item.triggerMode === TriggerMode.TriggerModeManual
+
+    let classes = "resLink"
+    if (building) {
+      classes += " resLink--building"
+    }
+
+    if (item.disabled) {
+      classes += " resLink--disabled"
+    }
+
+    if (isSelected) {
+      classes += " is-selected"
+    }
+    return (
+      <li key={key}>
+        <SidebarTriggerButton
+          isSelected={isSelected}
+          resourceName={item.name}
+          isReady={item.hasPendingChanges && !building}
+          triggerMode={item.triggerMode}
+        />
+        <Link className={classes} to={pb.path(link)}>
+          <div className="sidebarIcon">
+            <SidebarIcon
+              status={item.status}
+              triggerMode={item.triggerMode}
+              hasWarning={item.hasWarnings}
+              isBuilding={building}
+              isDirty={item.hasPendingChanges}
+              lastBuild={item.lastBuild}
+            />
+          </div>
+          <p className="resLink-name" title={item.name}>
+            {item.name}
+          </p>
+          {item.alertCount > 0 ? (
+            <span className="resLink-alertBadge">{item.alertCount}</span>
+          ) : (
+            ""
+          )}
+          <span className={`resLink-timeAgo ${hasBuilt ? "" : "empty"}`}>
+            {hasBuilt ? timeAgo : "—"}
+          </span>
+          <span className="resLink-isDirty">
+            {item.hasPendingChanges && isManualTriggerMode ? "*" : null}
+          </span>
+        </Link>
+      </li>
+    )
+  }
+
+  renderGroups(groups: SidebarGroup[]) {
+    let filters = this.state.labelFilters
+    let filterButtons = groups.map(group => {
+      let classes = "Sidebar-filter"
+      if (filters.includes(group.label)) {
+        classes += " is-active"
+      }
+      return (
+        <button
+          key={group.label}
+          className={classes}
+          onClick={() => this.toggleFilter(group.label)}
+        >
+          {group.label}
+        </button>
+      )
+    })
+
+    let visibleGroups = groups.filter(
+      group => filters.length === 0 || filters.includes(group.label)
+    )
+    let groupEls = visibleGroups.map(group => {
+      let isCollapsed = !!this.state.collapsedGroups[group.label]
+      let alertCount = group.items
+        .map(i => i.alertCount)
+        .reduce((sum, current) => sum + current, 0)
+      let classes = "Sidebar-group"
+      if (isCollapsed) {
+        classes += " is-collapsed"
+      }
+      return (
+        <div key={group.label} className={classes}>
+          <button
+            className="Sidebar-groupHeader"
+            onClick={() => this.toggleGroup(group.label)}
+          >
+            <ChevronSvg />
+            <span className="Sidebar-groupName">{group.label}</span>
+            <span className="Sidebar-groupCount">{group.items.length}</span>
+            {alertCount > 0 ? (
+              <span className="resLink-alertBadge">{alertCount}</span>
+            ) : (
+              ""
+            )}
+          </button>
+          {isCollapsed ? null : (
+            <ul className="Sidebar-list">
+              {group.items.map(item =>
+                this.renderItem(item, `${group.label}/${item.name}`)
+              )}
+            </ul>
+          )}
+        </div>
+      )
+    })
+
+    return [
+      <div key="filters" className="Sidebar-filters">
+        {filterButtons}
+      </div>,
+      groupEls,
+    ]
+  }
+
   render() {
     let pb = this.props.pathBuilder
     let classes = ["Sidebar"]
@@ -97,67 +304,10 @@ class Sidebar extends PureComponent<SidebarProps> {
       </li>
     )
 
-    let listItems = this.props.items.map(item => {
-      let link = `/r/${item.name}`
-      if (this.props.resourceView === ResourceView.Preview) {
-        link += "/preview"
-      } else if (this.props.resourceView === ResourceView.Alerts) {
-        link += "/alerts"
-      }
-
-      let formatter = timeAgoFormatter
-      let hasBuilt = !isZeroTime(item.lastDeployTime)
-      let building = !isZeroTime(item.currentBuildStartTime)
-      let timeAgo = <TimeAgo date={item.lastDeployTime} formatter={formatter} />
-      let isSelected = this.props.selected === item.name
-      let isManualTriggerMode =
-        item.triggerMode === TriggerMode.TriggerModeManual
-
-      let classes = "resLink"
-      if (building) {
-        classes += " resLink--building"
-      }
-
-      if (isSelected) {
-        classes += " is-selected"
-      }
-      return (
-        <li key={item.name}>
-          <SidebarTriggerButton
-            isSelected={isSelected}
-            resourceName={item.name}
-            isReady={item.hasPendingChanges && !building}
-            triggerMode={item.triggerMode}
-          />
-          <Link className={classes} to={pb.path(link)}>
-            <div className="sidebarIcon">
-              <SidebarIcon
-                status={item.status}
-                triggerMode={item.triggerMode}
-                hasWarning={item.hasWarnings}
-                isBuilding={building}
-                isDirty={item.hasPendingChanges}
-                lastBuild={item.lastBuild}
-              />
-            </div>
-            <p className="resLink-name" title={item.name}>
-              {item.name}
-            </p>
-            {item.alertCount > 0 ? (
-              <span className="resLink-alertBadge">{item.alertCount}</span>
-            ) : (
-              ""
-            )}
-            <span className={`resLink-timeAgo ${hasBuilt ? "" : "empty"}`}>
-              {hasBuilt ? timeAgo : "—"}
-            </span>
-            <span className="resLink-isDirty">
-              {item.hasPendingChanges && isManualTriggerMode ? "*" : null}
-            </span>
-          </Link>
-        </li>
-      )
-    })
+    let groups = groupItems(this.props.items)
+    let listItems = this.props.items
+      .filter(item => groups.length === 0 || item.isTiltfile)
+      .map(item => this.renderItem(item, item.name))
 
     return (
       <section className={classes.join(" ")}>
@@ -166,6 +316,7 @@ class Sidebar extends PureComponent<SidebarProps> {
             {allItem}
             {listItems}
           </ul>
+          {groups.length > 0 ? this.renderGroups(groups) : null}
         </nav>
         <div className="Sidebar-spacer">&nbsp;</div>
         <button className="Sidebar-toggle" onClick={this.props.toggleSidebar}>
@@ -178,4 +329,4 @@ class Sidebar extends PureComponent<SidebarProps> {
 
 export default Sidebar
 
-export { SidebarItem }
+export { SidebarItem, groupItems }
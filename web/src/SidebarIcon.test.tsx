@@ -161,6 +161,17 @@ const cases: Array<
     false,
     null,
   ],
+  [
+    "auto mode, status pending readiness → small glowing ring",
+    RuntimeStatus.PendingReadiness,
+    false,
+    false,
+    TriggerMode.TriggerModeAuto,
+    false,
+    IconType.DotAutoPending,
+    false,
+    null,
+  ],
   [
     "manual mode, status pending and no warnings → glowing ring",
     RuntimeStatus.Pending,
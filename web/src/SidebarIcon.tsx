@@ -53,7 +53,7 @@ export default class SidebarIcon extends PureComponent<SidebarIconProps> {
       return this.dotAutoBuilding()
     }
 
-    if (props.status === RuntimeStatus.Pending) {
+    if (this.isPending()) {
       return this.dotAutoPending()
     }
 
@@ -70,13 +70,21 @@ export default class SidebarIcon extends PureComponent<SidebarIconProps> {
       return this.dotManual(fill)
     }
 
-    if (props.status === RuntimeStatus.Pending) {
+    if (this.isPending()) {
       return this.dotManualPending()
     }
 
     return this.dotManual(fill)
   }
 
+  isPending() {
+    let status = this.props.status
+    return (
+      status === RuntimeStatus.Pending ||
+      status === RuntimeStatus.PendingReadiness
+    )
+  }
+
   dotAuto(fill: Color) {
     return <AutoSvg className={`${IconType.DotAuto} auto`} fill={fill} />
   }
@@ -17,6 +17,21 @@ type TopBarProps = {
   handleSendSnapshot: (snapshot: Snapshot) => void
   snapshotURL: string
   snapshotsIsEnabled: boolean
+  resourceName?: string
+  resourceDisabled?: boolean
+}
+
+const setResourceEnabled = (name: string, enabled: boolean): void => {
+  let url = `//${window.location.host}/api/resource/${name}/enable`
+
+  fetch(url, {
+    method: "post",
+    body: JSON.stringify({ enabled: enabled }),
+  }).then(response => {
+    if (!response.ok) {
+      console.log(response)
+    }
+  })
 }
 
 class TopBar extends PureComponent<TopBarProps> {
@@ -31,6 +46,11 @@ class TopBar extends PureComponent<TopBarProps> {
           numberOfAlerts={this.props.numberOfAlerts}
         />
         <section className="TopBar-tools">
+          {this.props.resourceName &&
+            renderEnableButton(
+              this.props.resourceName,
+              !!this.props.resourceDisabled
+            )}
           {this.props.snapshotsIsEnabled &&
             renderSnapshotLinkButton(
               this.props.state,
@@ -47,6 +67,16 @@ class TopBar extends PureComponent<TopBarProps> {
   }
 }
 
+function renderEnableButton(name: string, disabled: boolean) {
+  return (
+    <section className="TopBar-enableWrap">
+      <button onClick={() => setResourceEnabled(name, disabled)}>
+        {disabled ? "Enable" : "Disable"}
+      </button>
+    </section>
+  )
+}
+
 function renderSnapshotLinkButton(
   snapshot: Snapshot,
   handleSendSnapshot: (snapshot: Snapshot) => void,
@@ -2,10 +2,12 @@ import {
   Alert,
   BuildFailedErrorType,
   CrashRebuildErrorType,
+  GaveUpRestartingErrorType,
   getResourceAlerts,
   numberOfAlerts,
   PodRestartErrorType,
   PodStatusErrorType,
+  PortForwardFailedErrorType,
   WarningErrorType,
 } from "./alerts"
 import { Resource, K8sResourceInfo, TriggerMode } from "./types"
@@ -102,6 +104,56 @@ describe("getResourceAlerts", () => {
     expect(actual).toEqual(expectedAlerts)
   })
 
+  it("K8s Resource: should show an alert for each failed port-forward", () => {
+    let r: Resource = k8sResource()
+    r.PortForwards = [
+      { LocalPort: 8080, ContainerPort: 8081, Status: "active", Error: "" },
+      {
+        LocalPort: 9000,
+        ContainerPort: 9000,
+        Status: "reconnecting",
+        Error: "lost connection to pod",
+      },
+      {
+        LocalPort: 9229,
+        ContainerPort: 9229,
+        Status: "failed",
+        Error: "pod testPod not found",
+      },
+    ]
+
+    let actual = getResourceAlerts(r)
+    let expectedAlerts: Array<Alert> = [
+      {
+        alertType: PortForwardFailedErrorType,
+        msg: "pod testPod not found",
+        timestamp: "",
+        header: "Port-forward localhost:9229 → 9229 failed",
+        resourceName: "snack",
+      },
+    ]
+    expect(actual).toEqual(expectedAlerts)
+  })
+
+  it("DC Resource: should show an alert when we give up restarting", () => {
+    let r: Resource = dcResource()
+    r.GaveUpRestarting = true
+    r.CrashRestartCount = 5
+    r.CrashLog = "Hello I am a crash log"
+
+    let actual = getResourceAlerts(r)
+    let expectedAlerts: Array<Alert> = [
+      {
+        alertType: GaveUpRestartingErrorType,
+        msg: "Hello I am a crash log",
+        timestamp: "2019-08-07T11:43:37.568626-04:00",
+        header: "Gave up restarting after 5 crashes",
+        resourceName: "vigoda",
+      },
+    ]
+    expect(actual).toEqual(expectedAlerts)
+  })
+
   it("K8s Resource: should show a warning alert using the first build history ", () => {
     let r: Resource = k8sResource()
     r.BuildHistory = [
@@ -358,6 +410,11 @@ function k8sResource(): Resource {
     Endpoints: [],
     PodID: "podID",
     IsTiltfile: false,
+    Disabled: false,
+    CrashRestartCount: 0,
+    GaveUpRestarting: false,
+    Labels: null,
+    PortForwards: null,
     LastDeployTime: "",
     PathsWatched: [],
     PendingBuildEdits: [],
@@ -425,6 +482,11 @@ function dcResource(): Resource {
     },
     RuntimeStatus: "ok",
     IsTiltfile: false,
+    Disabled: false,
+    CrashRestartCount: 0,
+    GaveUpRestarting: false,
+    Labels: null,
+    PortForwards: null,
     CombinedLog: "",
     CrashLog: "",
     Alerts: [],
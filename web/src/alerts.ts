@@ -1,4 +1,9 @@
-import { DCResourceInfo, K8sResourceInfo, Resource } from "./types"
+import {
+  DCResourceInfo,
+  K8sResourceInfo,
+  PortForward,
+  Resource,
+} from "./types"
 import { podStatusIsError, podStatusIsCrash } from "./constants"
 import { is } from "immutable"
 
@@ -13,8 +18,10 @@ export type Alert = {
 export const PodRestartErrorType = "PodRestartError"
 export const PodStatusErrorType = "PodStatusError"
 export const CrashRebuildErrorType = "ResourceCrashRebuild"
+export const GaveUpRestartingErrorType = "GaveUpRestarting"
 export const BuildFailedErrorType = "BuildError"
 export const WarningErrorType = "Warning"
+export const PortForwardFailedErrorType = "PortForwardFailed"
 
 function hasAlert(resource: Resource) {
   return numberOfAlerts(resource) > 0
@@ -43,7 +50,15 @@ function podRestarted(r: Resource) {
   return rInfo.PodRestarts > 0
 }
 
+function failedPortForwards(r: Resource): Array<PortForward> {
+  return (r.PortForwards || []).filter(pf => pf.Status === "failed")
+}
+
 // Errors for both DC and K8s Resources
+function gaveUpRestarting(r: Resource): boolean {
+  return !!r.GaveUpRestarting
+}
+
 function buildFailed(resource: Resource) {
   return (
     resource.BuildHistory.length > 0 && resource.BuildHistory[0].Error !== null
@@ -71,8 +86,12 @@ function getResourceAlerts(r: Resource): Array<Alert> {
     if (crashRebuild(r)) {
       result.push(crashRebuildAlert(r))
     }
+    result = result.concat(portForwardFailedAlerts(r))
   }
 
+  if (gaveUpRestarting(r)) {
+    result.push(gaveUpRestartingAlert(r))
+  }
   if (buildFailed(r)) {
     result.push(buildFailedAlert(r))
   }
@@ -130,6 +149,26 @@ function crashRebuildAlert(r: Resource): Alert {
     resourceName: r.Name,
   }
 }
+function gaveUpRestartingAlert(r: Resource): Alert {
+  let timestamp = r.BuildHistory.length > 0 ? r.BuildHistory[0].FinishTime : ""
+  return {
+    alertType: GaveUpRestartingErrorType,
+    header: `Gave up restarting after ${r.CrashRestartCount} crashes`,
+    msg: r.CrashLog || "",
+    timestamp: timestamp,
+    resourceName: r.Name,
+  }
+}
+function portForwardFailedAlerts(r: Resource): Array<Alert> {
+  let rInfo = <K8sResourceInfo>r.ResourceInfo
+  return failedPortForwards(r).map(pf => ({
+    alertType: PortForwardFailedErrorType,
+    header: `Port-forward localhost:${pf.LocalPort} → ${pf.ContainerPort} failed`,
+    msg: pf.Error || "",
+    timestamp: rInfo.PodCreationTime,
+    resourceName: r.Name,
+  }))
+}
 function buildFailedAlert(resource: Resource): Alert {
   // both: DCResource and K8s Resource
   let msg = resource.BuildHistory[0].Log || ""
@@ -169,7 +208,9 @@ export {
   warningsAlerts,
   buildFailedAlert,
   crashRebuildAlert,
+  gaveUpRestartingAlert,
   podRestartAlert,
+  portForwardFailedAlerts,
   hasAlert,
   isK8sResourceInfo,
 }
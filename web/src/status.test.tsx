@@ -42,6 +42,14 @@ describe("combinedStatus", () => {
     expect(combinedStatus(res)).toBe("error")
   })
 
+  it("pending readiness when runtime pending readiness", () => {
+    const ts = Date.now().toLocaleString()
+    let res = emptyResource()
+    res.BuildHistory = [{ StartTime: ts }]
+    res.RuntimeStatus = "pending_readiness"
+    expect(combinedStatus(res)).toBe("pending_readiness")
+  })
+
   it("error when last build error", () => {
     const ts = Date.now().toLocaleString()
     let res = emptyResource()
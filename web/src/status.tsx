@@ -28,6 +28,8 @@ function combinedStatus(res: Resource): RuntimeStatus {
       return RuntimeStatus.Error
     case RuntimeStatus.Pending:
       return RuntimeStatus.Pending
+    case RuntimeStatus.PendingReadiness:
+      return RuntimeStatus.PendingReadiness
     case RuntimeStatus.Ok:
       return RuntimeStatus.Ok
     default:
@@ -94,6 +94,11 @@ function oneResource(): Resource {
     Endpoints: [],
     PodID: "",
     IsTiltfile: false,
+    Disabled: false,
+    CrashRestartCount: 0,
+    GaveUpRestarting: false,
+    Labels: null,
+    PortForwards: null,
     PathsWatched: [],
     Alerts: [],
   }
@@ -251,6 +256,11 @@ function twoResourceView(): view {
     CombinedLog: "",
     CrashLog: "",
     IsTiltfile: false,
+    Disabled: false,
+    CrashRestartCount: 0,
+    GaveUpRestarting: false,
+    Labels: null,
+    PortForwards: null,
     PodID: "",
     PathsWatched: [],
     PendingBuildReason: 0,
@@ -31,6 +31,7 @@ export type TiltBuild = {
 export enum RuntimeStatus {
   Ok = "ok",
   Pending = "pending",
+  PendingReadiness = "pending_readiness", // running, but failing readiness probes
   Error = "error",
   Unknown = "unknown",
 }
@@ -55,6 +56,11 @@ export type Resource = {
   Endpoints: Array<string>
   PodID: string
   IsTiltfile: boolean
+  Disabled: boolean
+  CrashRestartCount: number
+  GaveUpRestarting: boolean
+  Labels: Array<string> | null
+  PortForwards: Array<PortForward> | null
   LastDeployTime: string
   PathsWatched: Array<string>
   PendingBuildEdits: Array<string>
@@ -66,6 +72,12 @@ export type Resource = {
   HasPendingChanges: boolean
   Alerts: Array<Alert>
 }
+export type PortForward = {
+  LocalPort: number
+  ContainerPort: number
+  Status: string // "active", "reconnecting", or "failed"
+  Error: string
+}
 export type K8sResourceInfo = {
   PodName: string
   PodCreationTime: string
@@ -90,6 +102,7 @@ export type Snapshot = {
   Message: string
   View: {
     Resources: Array<Resource>
+    Labels: Array<string> | null
     Log: string
     LogTimestamps: boolean
     SailEnabled: boolean